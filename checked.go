@@ -0,0 +1,161 @@
+package float
+
+// Checked performs operations with its own rounding attributes and reports
+// exceptions as errors instead of through the global environment.  An
+// operation returns an *OpError if it raises any of the flags in Mask; the
+// result is the default result in either case.  Checked operations neither
+// read nor modify the global rounding mode, precision, exception flags or
+// handlers.
+//
+//	c := float.Checked{Mask: float.ExceptionInvalid | float.ExceptionDivbyzero}
+//	q, err := c.Div(a, b)
+//	if errors.Is(err, float.ErrDivByZero) {
+//	    ...
+//	}
+type Checked struct {
+	Mask              Flags    // exceptions reported as errors
+	RoundingMode      Rounding // rounding mode of the operations
+	RoundingPrecision int      // 32, 64 or 80; 0 means 80
+	DestinationFormat bool     // narrow the exponent range as well, see DestinationFormat
+}
+
+// OpError is the error returned by Checked operations.  Flags holds all
+// exceptions raised by the operation, including those outside the mask.
+type OpError struct {
+	Op    Op
+	Flags Flags
+}
+
+func (e *OpError) Error() string {
+	return "float: " + e.Op.String() + ": " + e.Flags.String()
+}
+
+// Unwrap returns the *FlagsError of the raised flags, so that errors.Is can
+// match the sentinel errors such as ErrInvalid.
+func (e *OpError) Unwrap() error {
+	return e.Flags.Err()
+}
+
+// Returns the environment of a checked operation.
+func (c Checked) status() status {
+	s := pureStatus(c.RoundingMode, c.RoundingPrecision)
+	s.destinationFormat = c.DestinationFormat
+	return s
+}
+
+// Returns `z' and, if the operation `op' raised a flag in the mask, an
+// *OpError.
+func check[T any](c Checked, s *status, op Op, z T) (T, error) {
+	if s.exception&c.Mask != 0 {
+		return z, &OpError{op, s.exception}
+	}
+	return z, nil
+}
+
+// Add returns a + b.
+func (c Checked) Add(a, b X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpAdd, s.add(a, b))
+}
+
+// Sub returns a - b.
+func (c Checked) Sub(a, b X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpSub, s.sub(a, b))
+}
+
+// Mul returns a * b.
+func (c Checked) Mul(a, b X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpMul, s.mul(a, b))
+}
+
+// Div returns a / b.
+func (c Checked) Div(a, b X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpDiv, s.div(a, b))
+}
+
+// Rem returns the IEEE remainder of a with respect to b.
+func (c Checked) Rem(a, b X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpRem, s.rem(a, b))
+}
+
+// Sqrt returns the square root of a.
+func (c Checked) Sqrt(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpSqrt, s.sqrt(a))
+}
+
+// RoundToInt rounds a to an integer in the rounding mode of `c'.
+func (c Checked) RoundToInt(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpRoundToInt, s.roundFloatX80ToInt(a, c.RoundingMode, true))
+}
+
+// Ln returns the natural logarithm of a.
+func (c Checked) Ln(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpLn, s.ln(a))
+}
+
+// Atan returns the arctangent of a.
+func (c Checked) Atan(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpAtan, s.atan(a))
+}
+
+// Sin returns the sine of a.
+func (c Checked) Sin(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpSin, s.sin(a))
+}
+
+// Cos returns the cosine of a.
+func (c Checked) Cos(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpCos, s.cos(a))
+}
+
+// Tan returns the tangent of a.
+func (c Checked) Tan(a X80) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpTan, s.tan(a))
+}
+
+// Compare compares a and b, signaling the invalid exception for NaNs.
+func (c Checked) Compare(a, b X80) (Ordering, error) {
+	s := c.status()
+	return check(c, &s, OpCompareOrdered, s.compareFloatX80(a, b, false))
+}
+
+// ToInt32 converts a to a 32-bit integer in the rounding mode of `c'.
+func (c Checked) ToInt32(a X80) (int32, error) {
+	s := c.status()
+	return check(c, &s, OpToInt32, s.toInt32(a))
+}
+
+// ToInt64 converts a to a 64-bit integer in the rounding mode of `c'.
+func (c Checked) ToInt64(a X80) (int64, error) {
+	s := c.status()
+	return check(c, &s, OpToInt64, s.toInt64(a))
+}
+
+// ToFloat32 converts a to single precision.
+func (c Checked) ToFloat32(a X80) (float32, error) {
+	s := c.status()
+	return check(c, &s, OpToFloat32, s.toFloat32(a))
+}
+
+// ToFloat64 converts a to double precision.
+func (c Checked) ToFloat64(a X80) (float64, error) {
+	s := c.status()
+	return check(c, &s, OpToFloat64, s.toFloat64(a))
+}
+
+// FromFloat64 converts the double-precision value a to extended precision.
+func (c Checked) FromFloat64(a float64) (X80, error) {
+	s := c.status()
+	return check(c, &s, OpFloat64ToFloatX80, s.float64ToFloatX80(a))
+}
@@ -0,0 +1,68 @@
+package float
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestChecked(t *testing.T) {
+	strict := Checked{Mask: ExceptionInvalid | ExceptionDivbyzero | ExceptionOverflow}
+	maxNormal := X80{0x7FFE, 0xFFFFFFFFFFFFFFFF}
+	tests := []struct {
+		name    string
+		op      func() (any, error)
+		want    any
+		wantErr error
+	}{
+		{"Add", func() (any, error) { return strict.Add(X80One, X80One) }, Int32ToFloatX80(2), nil},
+		{"Div by zero", func() (any, error) { return strict.Div(X80One, X80Zero) }, X80InfPos, ErrDivByZero},
+		{"Mul overflow", func() (any, error) { return strict.Mul(maxNormal, maxNormal) }, X80InfPos, ErrOverflow},
+		{"Sqrt negative", func() (any, error) { return strict.Sqrt(X80MinusOne) }, X80NaN, ErrInvalid},
+		{"Ln zero", func() (any, error) { return strict.Ln(X80Zero) }, X80InfNeg, ErrDivByZero},
+		{"inexact not in mask", func() (any, error) { return strict.Div(X80One, Int32ToFloatX80(3)) }, X80One.Div(Int32ToFloatX80(3)), nil},
+		{"inexact in mask", func() (any, error) {
+			return Checked{Mask: ExceptionInexact}.Div(X80One, Int32ToFloatX80(3))
+		}, X80One.Div(Int32ToFloatX80(3)), ErrInexact},
+		{"ToInt32 NaN", func() (any, error) { return strict.ToInt32(X80NaN) }, int32(math.MaxInt32), ErrInvalid},
+		{"ToInt64 rounding", func() (any, error) {
+			return Checked{RoundingMode: RoundUp}.ToInt64(Float64ToFloatX80(1.25))
+		}, int64(2), nil},
+		{"ToFloat64 overflow", func() (any, error) { return strict.ToFloat64(maxNormal) }, math.Inf(1), ErrOverflow},
+		{"Compare NaN", func() (any, error) { return strict.Compare(X80NaN, X80One) }, Unordered, ErrInvalid},
+		{"precision 32", func() (any, error) {
+			return Checked{RoundingPrecision: 32}.Div(X80One, Int32ToFloatX80(3))
+		}, Float32ToFloatX80(float32(1) / 3), nil},
+		{"destination format", func() (any, error) {
+			pow1000 := X80{0x3FFF + 1000, 0x8000000000000000}
+			return Checked{Mask: ExceptionOverflow, RoundingPrecision: 64, DestinationFormat: true}.Mul(pow1000, pow1000)
+		}, X80InfPos, ErrOverflow},
+	}
+	ClearExceptions()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if Exception != 0 {
+		t.Errorf("checked operations accrued %v", Exception)
+	}
+	ClearExceptions()
+}
+
+func TestOpError(t *testing.T) {
+	_, err := Checked{Mask: ExceptionDivbyzero}.Div(X80One, X80Zero)
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != OpDiv || opErr.Flags != ExceptionDivbyzero {
+		t.Fatalf("err = %#v", err)
+	}
+	if got, want := err.Error(), "float: Div: DivByZero"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
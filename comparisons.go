@@ -1,13 +1,21 @@
 package float
 
+import "strconv"
+
 // Eq returns true if the extended double-precision floating-point value `a' is
 // equal to the corresponding value `b', and false otherwise.  The comparison is
 // performed according to the IEC/IEEE Standard for Binary Floating-Point
 // Arithmetic.
 func (a X80) Eq(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpEq, s.eq(a, b), a, b)
+}
+
+func (s *status) eq(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
 		if a.IsSignalingNaN() || b.IsSignalingNaN() {
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 		}
 		return false
 	}
@@ -24,8 +32,14 @@ func (a X80) Gt(b X80) bool {
 // Le returns true if the extended double-precision floating-point value `a' is less than or
 // equal to the corresponding value `b', and false otherwise.
 func (a X80) Le(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpLe, s.le(a, b), a, b)
+}
+
+func (s *status) le(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return false
 	}
 	aSign, bSign := a.sign(), b.sign()
@@ -49,8 +63,14 @@ func (a X80) Ge(b X80) bool {
 // is performed according to the IEC/IEEE Standard for Binary Floating-Point
 // Arithmetic.
 func (a X80) Lt(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpLt, s.lt(a, b), a, b)
+}
+
+func (s *status) lt(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return false
 	}
 	aSign, bSign := a.sign(), b.sign()
@@ -68,8 +88,14 @@ func (a X80) Lt(b X80) bool {
 // raised if either operand is a NaN.  Otherwise, the comparison is performed
 // according to the IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) EqSignaling(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpEqSignaling, s.eqSignaling(a, b), a, b)
+}
+
+func (s *status) eqSignaling(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return false
 	}
 	return a.low == b.low && (a.high == b.high || (a.low == 0 && (a.high|b.high)<<1 == 0))
@@ -88,9 +114,15 @@ func (a X80) GtQuiet(b X80) bool {
 // do not cause an exception.  Otherwise, the comparison is performed according
 // to the IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) LeQuiet(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpLeQuiet, s.leQuiet(a, b), a, b)
+}
+
+func (s *status) leQuiet(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
 		if a.IsSignalingNaN() || b.IsSignalingNaN() {
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 		}
 		return false
 	}
@@ -117,9 +149,15 @@ func (a X80) GeQuiet(b X80) bool {
 // an exception.  Otherwise, the comparison is performed according to the
 // IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) LtQuiet(b X80) bool {
+	s := newStatus()
+	return commit(&s, OpLtQuiet, s.ltQuiet(a, b), a, b)
+}
+
+func (s *status) ltQuiet(a, b X80) bool {
+	a, b = s.denormalOperands(a, b)
 	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
 		if a.IsSignalingNaN() || b.IsSignalingNaN() {
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 		}
 		return false
 	}
@@ -132,3 +170,406 @@ func (a X80) LtQuiet(b X80) bool {
 	}
 	return lt128(uint64(a.high), a.low, uint64(b.high), b.low)
 }
+
+// Returns true if the extended double-precision floating-point value `a' is
+// less than the corresponding value `b', treating -0 as less than +0.  Neither
+// value may be a NaN.
+func ltSignedZero(a, b X80) bool {
+	aSign, bSign := a.sign(), b.sign()
+	if aSign != bSign {
+		return aSign
+	}
+	if aSign {
+		return lt128(uint64(b.high), b.low, uint64(a.high), a.low)
+	}
+	return lt128(uint64(a.high), a.low, uint64(b.high), b.low)
+}
+
+// Returns true if the magnitude of `a' is less than the magnitude of `b'.
+// Neither value may be a NaN.
+func ltMag(a, b X80) bool {
+	return lt128(uint64(a.high&0x7FFF), a.low, uint64(b.high&0x7FFF), b.low)
+}
+
+// Returns the appropriate result of a minimumNumber or maximumNumber style
+// operation where at least one of `a' and `b' is a NaN.  If only one operand
+// is a NaN the other operand is returned; a signaling NaN still raises the
+// invalid exception.
+func (s *status) preferNumber(a, b X80) X80 {
+	aIsNaN, bIsNaN := a.IsNaN(), b.IsNaN()
+	if aIsNaN && bIsNaN {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	if a.IsSignalingNaN() || b.IsSignalingNaN() {
+		s.raise(ExceptionInvalid)
+	}
+	if aIsNaN {
+		return b
+	}
+	return a
+}
+
+// Min returns the smaller of the extended double-precision floating-point
+// values `a' and `b' as defined by the minimumNumber operation of IEEE
+// 754-2019.  If exactly one operand is a NaN the other operand is returned,
+// raising the invalid exception if the NaN is signaling.  -0 is considered
+// less than +0.
+func (a X80) Min(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMin, s.min(a, b), a, b)
+}
+
+func (s *status) min(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.preferNumber(a, b)
+	}
+	if ltSignedZero(b, a) {
+		return b
+	}
+	return a
+}
+
+// Max returns the larger of the extended double-precision floating-point
+// values `a' and `b' as defined by the maximumNumber operation of IEEE
+// 754-2019.  If exactly one operand is a NaN the other operand is returned,
+// raising the invalid exception if the NaN is signaling.  +0 is considered
+// greater than -0.
+func (a X80) Max(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMax, s.max(a, b), a, b)
+}
+
+func (s *status) max(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.preferNumber(a, b)
+	}
+	if ltSignedZero(a, b) {
+		return b
+	}
+	return a
+}
+
+// MinNum returns the smaller of the extended double-precision floating-point
+// values `a' and `b' as defined by the minNum operation of IEEE 754-2008.  A
+// quiet NaN operand is ignored in favour of a number, but a signaling NaN
+// raises the invalid exception and yields a quiet NaN.  -0 is considered less
+// than +0.
+func (a X80) MinNum(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMinNum, s.minNum(a, b), a, b)
+}
+
+func (s *status) minNum(a, b X80) X80 {
+	if a.IsSignalingNaN() || b.IsSignalingNaN() {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	return s.min(a, b)
+}
+
+// MaxNum returns the larger of the extended double-precision floating-point
+// values `a' and `b' as defined by the maxNum operation of IEEE 754-2008.  A
+// quiet NaN operand is ignored in favour of a number, but a signaling NaN
+// raises the invalid exception and yields a quiet NaN.  +0 is considered
+// greater than -0.
+func (a X80) MaxNum(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMaxNum, s.maxNum(a, b), a, b)
+}
+
+func (s *status) maxNum(a, b X80) X80 {
+	if a.IsSignalingNaN() || b.IsSignalingNaN() {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	return s.max(a, b)
+}
+
+// MinMag returns the operand of smaller magnitude as defined by the
+// minimumMagnitudeNumber operation of IEEE 754-2019.  If both operands have
+// the same magnitude the result is that of Min.  NaNs are handled as in Min.
+func (a X80) MinMag(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMinMag, s.minMag(a, b), a, b)
+}
+
+func (s *status) minMag(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.preferNumber(a, b)
+	}
+	if ltMag(a, b) {
+		return a
+	}
+	if ltMag(b, a) {
+		return b
+	}
+	return s.min(a, b)
+}
+
+// MaxMag returns the operand of larger magnitude as defined by the
+// maximumMagnitudeNumber operation of IEEE 754-2019.  If both operands have
+// the same magnitude the result is that of Max.  NaNs are handled as in Max.
+func (a X80) MaxMag(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMaxMag, s.maxMag(a, b), a, b)
+}
+
+func (s *status) maxMag(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.preferNumber(a, b)
+	}
+	if ltMag(b, a) {
+		return a
+	}
+	if ltMag(a, b) {
+		return b
+	}
+	return s.max(a, b)
+}
+
+// Minimum returns the smaller of the extended double-precision floating-point
+// values `a' and `b' as defined by the minimum operation of IEEE 754-2019.  If
+// either operand is a NaN the result is a quiet NaN, and the invalid exception
+// is raised if either operand is a signaling NaN.  -0 is considered less than
+// +0.
+func (a X80) Minimum(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMinimum, s.minimum(a, b), a, b)
+}
+
+func (s *status) minimum(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	if ltSignedZero(b, a) {
+		return b
+	}
+	return a
+}
+
+// Maximum returns the larger of the extended double-precision floating-point
+// values `a' and `b' as defined by the maximum operation of IEEE 754-2019.  If
+// either operand is a NaN the result is a quiet NaN, and the invalid exception
+// is raised if either operand is a signaling NaN.  +0 is considered greater
+// than -0.
+func (a X80) Maximum(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMaximum, s.maximum(a, b), a, b)
+}
+
+func (s *status) maximum(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	if ltSignedZero(a, b) {
+		return b
+	}
+	return a
+}
+
+// Maps the extended double-precision floating-point value `a' to a 128-bit
+// unsigned key whose natural order is the totalOrder predicate of IEEE 754.
+// Negative values have all bits inverted so that they sort in reverse and
+// below every positive value.
+func totalOrderKey(a X80) (uint64, uint64) {
+	if a.sign() {
+		return uint64(^a.high), ^a.low
+	}
+	return uint64(a.high | 0x8000), a.low
+}
+
+// TotalOrder returns true if the extended double-precision floating-point
+// value `a' is ordered before or equal to `b' in the totalOrder predicate of
+// IEEE 754-2019:
+//
+//	-qNaN < -sNaN < -Inf < negative numbers < -0 < +0 < positive numbers < +Inf < +sNaN < +qNaN
+//
+// NaNs of the same sign and kind are ordered by payload.  Values with the same
+// numeric value but different encodings are ordered by their encoding.  No
+// exception is raised, not even for signaling NaNs.
+func (a X80) TotalOrder(b X80) bool {
+	a0, a1 := totalOrderKey(a)
+	b0, b1 := totalOrderKey(b)
+	return le128(a0, a1, b0, b1)
+}
+
+// TotalOrderMag returns true if the absolute value of `a' is ordered before or
+// equal to the absolute value of `b' in the totalOrder predicate of IEEE
+// 754-2019.  No exception is raised.
+func (a X80) TotalOrderMag(b X80) bool {
+	a.high &= 0x7FFF
+	b.high &= 0x7FFF
+	return a.TotalOrder(b)
+}
+
+// Compare returns -1 if `a' is ordered before `b', 0 if both values have the
+// same encoding, and +1 otherwise, following the totalOrder predicate of IEEE
+// 754-2019.  It gives a deterministic order for every value including signed
+// zeros and NaNs and is suitable for slices.SortFunc.  No exception is raised.
+func (a X80) Compare(b X80) int {
+	a0, a1 := totalOrderKey(a)
+	b0, b1 := totalOrderKey(b)
+	switch {
+	case lt128(a0, a1, b0, b1):
+		return -1
+	case eq128(a0, a1, b0, b1):
+		return 0
+	}
+	return 1
+}
+
+// Ordering is the result of comparing two extended double-precision
+// floating-point values.
+type Ordering int
+
+// Possible results of CompareOrdered and CompareQuiet.
+const (
+	Less Ordering = iota
+	Equal
+	Greater
+	Unordered
+)
+
+func (o Ordering) String() string {
+	switch o {
+	case Less:
+		return "Less"
+	case Equal:
+		return "Equal"
+	case Greater:
+		return "Greater"
+	case Unordered:
+		return "Unordered"
+	}
+	return "Ordering(" + strconv.Itoa(int(o)) + ")"
+}
+
+// x87 FPU status word condition code bits.
+const (
+	X87C0 = 0x0100
+	X87C1 = 0x0200
+	X87C2 = 0x0400
+	X87C3 = 0x4000
+)
+
+// x86 EFLAGS bits written by FCOMI and FUCOMI.
+const (
+	EflagsCF = 0x0001
+	EflagsPF = 0x0004
+	EflagsZF = 0x0040
+)
+
+// 68881/68882 FPSR condition code bits.
+const (
+	M68881NaN = 0x01000000
+	M68881I   = 0x02000000
+	M68881Z   = 0x04000000
+	M68881N   = 0x08000000
+)
+
+// Compares `a' with `b' and returns their ordering.  If either operand is a
+// NaN the result is Unordered; the invalid exception is raised for any NaN
+// unless `quiet' is set, in which case only signaling NaNs raise it.
+func (s *status) compareFloatX80(a, b X80, quiet bool) Ordering {
+	a, b = s.denormalOperands(a, b)
+	if (a.exp() == 0x7FFF && a.frac()<<1 != 0) || (b.exp() == 0x7FFF && b.frac()<<1 != 0) {
+		if !quiet || a.IsSignalingNaN() || b.IsSignalingNaN() {
+			s.raise(ExceptionInvalid)
+		}
+		return Unordered
+	}
+	if (a.low == b.low && a.high == b.high) || ((a.low|b.low) == 0 && (a.high|b.high)<<1 == 0) {
+		return Equal
+	}
+	if ltSignedZero(a, b) {
+		return Less
+	}
+	return Greater
+}
+
+// CompareOrdered compares the extended double-precision floating-point values
+// `a' and `b' with a single operation and returns Less, Equal, Greater or
+// Unordered.  The invalid exception is raised if either operand is a NaN, as
+// with the x87 FCOM and FCOMI instructions.
+func (a X80) CompareOrdered(b X80) Ordering {
+	s := newStatus()
+	return commit(&s, OpCompareOrdered, s.compareFloatX80(a, b, false), a, b)
+}
+
+// CompareQuiet compares the extended double-precision floating-point values
+// `a' and `b' with a single operation and returns Less, Equal, Greater or
+// Unordered.  Quiet NaNs do not cause an exception, as with the x87 FUCOM and
+// FUCOMI instructions and the 68881 FCMP instruction.
+func (a X80) CompareQuiet(b X80) Ordering {
+	s := newStatus()
+	return commit(&s, OpCompareQuiet, s.compareFloatX80(a, b, true), a, b)
+}
+
+// X87 returns the x87 status word condition code bits C3, C2 and C0 that
+// FCOM, FUCOM and FTST set for the ordering.
+func (o Ordering) X87() uint16 {
+	switch o {
+	case Less:
+		return X87C0
+	case Equal:
+		return X87C3
+	case Unordered:
+		return X87C3 | X87C2 | X87C0
+	}
+	return 0
+}
+
+// Eflags returns the x86 EFLAGS bits ZF, PF and CF that FCOMI and FUCOMI set
+// for the ordering.
+func (o Ordering) Eflags() uint32 {
+	switch o {
+	case Less:
+		return EflagsCF
+	case Equal:
+		return EflagsZF
+	case Unordered:
+		return EflagsZF | EflagsPF | EflagsCF
+	}
+	return 0
+}
+
+// M68881 returns the 68881/68882 FPSR condition code bits for the ordering:
+// Less sets N, Equal sets Z and Unordered sets NAN.  FCMP derives its
+// condition codes from the difference of its operands and also sets N for
+// the equal operands -0 and +0, in this order, and for two negative
+// infinities; these depend on the operands rather than on the ordering and
+// are left to the caller.
+func (o Ordering) M68881() uint32 {
+	switch o {
+	case Less:
+		return M68881N
+	case Equal:
+		return M68881Z
+	case Unordered:
+		return M68881NaN
+	}
+	return 0
+}
+
+// M68881ConditionCodes returns the 68881/68882 FPSR condition code bits that
+// describe `a' as the result of an operation: N is the sign bit, Z is set for
+// zeros, I for infinities and NAN for NaNs.
+func (a X80) M68881ConditionCodes() uint32 {
+	var cc uint32
+	if a.sign() {
+		cc |= M68881N
+	}
+	switch {
+	case a.IsNaN():
+		cc |= M68881NaN
+	case a.IsInf():
+		cc |= M68881I
+	case a.exp() == 0 && a.frac() == 0:
+		cc |= M68881Z
+	}
+	return cc
+}
@@ -1,6 +1,7 @@
 package float
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -135,3 +136,212 @@ func TestX80_LeQuiet(t *testing.T) {
 		t.Error("LE failed")
 	}
 }
+
+func TestX80_MinMax(t *testing.T) {
+	negZero := newFromHexString("80000000000000000000")
+	two := newFromHexString("40008000000000000000")
+	minusTwo := newFromHexString("C0008000000000000000")
+	sNaN := newFromHexString("7FFFA000000000000000")
+
+	tests := []struct {
+		name        string
+		op          func(a, b X80) X80
+		a, b        X80
+		want        X80
+		wantInvalid bool
+	}{
+		{"Min(1, 2)", X80.Min, X80One, two, X80One, false},
+		{"Min(-0, +0)", X80.Min, X80Zero, negZero, negZero, false},
+		{"Min(NaN, 1)", X80.Min, X80NaN, X80One, X80One, false},
+		{"Min(sNaN, 1)", X80.Min, sNaN, X80One, X80One, true},
+		{"Max(1, 2)", X80.Max, X80One, two, two, false},
+		{"Max(-0, +0)", X80.Max, negZero, X80Zero, X80Zero, false},
+		{"Max(1, NaN)", X80.Max, X80One, X80NaN, X80One, false},
+		{"Max(1, sNaN)", X80.Max, X80One, sNaN, X80One, true},
+		{"MinNum(NaN, 1)", X80.MinNum, X80NaN, X80One, X80One, false},
+		{"MinNum(sNaN, 1)", X80.MinNum, sNaN, X80One, X80NaN, true},
+		{"MaxNum(1, sNaN)", X80.MaxNum, X80One, sNaN, X80NaN, true},
+		{"MaxNum(-0, +0)", X80.MaxNum, negZero, X80Zero, X80Zero, false},
+		{"MinMag(-1, 2)", X80.MinMag, X80MinusOne, two, X80MinusOne, false},
+		{"MinMag(2, -2)", X80.MinMag, two, minusTwo, minusTwo, false},
+		{"MinMag(NaN, -2)", X80.MinMag, X80NaN, minusTwo, minusTwo, false},
+		{"MaxMag(-2, 1)", X80.MaxMag, minusTwo, X80One, minusTwo, false},
+		{"MaxMag(-2, 2)", X80.MaxMag, minusTwo, two, two, false},
+		{"Minimum(-0, +0)", X80.Minimum, X80Zero, negZero, negZero, false},
+		{"Minimum(NaN, 1)", X80.Minimum, X80NaN, X80One, X80NaN, false},
+		{"Minimum(1, sNaN)", X80.Minimum, X80One, sNaN, X80NaN, true},
+		{"Maximum(-0, +0)", X80.Maximum, negZero, X80Zero, X80Zero, false},
+		{"Maximum(1, NaN)", X80.Maximum, X80One, X80NaN, X80NaN, false},
+		{"Maximum(-inf, 1)", X80.Maximum, X80InfNeg, X80One, X80One, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			got := tt.op(tt.a, tt.b)
+			if tt.want.IsNaN() {
+				if !got.IsNaN() || got.IsSignalingNaN() {
+					t.Errorf("got %v, want quiet NaN", got.Internal())
+				}
+			} else if got != tt.want {
+				t.Errorf("got %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if HasException(ExceptionInvalid) != tt.wantInvalid {
+				t.Errorf("invalid exception = %v, want %v", HasException(ExceptionInvalid), tt.wantInvalid)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_TotalOrder(t *testing.T) {
+	// Values in ascending total order.
+	ordered := []X80{
+		newFromHexString("FFFFC000000000000001"), // -qNaN, larger payload
+		newFromHexString("FFFFC000000000000000"), // -qNaN
+		newFromHexString("FFFFA000000000000000"), // -sNaN
+		X80InfNeg,
+		newFromHexString("C0008000000000000000"), // -2
+		X80MinusOne,
+		newFromHexString("80000000000000000001"), // -denormal
+		newFromHexString("80000000000000000000"), // -0
+		X80Zero,
+		newFromHexString("00000000000000000001"), // +denormal
+		X80One,
+		X80Pi,
+		X80InfPos,
+		newFromHexString("7FFFA000000000000000"), // +sNaN
+		X80NaN,
+		newFromHexString("7FFFC000000000000001"), // +qNaN, larger payload
+	}
+	ClearExceptions()
+	for i := range ordered {
+		for j := range ordered {
+			a, b := ordered[i], ordered[j]
+			if got := a.TotalOrder(b); got != (i <= j) {
+				t.Errorf("%v.TotalOrder(%v) = %v, want %v", a.Internal(), b.Internal(), got, i <= j)
+			}
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%v.Compare(%v) = %v, want %v", a.Internal(), b.Internal(), got, want)
+			}
+		}
+	}
+	if HasAnyException() {
+		t.Errorf("TotalOrder raised exceptions %x", GetExceptions())
+	}
+
+	shuffled := []X80{X80NaN, X80One, X80InfNeg, X80Zero, X80MinusOne, X80InfPos}
+	slices.SortFunc(shuffled, X80.Compare)
+	want := []X80{X80InfNeg, X80MinusOne, X80Zero, X80One, X80InfPos, X80NaN}
+	if !slices.Equal(shuffled, want) {
+		t.Errorf("SortFunc(Compare) = %v, want %v", shuffled, want)
+	}
+}
+
+func TestX80_TotalOrderMag(t *testing.T) {
+	if !X80MinusOne.TotalOrderMag(X80One) || !X80One.TotalOrderMag(X80MinusOne) {
+		t.Error("TotalOrderMag(-1, 1) failed")
+	}
+	if newFromHexString("C0008000000000000000").TotalOrderMag(X80One) {
+		t.Error("TotalOrderMag(-2, 1) failed")
+	}
+	if !X80InfNeg.TotalOrderMag(X80NaN) {
+		t.Error("TotalOrderMag(-inf, NaN) failed")
+	}
+}
+
+func TestX80_CompareOrdered(t *testing.T) {
+	negZero := newFromHexString("80000000000000000000")
+	sNaN := newFromHexString("7FFFA000000000000000")
+
+	tests := []struct {
+		name             string
+		a, b             X80
+		want             Ordering
+		wantInvalid      bool
+		wantQuietInvalid bool
+	}{
+		{"1 < pi", X80One, X80Pi, Less, false, false},
+		{"pi > 1", X80Pi, X80One, Greater, false, false},
+		{"-1 < 1", X80MinusOne, X80One, Less, false, false},
+		{"-0 = +0", negZero, X80Zero, Equal, false, false},
+		{"-inf < -1", X80InfNeg, X80MinusOne, Less, false, false},
+		{"inf = inf", X80InfPos, X80InfPos, Equal, false, false},
+		{"NaN ? 1", X80NaN, X80One, Unordered, true, false},
+		{"1 ? sNaN", X80One, sNaN, Unordered, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			if got := tt.a.CompareOrdered(tt.b); got != tt.want {
+				t.Errorf("CompareOrdered() = %v, want %v", got, tt.want)
+			}
+			if HasException(ExceptionInvalid) != tt.wantInvalid {
+				t.Errorf("CompareOrdered() invalid = %v, want %v", HasException(ExceptionInvalid), tt.wantInvalid)
+			}
+			ClearExceptions()
+			if got := tt.a.CompareQuiet(tt.b); got != tt.want {
+				t.Errorf("CompareQuiet() = %v, want %v", got, tt.want)
+			}
+			if HasException(ExceptionInvalid) != tt.wantQuietInvalid {
+				t.Errorf("CompareQuiet() invalid = %v, want %v", HasException(ExceptionInvalid), tt.wantQuietInvalid)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestOrdering_ConditionCodes(t *testing.T) {
+	tests := []struct {
+		o      Ordering
+		x87    uint16
+		eflags uint32
+		m68881 uint32
+	}{
+		{Greater, 0, 0, 0},
+		{Less, X87C0, EflagsCF, M68881N},
+		{Equal, X87C3, EflagsZF, M68881Z},
+		{Unordered, X87C3 | X87C2 | X87C0, EflagsZF | EflagsPF | EflagsCF, M68881NaN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.o.String(), func(t *testing.T) {
+			if got := tt.o.X87(); got != tt.x87 {
+				t.Errorf("X87() = %#x, want %#x", got, tt.x87)
+			}
+			if got := tt.o.Eflags(); got != tt.eflags {
+				t.Errorf("Eflags() = %#x, want %#x", got, tt.eflags)
+			}
+			if got := tt.o.M68881(); got != tt.m68881 {
+				t.Errorf("M68881() = %#x, want %#x", got, tt.m68881)
+			}
+		})
+	}
+}
+
+func TestX80_M68881ConditionCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		a    X80
+		want uint32
+	}{
+		{"1", X80One, 0},
+		{"-1", X80MinusOne, M68881N},
+		{"+0", X80Zero, M68881Z},
+		{"-0", newFromHexString("80000000000000000000"), M68881N | M68881Z},
+		{"+inf", X80InfPos, M68881I},
+		{"-inf", X80InfNeg, M68881N | M68881I},
+		{"NaN", X80NaN, M68881NaN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.M68881ConditionCodes(); got != tt.want {
+				t.Errorf("M68881ConditionCodes() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
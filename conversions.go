@@ -44,7 +44,20 @@ func Int64ToFloatX80(a int64) X80 {
 // is performed according to the IEC/IEEE Standard for Binary Floating-Point
 // Arithmetic.
 func Float32ToFloatX80(a float32) X80 {
-	return Float64ToFloatX80(float64(a))
+	s := newStatus()
+	return commit(&s, OpFloat32ToFloatX80, s.float32ToFloatX80(a))
+}
+
+func (s *status) float32ToFloatX80(a float32) X80 {
+	if b := math.Float32bits(a); b&0x7F800000 == 0 && b&0x007FFFFF != 0 {
+		if s.denormalsAreZero {
+			return packFloatX80(b>>31 != 0, 0, 0)
+		}
+		if s.signalDenormal {
+			s.raise(ExceptionDenormal)
+		}
+	}
+	return s.float64ToFloatX80(float64(a))
 }
 
 // Float64ToFloatX80 returns the result of converting the double-precision floating-point value
@@ -52,6 +65,11 @@ func Float32ToFloatX80(a float32) X80 {
 // is performed according to the IEC/IEEE Standard for Binary Floating-Point
 // Arithmetic.
 func Float64ToFloatX80(a float64) X80 {
+	s := newStatus()
+	return commit(&s, OpFloat64ToFloatX80, s.float64ToFloatX80(a))
+}
+
+func (s *status) float64ToFloatX80(a float64) X80 {
 	b := math.Float64bits(a)
 	aSig := b & 0x000FFFFFFFFFFFFF
 	aExp := int((b >> 52) & 0x7FF)
@@ -66,6 +84,12 @@ func Float64ToFloatX80(a float64) X80 {
 		if aSig == 0 {
 			return packFloatX80(aSign, 0, 0)
 		}
+		if s.denormalsAreZero {
+			return packFloatX80(aSign, 0, 0)
+		}
+		if s.signalDenormal {
+			s.raise(ExceptionDenormal)
+		}
 		shiftCount := bits.LeadingZeros64(aSig) - 11
 		aExp, aSig = 1-shiftCount, aSig<<shiftCount
 	}
@@ -80,6 +104,12 @@ func Float64ToFloatX80(a float64) X80 {
 // largest positive integer is returned.  Otherwise, if the conversion
 // overflows, the largest integer with the same sign as `a' is returned.
 func (a X80) ToInt32() int32 {
+	s := newStatus()
+	return commit(&s, OpToInt32, s.toInt32(a), a)
+}
+
+func (s *status) toInt32(a X80) int32 {
+	a, _ = s.denormalOperands(a, a)
 	aSig := a.frac()
 	aExp := a.exp()
 	aSign := a.sign()
@@ -91,7 +121,7 @@ func (a X80) ToInt32() int32 {
 		shiftCount = 1
 	}
 	aSig = shift64RightJamming(aSig, int16(shiftCount))
-	return roundAndPackInt32(aSign, aSig)
+	return s.roundAndPackInt32(aSign, aSig)
 }
 
 // ToInt32RoundZero returns the result of converting the extended double-precision floating-
@@ -102,12 +132,18 @@ func (a X80) ToInt32() int32 {
 // Otherwise, if the conversion overflows, the largest integer with the same
 // sign as `a' is returned.
 func (a X80) ToInt32RoundZero() int32 {
+	s := newStatus()
+	return commit(&s, OpToInt32RoundZero, s.toInt32RoundZero(a), a)
+}
+
+func (s *status) toInt32RoundZero(a X80) int32 {
+	a, _ = s.denormalOperands(a, a)
 	aSig := a.frac()
 	aExp := a.exp()
 	aSign := a.sign()
 
 	invalid := func() int32 {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		if aSign {
 			return math.MinInt32
 		}
@@ -121,7 +157,7 @@ func (a X80) ToInt32RoundZero() int32 {
 		return invalid()
 	} else if aExp < 0x3FFF {
 		if aExp != 0 || aSig != 0 {
-			Raise(ExceptionInexact)
+			s.raise(ExceptionInexact)
 		}
 		return 0
 	}
@@ -136,7 +172,7 @@ func (a X80) ToInt32RoundZero() int32 {
 		return invalid()
 	}
 	if (aSig << shiftCount) != savedASig {
-		Raise(ExceptionInexact)
+		s.raise(ExceptionInexact)
 	}
 	return z
 }
@@ -149,20 +185,26 @@ func (a X80) ToInt32RoundZero() int32 {
 // the largest positive integer is returned.  Otherwise, if the conversion
 // overflows, the largest integer with the same sign as `a' is returned.
 func (a X80) ToInt64() int64 {
+	s := newStatus()
+	return commit(&s, OpToInt64, s.toInt64(a), a)
+}
+
+func (s *status) toInt64(a X80) int64 {
+	a, _ = s.denormalOperands(a, a)
 	aSig := a.frac()
 	aExp := a.exp()
 	aSign := a.sign()
 	shiftCount := 0x403E - aExp
 	aSigExtra := uint64(0)
 	if shiftCount < 0 {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		if !aSign || (aExp == 0x7FFF && aSig != 0x8000000000000000) {
 			return math.MaxInt64
 		}
 		return math.MinInt64
 	}
 	aSig, aSigExtra = shift64ExtraRightJamming(aSig, 0, int16(shiftCount))
-	return roundAndPackInt64(aSign, aSig, aSigExtra)
+	return s.roundAndPackInt64(aSign, aSig, aSigExtra)
 }
 
 // ToInt64RoundZero returns the result of converting the extended double-precision
@@ -173,6 +215,12 @@ func (a X80) ToInt64() int64 {
 // Otherwise, if the conversion overflows, the largest integer with the same
 // sign as `a' is returned.
 func (a X80) ToInt64RoundZero() int64 {
+	s := newStatus()
+	return commit(&s, OpToInt64RoundZero, s.toInt64RoundZero(a), a)
+}
+
+func (s *status) toInt64RoundZero(a X80) int64 {
+	a, _ = s.denormalOperands(a, a)
 	aSig := a.frac()
 	aExp := a.exp()
 	aSign := a.sign()
@@ -182,20 +230,20 @@ func (a X80) ToInt64RoundZero() int64 {
 		if a.high == 0xC03E && aSig == 0 {
 			return math.MinInt64
 		}
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		if !aSign || ((aExp == 0x7FFF) && aSig != 0) {
 			return math.MaxInt64
 		}
 		return math.MinInt64
 	} else if aExp < 0x3FFF {
 		if aExp != 0 || aSig != 0 {
-			Raise(ExceptionInexact)
+			s.raise(ExceptionInexact)
 		}
 		return 0
 	}
 	z := int64(aSig >> (-shiftCount))
 	if uint64(aSig<<(shiftCount&63)) != 0 {
-		Raise(ExceptionInexact)
+		s.raise(ExceptionInexact)
 	}
 	if aSign {
 		z = -z
@@ -204,11 +252,28 @@ func (a X80) ToInt64RoundZero() int64 {
 }
 
 // ToFloat32 returns the result of converting the extended double-precision floating-
-// point value `a' to the double-precision floating-point format.  The
+// point value `a' to the single-precision floating-point format.  The
 // conversion is performed according to the IEC/IEEE Standard for Binary
 // Floating-Point Arithmetic.
 func (a X80) ToFloat32() float32 {
-	return float32(a.ToFloat64())
+	s := newStatus()
+	return commit(&s, OpToFloat32, s.toFloat32(a), a)
+}
+
+func (s *status) toFloat32(a X80) float32 {
+	a, _ = s.denormalOperands(a, a)
+	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
+	if aExp == 0x7FFF {
+		if aSig<<1 != 0 {
+			return float32(math.NaN())
+		}
+		return packFloat32(aSign, 0xFF, 0)
+	}
+	zSig := shift64RightJamming(aSig, 33)
+	if aExp != 0 || aSig != 0 {
+		aExp -= 0x3F81
+	}
+	return s.roundAndPackFloat32(aSign, int16(aExp), zSig)
 }
 
 // ToFloat64 returns the result of converting the extended double-precision floating-
@@ -216,6 +281,12 @@ func (a X80) ToFloat32() float32 {
 // conversion is performed according to the IEC/IEEE Standard for Binary
 // Floating-Point Arithmetic.
 func (a X80) ToFloat64() float64 {
+	s := newStatus()
+	return commit(&s, OpToFloat64, s.toFloat64(a), a)
+}
+
+func (s *status) toFloat64(a X80) float64 {
+	a, _ = s.denormalOperands(a, a)
 	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
 	if aExp == 0x7FFF {
 		if aSig<<1 != 0 {
@@ -227,5 +298,5 @@ func (a X80) ToFloat64() float64 {
 	if aExp != 0 || aSig != 0 {
 		aExp -= 0x3C01
 	}
-	return roundAndPackFloat64(aSign, int16(aExp), zSig)
+	return s.roundAndPackFloat64(aSign, int16(aExp), zSig)
 }
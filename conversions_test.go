@@ -125,6 +125,17 @@ func TestInt64ToFloatX80(t *testing.T) {
 	}
 }
 
+func TestNewFromBits(t *testing.T) {
+	for _, a := range []X80{X80One, X80MinusOne, X80InfNeg, X80NaN, {0x0000, 0x0000000000000001}} {
+		if got := NewFromBits(a.high, a.low); got != a {
+			t.Errorf("NewFromBits(%04X, %016X) = %v", a.high, a.low, got.Internal())
+		}
+		if high, low := a.Bits(); high != a.high || low != a.low {
+			t.Errorf("%v.Bits() = %04X, %016X", a.Internal(), high, low)
+		}
+	}
+}
+
 func TestFloat64ToFloatX80(t *testing.T) {
 	tests := []struct {
 		name string
@@ -150,7 +161,7 @@ func TestFloat64ToFloatX80(t *testing.T) {
 		{"-0.33333", -0.33333, newFromHexString("BFFDAAAA3AD18D25F000")},
 		{"inf+", math.Inf(1), X80InfPos},
 		{"inf-", math.Inf(-1), X80InfNeg},
-		{"pi", math.Pi, X80Pi},
+		{"pi", math.Pi, newFromHexString("4000C90FDAA22168C000")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -186,3 +197,100 @@ func TestX80_ToFloat64(t *testing.T) {
 		})
 	}
 }
+
+func TestRoundingModes_Conversions(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Rounding
+		a     float64
+		int32 int32
+		int64 int64
+	}{
+		{"2.5 nearest even", RoundNearestEven, 2.5, 2, 2},
+		{"2.5 nearest away", RoundNearestAway, 2.5, 3, 3},
+		{"-2.5 nearest away", RoundNearestAway, -2.5, -3, -3},
+		{"3.5 nearest away", RoundNearestAway, 3.5, 4, 4},
+		{"2.25 nearest away", RoundNearestAway, 2.25, 2, 2},
+		{"2.5 to odd", RoundToOdd, 2.5, 3, 3},
+		{"3.5 to odd", RoundToOdd, 3.5, 3, 3},
+		{"-4.25 to odd", RoundToOdd, -4.25, -5, -5},
+		{"6 to odd", RoundToOdd, 6, 6, 6},
+	}
+	savedMode := RoundingMode
+	defer func() { RoundingMode = savedMode }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode = tt.mode
+			a := Float64ToFloatX80(tt.a)
+			if got := a.ToInt32(); got != tt.int32 {
+				t.Errorf("ToInt32() = %v, want %v", got, tt.int32)
+			}
+			if got := a.ToInt64(); got != tt.int64 {
+				t.Errorf("ToInt64() = %v, want %v", got, tt.int64)
+			}
+		})
+	}
+
+	onePlusHalfUlp := newFromHexString("3FFF8000000000000400")      // 1 + 2^-53
+	onePlusThreeHalfUlp := newFromHexString("3FFF8000000000000C00") // 1 + 3 * 2^-53
+	tests64 := []struct {
+		name string
+		mode Rounding
+		a    X80
+		want float64
+	}{
+		{"1 + 2^-53 nearest even", RoundNearestEven, onePlusHalfUlp, 1},
+		{"1 + 2^-53 nearest away", RoundNearestAway, onePlusHalfUlp, 1 + 0x1p-52},
+		{"1 + 2^-53 to odd", RoundToOdd, onePlusHalfUlp, 1 + 0x1p-52},
+		{"1 + 3*2^-53 nearest even", RoundNearestEven, onePlusThreeHalfUlp, 1 + 0x1p-51},
+		{"1 + 3*2^-53 nearest away", RoundNearestAway, onePlusThreeHalfUlp, 1 + 0x1p-51},
+		{"1 + 3*2^-53 to odd", RoundToOdd, onePlusThreeHalfUlp, 1 + 0x1p-52},
+		{"-(1 + 2^-53) nearest away", RoundNearestAway, onePlusHalfUlp.Mul(X80MinusOne), -1 - 0x1p-52},
+		{"overflow to odd", RoundToOdd, newFromHexString("7FFE8000000000000000"), math.MaxFloat64},
+		{"overflow nearest away", RoundNearestAway, newFromHexString("7FFE8000000000000000"), math.Inf(1)},
+	}
+	for _, tt := range tests64 {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode = tt.mode
+			if got := tt.a.ToFloat64(); got != tt.want {
+				t.Errorf("ToFloat64() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_ToFloat32(t *testing.T) {
+	defer func(m Rounding) { RoundingMode = m }(RoundingMode)
+	tests := []struct {
+		name string
+		mode Rounding
+		a    X80
+		want float32
+		exc  Flags
+	}{
+		{"exact", RoundNearestEven, Float64ToFloatX80(0.375), 0.375, 0},
+		{"no double rounding", RoundNearestEven, newFromHexString("3FFF8000008000000008"), 1 + 0x1p-23, ExceptionInexact},
+		{"tie to even", RoundNearestEven, newFromHexString("3FFF8000008000000000"), 1, ExceptionInexact},
+		{"down", RoundDown, Float64ToFloatX80(-1.0 / 3), -0x1.555556p-2, ExceptionInexact},
+		{"to zero", RoundToZero, Float64ToFloatX80(-1.0 / 3), -0x1.555554p-2, ExceptionInexact},
+		{"overflow", RoundNearestEven, Float64ToFloatX80(1e39), float32(math.Inf(1)), ExceptionOverflow | ExceptionInexact},
+		{"overflow to zero", RoundToZero, Float64ToFloatX80(1e39), math.MaxFloat32, ExceptionOverflow | ExceptionInexact},
+		{"subnormal", RoundNearestEven, Float64ToFloatX80(0x1p-149), 0x1p-149, 0},
+		{"underflow", RoundUp, Float64ToFloatX80(0x1p-160), 0x1p-149, ExceptionUnderflow | ExceptionInexact},
+		{"inf", RoundNearestEven, X80InfNeg, float32(math.Inf(-1)), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode = tt.mode
+			ClearExceptions()
+			if got := tt.a.ToFloat32(); got != tt.want {
+				t.Errorf("ToFloat32() = %v, want %v", got, tt.want)
+			}
+			if Exception != tt.exc {
+				t.Errorf("Exception = %v, want %v", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
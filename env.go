@@ -0,0 +1,388 @@
+package float
+
+// Env is a snapshot of the global floating-point environment, the equivalent
+// of fenv_t in C.
+type Env struct {
+	RoundingMode           Rounding
+	RoundingPrecision      int
+	DetectTininess         int
+	Exception              Flags
+	ExceptionHandler       ExceptionHandler
+	EventHandler           EventHandler
+	TrapEnable             Flags
+	TrapHandler            TrapHandler
+	SignalDenormal         bool
+	FlushToZero            bool
+	DenormalsAreZero       bool
+	DestinationFormat      bool
+	TranscendentalAccuracy Accuracy
+}
+
+// Err returns the exception flags of the environment as an error, or nil if
+// none is set.
+func (e Env) Err() error {
+	return e.Exception.Err()
+}
+
+// GetEnv returns the current floating-point environment, like fegetenv.
+func GetEnv() Env {
+	return Env{
+		RoundingMode:           RoundingMode,
+		RoundingPrecision:      RoundingPrecision,
+		DetectTininess:         DetectTininess,
+		Exception:              Exception,
+		ExceptionHandler:       exceptionHandler,
+		EventHandler:           eventHandler,
+		TrapEnable:             TrapEnable,
+		TrapHandler:            trapHandler,
+		SignalDenormal:         SignalDenormal,
+		FlushToZero:            FlushToZero,
+		DenormalsAreZero:       DenormalsAreZero,
+		DestinationFormat:      DestinationFormat,
+		TranscendentalAccuracy: TranscendentalAccuracy,
+	}
+}
+
+// SetEnv installs the floating-point environment `e', like fesetenv.  No
+// handler is called.
+func SetEnv(e Env) {
+	RoundingMode = e.RoundingMode
+	RoundingPrecision = e.RoundingPrecision
+	DetectTininess = e.DetectTininess
+	Exception = e.Exception
+	exceptionHandler = e.ExceptionHandler
+	eventHandler = e.EventHandler
+	TrapEnable = e.TrapEnable
+	trapHandler = e.TrapHandler
+	SignalDenormal = e.SignalDenormal
+	FlushToZero = e.FlushToZero
+	DenormalsAreZero = e.DenormalsAreZero
+	DestinationFormat = e.DestinationFormat
+	TranscendentalAccuracy = e.TranscendentalAccuracy
+}
+
+// HoldExcept saves the current environment, clears the exception flags and
+// masks all traps, like feholdexcept.  The saved environment is returned for
+// use with SetEnv or UpdateEnv.
+func HoldExcept() Env {
+	e := GetEnv()
+	Exception = 0
+	TrapEnable = 0
+	return e
+}
+
+// UpdateEnv saves the currently raised exception flags, installs the
+// environment `e' and then raises the saved flags, like feupdateenv.  The
+// exception handler receives the saved flags, and the trap handler is called
+// for those whose trap `e' enables, with a zero result and no operands, since
+// no single operation raised them.  No event is reported.
+func UpdateEnv(e Env) {
+	raised := Exception
+	SetEnv(e)
+	if raised == 0 {
+		return
+	}
+	Raise(raised)
+	if trapped := raised & TrapEnable; trapped != 0 && trapHandler != nil {
+		trapHandler(trapped, X80Zero, nil)
+	}
+}
+
+// TestExcept returns the accrued exception flags that are set in `mask', like
+// fetestexcept.
+func TestExcept(mask Flags) Flags {
+	return Exception & mask
+}
+
+// WithRounding calls `fn' with RoundingMode set to `mode' and restores the
+// previous rounding mode afterwards, even if `fn' panics.
+func WithRounding(mode Rounding, fn func()) {
+	saved := RoundingMode
+	defer func() { RoundingMode = saved }()
+	RoundingMode = mode
+	fn()
+}
+
+// The methods of Env perform a single operation in the environment `e'
+// instead of the global one.  They use its rounding attributes, tininess
+// detection, denormal handling and trap enables, accrue the raised flags in
+// e.Exception and call no handler, so that an emulator can keep one Env per
+// virtual FPU.  Trapped overflows and underflows return the result with the
+// wrapped exponent, as documented for TrapEnable.
+//
+// Checked and the ...Flags functions cannot serve an emulator: they fix
+// tininess detection, the denormal exception, flush-to-zero,
+// denormals-are-zero and the trap enables, which the x87 and the 68881
+// select differently, and they return the flags of one operation where an
+// instruction built from several operations needs them accrued.
+
+// Returns the status of an operation in the environment `e'.
+func (e *Env) status() status {
+	return status{
+		roundingMode:      e.RoundingMode.normalize(),
+		roundingPrecision: e.RoundingPrecision,
+		detectTininess:    e.DetectTininess,
+		signalDenormal:    e.SignalDenormal,
+		flushToZero:       e.FlushToZero,
+		denormalsAreZero:  e.DenormalsAreZero,
+		destinationFormat: e.DestinationFormat,
+		accuracy:          e.TranscendentalAccuracy,
+		trapEnable:        e.TrapEnable,
+	}
+}
+
+// Accrues the flags raised by an operation in `e' and returns its result `z'.
+func accrue[T any](e *Env, s *status, z T) T {
+	e.Exception |= s.exception
+	return z
+}
+
+// Add returns a + b.
+func (e *Env) Add(a, b X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.add(a, b))
+}
+
+// Sub returns a - b.
+func (e *Env) Sub(a, b X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.sub(a, b))
+}
+
+// Mul returns a * b.
+func (e *Env) Mul(a, b X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.mul(a, b))
+}
+
+// Div returns a / b.
+func (e *Env) Div(a, b X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.div(a, b))
+}
+
+// RemQuo returns the IEEE remainder of a with respect to b and the low 64
+// bits of the magnitude of the quotient rounded to nearest even.
+func (e *Env) RemQuo(a, b X80) (X80, uint64) {
+	s := e.status()
+	z, q := s.remQuo(a, b, false)
+	return accrue(e, &s, z), q
+}
+
+// ModQuo returns the remainder of a with respect to b with the quotient
+// truncated towards zero, and the low 64 bits of the magnitude of the
+// quotient.
+func (e *Env) ModQuo(a, b X80) (X80, uint64) {
+	s := e.status()
+	z, q := s.remQuo(a, b, true)
+	return accrue(e, &s, z), q
+}
+
+// Sqrt returns the square root of a.
+func (e *Env) Sqrt(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.sqrt(a))
+}
+
+// RoundToInt rounds a to an integer in the rounding mode of `e'.
+func (e *Env) RoundToInt(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.roundFloatX80ToInt(a, e.RoundingMode, true))
+}
+
+// Round rounds a to the rounding precision of `e'.  Signaling NaNs are
+// quieted and subnormal values are kept at full extended precision.
+func (e *Env) Round(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.roundToPrecision(a))
+}
+
+// Scalbn returns a * 2^n.
+func (e *Env) Scalbn(a X80, n int) X80 {
+	s := e.status()
+	return accrue(e, &s, s.scalbn(a, n))
+}
+
+// Ln returns the natural logarithm of a.
+func (e *Env) Ln(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.ln(a))
+}
+
+// Log1p returns ln(1 + a).
+func (e *Env) Log1p(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.log1p(a))
+}
+
+// Log2p1 returns log2(1 + a).
+func (e *Env) Log2p1(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.log2p1(a))
+}
+
+// Log2 returns the binary logarithm of a.
+func (e *Env) Log2(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.log2(a))
+}
+
+// Log10 returns the decimal logarithm of a.
+func (e *Env) Log10(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.log10(a))
+}
+
+// Exp returns e^a.
+func (e *Env) Exp(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.exp(a))
+}
+
+// Expm1 returns e^a - 1.
+func (e *Env) Expm1(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.expm1(a))
+}
+
+// Exp2m1 returns 2^a - 1.
+func (e *Env) Exp2m1(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.exp2m1(a))
+}
+
+// Exp2 returns 2^a.
+func (e *Env) Exp2(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.exp2(a))
+}
+
+// Exp10 returns 10^a.
+func (e *Env) Exp10(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.exp10(a))
+}
+
+// Sin returns the sine of a.
+func (e *Env) Sin(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.sin(a))
+}
+
+// Cos returns the cosine of a.
+func (e *Env) Cos(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.cos(a))
+}
+
+// Sincos returns the sine and the cosine of a.
+func (e *Env) Sincos(a X80) (sin, cos X80) {
+	s := e.status()
+	sin, cos = s.sincos(a)
+	return accrue(e, &s, sin), cos
+}
+
+// Tan returns the tangent of a.
+func (e *Env) Tan(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.tan(a))
+}
+
+// Asin returns the arcsine of a.
+func (e *Env) Asin(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.asin(a))
+}
+
+// Acos returns the arccosine of a.
+func (e *Env) Acos(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.acos(a))
+}
+
+// Atan returns the arctangent of a.
+func (e *Env) Atan(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.atan(a))
+}
+
+// Atan2 returns the arctangent of a/b in the quadrant of the point (b, a).
+func (e *Env) Atan2(a, b X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.atan2(a, b))
+}
+
+// Atanh returns the inverse hyperbolic tangent of a.
+func (e *Env) Atanh(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.atanh(a))
+}
+
+// Sinh returns the hyperbolic sine of a.
+func (e *Env) Sinh(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.sinh(a))
+}
+
+// Cosh returns the hyperbolic cosine of a.
+func (e *Env) Cosh(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.cosh(a))
+}
+
+// Tanh returns the hyperbolic tangent of a.
+func (e *Env) Tanh(a X80) X80 {
+	s := e.status()
+	return accrue(e, &s, s.tanh(a))
+}
+
+// Compare compares a and b, signaling the invalid exception for NaNs.
+func (e *Env) Compare(a, b X80) Ordering {
+	s := e.status()
+	return accrue(e, &s, s.compareFloatX80(a, b, false))
+}
+
+// CompareQuiet compares a and b, signaling the invalid exception only for
+// signaling NaNs.
+func (e *Env) CompareQuiet(a, b X80) Ordering {
+	s := e.status()
+	return accrue(e, &s, s.compareFloatX80(a, b, true))
+}
+
+// Float32ToFloatX80 converts the single-precision value a to extended
+// precision.
+func (e *Env) Float32ToFloatX80(a float32) X80 {
+	s := e.status()
+	return accrue(e, &s, s.float32ToFloatX80(a))
+}
+
+// Float64ToFloatX80 converts the double-precision value a to extended
+// precision.
+func (e *Env) Float64ToFloatX80(a float64) X80 {
+	s := e.status()
+	return accrue(e, &s, s.float64ToFloatX80(a))
+}
+
+// ToInt32 converts a to a 32-bit integer in the rounding mode of `e'.
+func (e *Env) ToInt32(a X80) int32 {
+	s := e.status()
+	return accrue(e, &s, s.toInt32(a))
+}
+
+// ToInt64 converts a to a 64-bit integer in the rounding mode of `e'.
+func (e *Env) ToInt64(a X80) int64 {
+	s := e.status()
+	return accrue(e, &s, s.toInt64(a))
+}
+
+// ToFloat32 converts a to single precision.
+func (e *Env) ToFloat32(a X80) float32 {
+	s := e.status()
+	return accrue(e, &s, s.toFloat32(a))
+}
+
+// ToFloat64 converts a to double precision.
+func (e *Env) ToFloat64(a X80) float64 {
+	s := e.status()
+	return accrue(e, &s, s.toFloat64(a))
+}
@@ -0,0 +1,279 @@
+package float
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnv_GetSet(t *testing.T) {
+	saved := GetEnv()
+	defer SetEnv(saved)
+
+	calls := 0
+	e := Env{
+		RoundingMode:      RoundUp,
+		RoundingPrecision: 64,
+		DetectTininess:    TininessBeforeRounding,
+		Exception:         ExceptionInexact,
+		ExceptionHandler:  func(Flags) { calls++ },
+		TrapEnable:        ExceptionOverflow,
+		SignalDenormal:    false,
+		FlushToZero:       true,
+		DenormalsAreZero:  true,
+		DestinationFormat: true,
+	}
+	SetEnv(e)
+	if RoundingMode != RoundUp || RoundingPrecision != 64 || DetectTininess != TininessBeforeRounding ||
+		Exception != ExceptionInexact || TrapEnable != ExceptionOverflow || SignalDenormal || !FlushToZero || !DenormalsAreZero ||
+		!DestinationFormat {
+		t.Fatalf("SetEnv did not install %+v", e)
+	}
+	if calls != 0 {
+		t.Errorf("SetEnv called the exception handler")
+	}
+	got := GetEnv()
+	if got.RoundingMode != e.RoundingMode || got.Exception != e.Exception || got.ExceptionHandler == nil || got.TrapEnable != e.TrapEnable {
+		t.Errorf("GetEnv() = %+v, want %+v", got, e)
+	}
+	if !errors.Is(got.Err(), ErrInexact) {
+		t.Errorf("Err() = %v, want %v", got.Err(), ErrInexact)
+	}
+	X80One.Div(X80Zero)
+	if calls != 1 {
+		t.Errorf("exception handler called %d times, want 1", calls)
+	}
+}
+
+func TestEnv_HoldUpdate(t *testing.T) {
+	saved := GetEnv()
+	defer SetEnv(saved)
+
+	var handled []Flags
+	SetExceptionHandler(func(exc Flags) { handled = append(handled, exc) })
+	TrapEnable = ExceptionDivbyzero
+	Exception = ExceptionInexact
+
+	held := HoldExcept()
+	if Exception != 0 || TrapEnable != 0 {
+		t.Fatalf("HoldExcept left Exception = %v, TrapEnable = %v", Exception, TrapEnable)
+	}
+	if held.Exception != ExceptionInexact || held.TrapEnable != ExceptionDivbyzero {
+		t.Errorf("HoldExcept() = %+v", held)
+	}
+	X80Zero.Div(X80Zero)
+	if TestExcept(ExceptionInvalid|ExceptionDivbyzero) != ExceptionInvalid {
+		t.Errorf("TestExcept() = %v, want Invalid", TestExcept(ExceptionInvalid|ExceptionDivbyzero))
+	}
+
+	handled = nil
+	UpdateEnv(held)
+	if Exception != ExceptionInvalid|ExceptionInexact {
+		t.Errorf("Exception = %v, want Invalid|Inexact", Exception)
+	}
+	if TrapEnable != ExceptionDivbyzero {
+		t.Errorf("TrapEnable = %v, want DivByZero", TrapEnable)
+	}
+	if len(handled) != 1 || handled[0] != ExceptionInvalid {
+		t.Errorf("handler got %v, want [Invalid]", handled)
+	}
+
+	var trapped Flags
+	SetTrapHandler(func(exc Flags, result X80, operands []X80) { trapped |= exc })
+	held = HoldExcept()
+	X80One.Div(X80Zero)
+	if trapped != 0 {
+		t.Errorf("held division by zero trapped %v", trapped)
+	}
+	UpdateEnv(held)
+	if trapped != ExceptionDivbyzero {
+		t.Errorf("UpdateEnv trapped %v, want DivByZero", trapped)
+	}
+}
+
+func TestWithRounding(t *testing.T) {
+	RoundingMode = RoundNearestEven
+	WithRounding(RoundUp, func() {
+		if got := X80One.Div(Int32ToFloatX80(3)); got != (X80{0x3FFD, 0xAAAAAAAAAAAAAAAB}) {
+			t.Errorf("1/3 rounded up = %v", got.Internal())
+		}
+	})
+	if RoundingMode != RoundNearestEven {
+		t.Errorf("RoundingMode = %v after WithRounding", RoundingMode)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was not propagated")
+			}
+		}()
+		WithRounding(RoundDown, func() { panic("boom") })
+	}()
+	if RoundingMode != RoundNearestEven {
+		t.Errorf("RoundingMode = %v after panic in WithRounding", RoundingMode)
+	}
+	ClearExceptions()
+}
+
+func TestEnv_Operations(t *testing.T) {
+	ClearExceptions()
+	e := Env{RoundingMode: RoundUp, RoundingPrecision: 80, DetectTininess: TininessAfterRounding}
+	if got := e.Div(X80One, Int32ToFloatX80(3)); got != (X80{0x3FFD, 0xAAAAAAAAAAAAAAAB}) {
+		t.Errorf("Div() = %v", got.Internal())
+	}
+	if e.Exception != ExceptionInexact {
+		t.Errorf("Exception = %v, want Inexact", e.Exception)
+	}
+	if got := e.RoundToInt(Float64ToFloatX80(2.25)); got != Int32ToFloatX80(3) {
+		t.Errorf("RoundToInt() = %v", got.Internal())
+	}
+	if z, q := e.RemQuo(Int32ToFloatX80(1000), Int32ToFloatX80(3)); z != X80One || q != 333 {
+		t.Errorf("RemQuo() = %v, %d", z.Internal(), q)
+	}
+	if z, q := e.ModQuo(Int32ToFloatX80(-7), Int32ToFloatX80(2)); z != X80MinusOne || q != 3 {
+		t.Errorf("ModQuo() = %v, %d", z.Internal(), q)
+	}
+	e.RoundingPrecision = 32
+	if got := e.Round(X80{0x3FFD, 0xAAAAAAAAAAAAAAAA}); got != (X80{0x3FFD, 0xAAAAAB0000000000}) {
+		t.Errorf("Round() = %v", got.Internal())
+	}
+	e.Exception = 0
+	if got := e.Ln(X80Zero); got != X80InfNeg || e.Exception != ExceptionDivbyzero {
+		t.Errorf("Ln(0) = %v, Exception = %v", got.Internal(), e.Exception)
+	}
+	if e.CompareQuiet(X80NaN, X80One) != Unordered || e.Exception&ExceptionInvalid != 0 {
+		t.Errorf("CompareQuiet raised %v", e.Exception)
+	}
+	if e.Compare(X80NaN, X80One) != Unordered || e.Exception&ExceptionInvalid == 0 {
+		t.Errorf("Compare did not raise Invalid")
+	}
+	if Exception != 0 {
+		t.Errorf("Env operations changed the global Exception to %v", Exception)
+	}
+}
+
+func TestEnv_RoundingPrecision(t *testing.T) {
+	pow2 := func(n int) X80 { return X80{uint16(0x3FFF + n), 0x8000000000000000} }
+	tests := []struct {
+		name        string
+		precision   int
+		destination bool
+		op          func(e *Env) X80
+		want        X80
+		exc         Flags
+	}{
+		{"Rem", 32, false, func(e *Env) X80 { z, _ := e.RemQuo(Int64ToFloatX80(1<<40+1), pow2(42)); return z },
+			pow2(40), ExceptionInexact},
+		{"Mod", 64, false, func(e *Env) X80 { z, _ := e.ModQuo(Int64ToFloatX80(1<<60+1), pow2(62)); return z },
+			pow2(60), ExceptionInexact},
+		{"Mod exact", 80, false, func(e *Env) X80 { z, _ := e.ModQuo(Int64ToFloatX80(1<<60+1), pow2(62)); return z },
+			Int64ToFloatX80(1<<60 + 1), 0},
+		{"RoundToInt", 32, false, func(e *Env) X80 { return e.RoundToInt(Float64ToFloatX80(33554434.5)) },
+			Int32ToFloatX80(33554436), ExceptionInexact},
+		{"RoundToInt narrow", 32, false, func(e *Env) X80 { return e.RoundToInt(Float64ToFloatX80(2.5)) },
+			Int32ToFloatX80(2), ExceptionInexact},
+		{"extended range", 32, false, func(e *Env) X80 { return e.Mul(pow2(100), pow2(100)) }, pow2(200), 0},
+		{"overflow", 32, true, func(e *Env) X80 { return e.Mul(pow2(100), pow2(100)) },
+			X80InfPos, ExceptionOverflow | ExceptionInexact},
+		{"extended precision", 80, true, func(e *Env) X80 { return e.Mul(pow2(100), pow2(100)) }, pow2(200), 0},
+		{"subnormal", 64, false, func(e *Env) X80 { return e.Mul(Float64ToFloatX80(1.75), pow2(-1073)) },
+			X80{0x3FFF - 1073, 0xE000000000000000}, 0},
+		{"subnormal destination", 64, true, func(e *Env) X80 { return e.Mul(Float64ToFloatX80(1.75), pow2(-1073)) },
+			pow2(-1072), ExceptionUnderflow | ExceptionInexact},
+		{"underflow", 32, true, func(e *Env) X80 { return e.Div(X80One, pow2(150)) },
+			X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"RoundToInt overflow", 32, true, func(e *Env) X80 { return e.RoundToInt(pow2(128)) },
+			X80InfPos, ExceptionOverflow | ExceptionInexact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Env{RoundingMode: RoundNearestEven, RoundingPrecision: tt.precision, DestinationFormat: tt.destination}
+			if got := tt.op(&e); got != tt.want || e.Exception != tt.exc {
+				t.Errorf("got %v, %v, want %v, %v", got.Internal(), e.Exception, tt.want.Internal(), tt.exc)
+			}
+		})
+	}
+}
+
+func TestEnv_MatchesGlobal(t *testing.T) {
+	saved := GetEnv()
+	defer SetEnv(saved)
+	e := Env{RoundingMode: RoundUp, RoundingPrecision: 64, DetectTininess: TininessBeforeRounding, SignalDenormal: true}
+	SetEnv(e)
+
+	a, b := Float64ToFloatX80(0.7), Float64ToFloatX80(-2.3)
+	unary := []struct {
+		name   string
+		env    func(e *Env, a X80) X80
+		global func(a X80) X80
+	}{
+		{"Sqrt", (*Env).Sqrt, X80.Sqrt},
+		{"RoundToInt", (*Env).RoundToInt, X80.RoundToInt},
+		{"Ln", (*Env).Ln, X80.Ln},
+		{"Log1p", (*Env).Log1p, X80.Log1p},
+		{"Log2p1", (*Env).Log2p1, X80.Log2p1},
+		{"Log2", (*Env).Log2, X80.Log2},
+		{"Log10", (*Env).Log10, X80.Log10},
+		{"Exp", (*Env).Exp, X80.Exp},
+		{"Expm1", (*Env).Expm1, X80.Expm1},
+		{"Exp2m1", (*Env).Exp2m1, X80.Exp2m1},
+		{"Exp2", (*Env).Exp2, X80.Exp2},
+		{"Exp10", (*Env).Exp10, X80.Exp10},
+		{"Sin", (*Env).Sin, X80.Sin},
+		{"Cos", (*Env).Cos, X80.Cos},
+		{"Tan", (*Env).Tan, X80.Tan},
+		{"Asin", (*Env).Asin, X80.Asin},
+		{"Acos", (*Env).Acos, X80.Acos},
+		{"Atan", (*Env).Atan, X80.Atan},
+		{"Atanh", (*Env).Atanh, X80.Atanh},
+		{"Sinh", (*Env).Sinh, X80.Sinh},
+		{"Cosh", (*Env).Cosh, X80.Cosh},
+		{"Tanh", (*Env).Tanh, X80.Tanh},
+	}
+	binary := []struct {
+		name   string
+		env    func(e *Env, a, b X80) X80
+		global func(a, b X80) X80
+	}{
+		{"Add", (*Env).Add, X80.Add},
+		{"Sub", (*Env).Sub, X80.Sub},
+		{"Mul", (*Env).Mul, X80.Mul},
+		{"Div", (*Env).Div, X80.Div},
+		{"Atan2", (*Env).Atan2, X80.Atan2},
+		{"RemQuo", func(e *Env, a, b X80) X80 { z, _ := e.RemQuo(a, b); return z }, X80.Rem},
+		{"ModQuo", func(e *Env, a, b X80) X80 { z, _ := e.ModQuo(a, b); return z }, X80.Mod},
+		{"Sincos", func(e *Env, a, b X80) X80 { sin, cos := e.Sincos(a); return sin.Add(cos) },
+			func(a, b X80) X80 { return a.Sin().Add(a.Cos()) }},
+		{"Scalbn", func(e *Env, a, b X80) X80 { return e.Scalbn(a, -16400) }, func(a, b X80) X80 { return a.Scalbn(-16400) }},
+		{"conversions", func(e *Env, a, b X80) X80 {
+			return e.Add(e.Float64ToFloatX80(e.ToFloat64(a)), e.Float32ToFloatX80(e.ToFloat32(b)))
+		}, func(a, b X80) X80 { return Float64ToFloatX80(a.ToFloat64()).Add(Float32ToFloatX80(b.ToFloat32())) }},
+	}
+	check := func(name string, env func(e *Env) X80, global func() X80) {
+		ClearExceptions()
+		want := global()
+		e.Exception = 0
+		if got := env(&e); got != want || e.Exception != Exception {
+			t.Errorf("%s = %v, %v, want %v, %v", name, got.Internal(), e.Exception, want.Internal(), Exception)
+		}
+	}
+	for _, x := range []X80{a, b, X80Zero, X80{0x0000, 0x4000000000000000}} {
+		for _, tt := range unary {
+			check(tt.name, func(e *Env) X80 { return tt.env(e, x) }, func() X80 { return tt.global(x) })
+		}
+		for _, tt := range binary {
+			check(tt.name, func(e *Env) X80 { return tt.env(e, x, b) }, func() X80 { return tt.global(x, b) })
+		}
+	}
+	e.Exception = 0
+	ClearExceptions()
+	if got, want := e.Compare(X80NaN, a), X80NaN.CompareOrdered(a); got != want || e.Exception != Exception {
+		t.Errorf("Compare = %v, %v, want %v, %v", got, e.Exception, want, Exception)
+	}
+	if got, want := e.CompareQuiet(X80NaN, a), X80NaN.CompareQuiet(a); got != want {
+		t.Errorf("CompareQuiet = %v, want %v", got, want)
+	}
+	if e.ToInt32(b) != b.ToInt32() || e.ToInt64(b) != b.ToInt64() {
+		t.Errorf("ToInt32, ToInt64 = %v, %v", e.ToInt32(b), e.ToInt64(b))
+	}
+	ClearExceptions()
+}
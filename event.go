@@ -0,0 +1,171 @@
+package float
+
+import "strconv"
+
+// Op identifies the operation reported in an Event.
+type Op int
+
+// Operations that report exceptions.
+const (
+	OpAdd Op = iota + 1
+	OpSub
+	OpMul
+	OpDiv
+	OpRem
+	OpSqrt
+	OpRoundToInt
+	OpRoundToIntegral
+	OpRoundToIntegralExact
+	OpFloor
+	OpCeil
+	OpTrunc
+	OpRound
+	OpRoundEven
+	OpLn
+	OpAtan
+	OpSin
+	OpCos
+	OpTan
+	OpEq
+	OpLe
+	OpLt
+	OpEqSignaling
+	OpLeQuiet
+	OpLtQuiet
+	OpMin
+	OpMax
+	OpMinNum
+	OpMaxNum
+	OpMinMag
+	OpMaxMag
+	OpMinimum
+	OpMaximum
+	OpCompareOrdered
+	OpCompareQuiet
+	OpFloat32ToFloatX80
+	OpFloat64ToFloatX80
+	OpToInt32
+	OpToInt32RoundZero
+	OpToInt64
+	OpToInt64RoundZero
+	OpToFloat32
+	OpToFloat64
+	OpExp
+	OpExpm1
+	OpExp2
+	OpExp10
+	OpLog1p
+	OpLog2
+	OpLog10
+	OpAsin
+	OpAcos
+	OpAtanh
+	OpSinh
+	OpCosh
+	OpTanh
+	OpMod
+	OpScalbn
+	OpExp2m1
+	OpLog2p1
+	OpAtan2
+	OpSincos
+)
+
+var opNames = [...]string{
+	OpAdd:                  "Add",
+	OpSub:                  "Sub",
+	OpMul:                  "Mul",
+	OpDiv:                  "Div",
+	OpRem:                  "Rem",
+	OpSqrt:                 "Sqrt",
+	OpRoundToInt:           "RoundToInt",
+	OpRoundToIntegral:      "RoundToIntegral",
+	OpRoundToIntegralExact: "RoundToIntegralExact",
+	OpFloor:                "Floor",
+	OpCeil:                 "Ceil",
+	OpTrunc:                "Trunc",
+	OpRound:                "Round",
+	OpRoundEven:            "RoundEven",
+	OpLn:                   "Ln",
+	OpAtan:                 "Atan",
+	OpSin:                  "Sin",
+	OpCos:                  "Cos",
+	OpTan:                  "Tan",
+	OpEq:                   "Eq",
+	OpLe:                   "Le",
+	OpLt:                   "Lt",
+	OpEqSignaling:          "EqSignaling",
+	OpLeQuiet:              "LeQuiet",
+	OpLtQuiet:              "LtQuiet",
+	OpMin:                  "Min",
+	OpMax:                  "Max",
+	OpMinNum:               "MinNum",
+	OpMaxNum:               "MaxNum",
+	OpMinMag:               "MinMag",
+	OpMaxMag:               "MaxMag",
+	OpMinimum:              "Minimum",
+	OpMaximum:              "Maximum",
+	OpCompareOrdered:       "CompareOrdered",
+	OpCompareQuiet:         "CompareQuiet",
+	OpFloat32ToFloatX80:    "Float32ToFloatX80",
+	OpFloat64ToFloatX80:    "Float64ToFloatX80",
+	OpToInt32:              "ToInt32",
+	OpToInt32RoundZero:     "ToInt32RoundZero",
+	OpToInt64:              "ToInt64",
+	OpToInt64RoundZero:     "ToInt64RoundZero",
+	OpToFloat32:            "ToFloat32",
+	OpToFloat64:            "ToFloat64",
+	OpExp:                  "Exp",
+	OpExpm1:                "Expm1",
+	OpExp2:                 "Exp2",
+	OpExp10:                "Exp10",
+	OpLog1p:                "Log1p",
+	OpLog2:                 "Log2",
+	OpLog10:                "Log10",
+	OpAsin:                 "Asin",
+	OpAcos:                 "Acos",
+	OpAtanh:                "Atanh",
+	OpSinh:                 "Sinh",
+	OpCosh:                 "Cosh",
+	OpTanh:                 "Tanh",
+	OpMod:                  "Mod",
+	OpScalbn:               "Scalbn",
+	OpExp2m1:               "Exp2m1",
+	OpLog2p1:               "Log2p1",
+	OpAtan2:                "Atan2",
+	OpSincos:               "Sincos",
+}
+
+func (o Op) String() string {
+	if 0 < o && int(o) < len(opNames) {
+		return opNames[o]
+	}
+	return "Op(" + strconv.Itoa(int(o)) + ")"
+}
+
+// Event describes an operation that raised floating-point exceptions.
+type Event struct {
+	Op       Op    // the operation
+	Operands []X80 // the extended double-precision operands, if any
+	Result   X80   // the default result, or the wrapped result of a trapped overflow or underflow
+	Raised   Flags // the exception flags raised by the operation
+	Accrued  Flags // the exception flags accrued in Exception, including Raised
+}
+
+// EventHandler is a function that gets called for every operation that raises
+// floating-point exceptions.
+type EventHandler func(e Event)
+
+var eventHandler EventHandler
+
+// SetEventHandler sets a handler that receives an Event for every operation
+// that raises exceptions.  It is called after the handler set with
+// SetExceptionHandler, which keeps receiving the raised flags.
+func SetEventHandler(handler EventHandler) {
+	eventHandler = handler
+}
+
+// GetEventHandler returns the current event handler.
+func GetEventHandler() EventHandler {
+	return eventHandler
+}
@@ -0,0 +1,69 @@
+package float
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOp_String(t *testing.T) {
+	tests := []struct {
+		op   Op
+		want string
+	}{
+		{OpAdd, "Add"},
+		{OpDiv, "Div"},
+		{OpLn, "Ln"},
+		{OpToInt32, "ToInt32"},
+		{OpToFloat64, "ToFloat64"},
+		{Op(0), "Op(0)"},
+		{Op(1000), "Op(1000)"},
+	}
+	for _, tt := range tests {
+		if got := tt.op.String(); got != tt.want {
+			t.Errorf("%d.String() = %q, want %q", int(tt.op), got, tt.want)
+		}
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func()
+		want Event
+	}{
+		{"Div", func() { X80One.Div(X80Zero) },
+			Event{OpDiv, []X80{X80One, X80Zero}, X80InfPos, ExceptionDivbyzero, ExceptionDivbyzero | ExceptionInexact}},
+		{"Ln", func() { X80MinusOne.Ln() },
+			Event{OpLn, []X80{X80MinusOne}, X80NaN, ExceptionInvalid, ExceptionInvalid | ExceptionInexact}},
+		{"ToInt32", func() { X80NaN.ToInt32() },
+			Event{OpToInt32, []X80{X80NaN}, Int32ToFloatX80(2147483647), ExceptionInvalid, ExceptionInvalid | ExceptionInexact}},
+		{"Float64ToFloatX80", func() { Float64ToFloatX80(5e-324) },
+			Event{OpFloat64ToFloatX80, nil, Float64ToFloatX80(5e-324), ExceptionDenormal, ExceptionDenormal | ExceptionInexact}},
+	}
+	defer SetEventHandler(nil)
+	defer SetExceptionHandler(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			var flags []Flags
+			SetEventHandler(func(e Event) { events = append(events, e) })
+			SetExceptionHandler(func(exc Flags) { flags = append(flags, exc) })
+			Exception = ExceptionInexact
+			tt.op()
+			if len(events) != 1 {
+				t.Fatalf("got %d events, want 1", len(events))
+			}
+			e := events[0]
+			if e.Op != tt.want.Op || e.Result != tt.want.Result || e.Raised != tt.want.Raised ||
+				e.Accrued != tt.want.Accrued || !slices.Equal(e.Operands, tt.want.Operands) {
+				t.Errorf("event = %+v, want %+v", e, tt.want)
+			}
+			if !slices.Equal(flags, []Flags{tt.want.Raised}) {
+				t.Errorf("exception handler got %v, want [%x]", flags, tt.want.Raised)
+			}
+		})
+	}
+	SetEventHandler(func(e Event) { t.Errorf("unexpected event %+v", e) })
+	X80One.Add(X80One)
+	ClearExceptions()
+}
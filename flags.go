@@ -0,0 +1,206 @@
+package float
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Flags is a set of IEC/IEEE floating-point exception flags.
+type Flags uint8
+
+// Rounding is an IEC/IEEE floating-point rounding mode.
+type Rounding uint8
+
+var flagNames = []struct {
+	flag Flags
+	name string
+}{
+	{ExceptionInvalid, "Invalid"},
+	{ExceptionDenormal, "Denormal"},
+	{ExceptionDivbyzero, "DivByZero"},
+	{ExceptionOverflow, "Overflow"},
+	{ExceptionUnderflow, "Underflow"},
+	{ExceptionInexact, "Inexact"},
+}
+
+// String returns the names of the flags in `f' separated by "|", for example
+// "Invalid|Inexact".  An empty set is formatted as "0" and unknown bits in
+// hexadecimal.
+func (f Flags) String() string {
+	if f == 0 {
+		return "0"
+	}
+	var names []string
+	for _, n := range flagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+			f &^= n.flag
+		}
+	}
+	if f != 0 {
+		names = append(names, "0x"+strconv.FormatUint(uint64(f), 16))
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseFlags parses a set of exception flags written as by Flags.String.  The
+// names are case-insensitive and may be separated by "|" or ",".  The empty
+// string and "0" denote the empty set.
+func ParseFlags(s string) (Flags, error) {
+	var f Flags
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ',' }) {
+		field = strings.TrimSpace(field)
+		found := false
+		for _, n := range flagNames {
+			if strings.EqualFold(field, n.name) {
+				f |= n.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("float: unknown exception flag %q", field)
+		}
+	}
+	return f, nil
+}
+
+// Sentinel errors for the individual exception flags, for use with errors.Is.
+var (
+	ErrInvalid   = errors.New("float: invalid operation")
+	ErrDenormal  = errors.New("float: denormal operand")
+	ErrDivByZero = errors.New("float: division by zero")
+	ErrOverflow  = errors.New("float: overflow")
+	ErrUnderflow = errors.New("float: underflow")
+	ErrInexact   = errors.New("float: inexact result")
+)
+
+var flagErrors = []struct {
+	flag Flags
+	err  error
+}{
+	{ExceptionInvalid, ErrInvalid},
+	{ExceptionDenormal, ErrDenormal},
+	{ExceptionDivbyzero, ErrDivByZero},
+	{ExceptionOverflow, ErrOverflow},
+	{ExceptionUnderflow, ErrUnderflow},
+	{ExceptionInexact, ErrInexact},
+}
+
+// FlagsError is the error returned for a non-empty set of exception flags.
+// errors.Is reports true for the sentinel error of every flag in the set.
+type FlagsError struct {
+	Flags Flags
+}
+
+func (e *FlagsError) Error() string {
+	return "float: floating-point exception " + e.Flags.String()
+}
+
+// Is reports whether `target' is the sentinel error of one of the flags.
+func (e *FlagsError) Is(target error) bool {
+	for _, fe := range flagErrors {
+		if e.Flags&fe.flag != 0 && target == fe.err {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil if no flag is set in `f', and a *FlagsError otherwise.
+func (f Flags) Err() error {
+	if f == 0 {
+		return nil
+	}
+	return &FlagsError{f}
+}
+
+// Err returns the accrued exception flags as an error, or nil if no exception
+// has been raised since they were last cleared.
+func Err() error {
+	return Exception.Err()
+}
+
+var roundingNames = [...]string{
+	RoundNearestEven: "NearestEven",
+	RoundToZero:      "ToZero",
+	RoundDown:        "Down",
+	RoundUp:          "Up",
+	RoundNearestAway: "NearestAway",
+	RoundToOdd:       "ToOdd",
+}
+
+// IEEE 754 attribute names accepted by ParseRounding.
+var roundingAttributes = map[string]Rounding{
+	"roundtiestoeven":     RoundNearestEven,
+	"roundtowardzero":     RoundToZero,
+	"roundtowardnegative": RoundDown,
+	"roundtowardpositive": RoundUp,
+	"roundtiestoaway":     RoundNearestAway,
+}
+
+// Valid reports whether `r' is one of the defined rounding modes.
+func (r Rounding) Valid() bool {
+	return int(r) < len(roundingNames)
+}
+
+// Returns `r' if it is a valid rounding mode and RoundNearestEven otherwise,
+// so that an invalid mode assigned directly to RoundingMode or an Env does
+// not select a directed rounding by accident.
+func (r Rounding) normalize() Rounding {
+	if r.Valid() {
+		return r
+	}
+	return RoundNearestEven
+}
+
+func (r Rounding) String() string {
+	if r.Valid() {
+		return roundingNames[r]
+	}
+	return "Rounding(" + strconv.Itoa(int(r)) + ")"
+}
+
+// ParseRounding parses a rounding mode written as by Rounding.String, with
+// or without the "Round" prefix of the constant names, or as an IEEE 754
+// rounding-direction attribute such as "roundTiesToEven".  Names are
+// case-insensitive.
+func ParseRounding(s string) (Rounding, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if r, ok := roundingAttributes[name]; ok {
+		return r, nil
+	}
+	name = strings.TrimPrefix(name, "round")
+	for r, n := range roundingNames {
+		if strings.EqualFold(name, n) {
+			return Rounding(r), nil
+		}
+	}
+	return 0, fmt.Errorf("float: unknown rounding mode %q", s)
+}
+
+// SetRoundingMode sets RoundingMode to `mode' after validating it.
+func SetRoundingMode(mode Rounding) error {
+	if !mode.Valid() {
+		return fmt.Errorf("float: invalid rounding mode %v", mode)
+	}
+	RoundingMode = mode
+	return nil
+}
+
+// SetRoundingPrecision sets RoundingPrecision to `precision', which must be
+// 32, 64 or 80.
+func SetRoundingPrecision(precision int) error {
+	switch precision {
+	case 32, 64, 80:
+		RoundingPrecision = precision
+		return nil
+	}
+	return fmt.Errorf("float: invalid rounding precision %d", precision)
+}
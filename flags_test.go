@@ -0,0 +1,142 @@
+package float
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlags_String(t *testing.T) {
+	tests := []struct {
+		f    Flags
+		want string
+	}{
+		{0, "0"},
+		{ExceptionInvalid | ExceptionInexact, "Invalid|Inexact"},
+		{ExceptionDivbyzero, "DivByZero"},
+		{ExceptionDenormal | ExceptionOverflow | ExceptionUnderflow, "Denormal|Overflow|Underflow"},
+		{ExceptionInexact | 0x40, "Inexact|0x40"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.String(); got != tt.want {
+			t.Errorf("Flags(%#x).String() = %q, want %q", uint8(tt.f), got, tt.want)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Flags
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"Invalid|Inexact", ExceptionInvalid | ExceptionInexact, false},
+		{"overflow, underflow", ExceptionOverflow | ExceptionUnderflow, false},
+		{" divbyzero ", ExceptionDivbyzero, false},
+		{"Invalid|Bogus", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFlags(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFlags(%q) = %v, %v, want %v, error %v", tt.s, got, err, tt.want, tt.wantErr)
+		}
+	}
+	all := ExceptionInvalid | ExceptionDenormal | ExceptionDivbyzero | ExceptionOverflow | ExceptionUnderflow | ExceptionInexact
+	if got, err := ParseFlags(all.String()); err != nil || got != all {
+		t.Errorf("ParseFlags(%q) = %v, %v", all.String(), got, err)
+	}
+}
+
+func TestFlags_Err(t *testing.T) {
+	if err := Flags(0).Err(); err != nil {
+		t.Errorf("Flags(0).Err() = %v, want nil", err)
+	}
+	err := (ExceptionOverflow | ExceptionInexact).Err()
+	for _, target := range []error{ErrOverflow, ErrInexact} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(%v, %v) = false", err, target)
+		}
+	}
+	for _, target := range []error{ErrInvalid, ErrDenormal, ErrDivByZero, ErrUnderflow} {
+		if errors.Is(err, target) {
+			t.Errorf("errors.Is(%v, %v) = true", err, target)
+		}
+	}
+	var fe *FlagsError
+	if !errors.As(err, &fe) || fe.Flags != ExceptionOverflow|ExceptionInexact {
+		t.Errorf("errors.As(%v) = %v", err, fe)
+	}
+
+	ClearExceptions()
+	if err := Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	X80One.Div(X80Zero)
+	if err := Err(); !errors.Is(err, ErrDivByZero) {
+		t.Errorf("Err() = %v, want %v", err, ErrDivByZero)
+	}
+	ClearExceptions()
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Rounding
+	}{
+		{"NearestEven", RoundNearestEven},
+		{"RoundToZero", RoundToZero},
+		{"down", RoundDown},
+		{"roundTowardPositive", RoundUp},
+		{"roundTiesToAway", RoundNearestAway},
+		{"ToOdd", RoundToOdd},
+	}
+	for _, tt := range tests {
+		got, err := ParseRounding(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRounding(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+		if got, err := ParseRounding(tt.want.String()); err != nil || got != tt.want {
+			t.Errorf("ParseRounding(%q) = %v, %v", tt.want.String(), got, err)
+		}
+	}
+	if _, err := ParseRounding("sideways"); err == nil {
+		t.Error("ParseRounding(\"sideways\") succeeded")
+	}
+	if Rounding(7).Valid() || Rounding(7).String() != "Rounding(7)" {
+		t.Errorf("Rounding(7) = %v, valid %v", Rounding(7), Rounding(7).Valid())
+	}
+
+	defer func() {
+		RoundingMode = RoundNearestEven
+		RoundingPrecision = 80
+	}()
+	if err := SetRoundingMode(7); err == nil || RoundingMode != RoundNearestEven {
+		t.Errorf("SetRoundingMode(7) = %v, RoundingMode = %v", err, RoundingMode)
+	}
+	if err := SetRoundingMode(RoundUp); err != nil || RoundingMode != RoundUp {
+		t.Errorf("SetRoundingMode(RoundUp) = %v, RoundingMode = %v", err, RoundingMode)
+	}
+	if err := SetRoundingPrecision(48); err == nil || RoundingPrecision != 80 {
+		t.Errorf("SetRoundingPrecision(48) = %v, RoundingPrecision = %v", err, RoundingPrecision)
+	}
+	if err := SetRoundingPrecision(64); err != nil || RoundingPrecision != 64 {
+		t.Errorf("SetRoundingPrecision(64) = %v, RoundingPrecision = %v", err, RoundingPrecision)
+	}
+
+	// Invalid modes assigned directly round to nearest even, not upwards.
+	a := X80{0x3FFD, 0xAAAAAA5555555555}
+	want := X80{0x3FFD, 0xAAAAAA0000000000}
+	RoundingMode, RoundingPrecision = 7, 32
+	if got := a.Add(X80Zero); got != want {
+		t.Errorf("Add() with RoundingMode = 7 is %v", got.Internal())
+	}
+	e := Env{RoundingMode: 7, RoundingPrecision: 32}
+	if got := e.Round(a); got != want {
+		t.Errorf("Env.Round() with RoundingMode = 7 is %v", got.Internal())
+	}
+	if got, _ := RoundToIntFlags(Float64ToFloatX80(2.5), 7); got != Int32ToFloatX80(2) {
+		t.Errorf("RoundToIntFlags(2.5, 7) = %v", got.Internal())
+	}
+	ClearExceptions()
+}
@@ -22,8 +22,8 @@
 //	product := a.Mul(b)
 //
 //	// Handle exceptions
-//	float.SetExceptionHandler(func(exc int) {
-//	    log.Printf("FP exception: %x", exc)
+//	float.SetExceptionHandler(func(exc float.Flags) {
+//	    log.Printf("FP exception: %v", exc)
 //	})
 //
 // For more examples, see the README.md file.
@@ -33,6 +33,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"math/bits"
 	"strconv"
@@ -65,49 +66,78 @@ var DetectTininess = TininessAfterRounding
 
 // Software IEC/IEEE floating-point rounding mode.
 const (
-	RoundNearestEven = 0
-	RoundToZero      = 1
-	RoundDown        = 2
-	RoundUp          = 3
+	RoundNearestEven Rounding = 0
+	RoundToZero      Rounding = 1
+	RoundDown        Rounding = 2
+	RoundUp          Rounding = 3
+	RoundNearestAway Rounding = 4 // roundTiesToAway of IEEE 754-2008
+	RoundToOdd       Rounding = 5 // truncate and set the least significant bit if inexact
 )
 
-// RoundingMode Software IEC/IEEE floating-point rounding mode.
+// RoundingMode Software IEC/IEEE floating-point rounding mode.  Assignments
+// are not checked; operations treat an invalid mode as RoundNearestEven.  Use
+// SetRoundingMode to reject invalid modes.
 var RoundingMode = RoundNearestEven
 
 // Software IEC/IEEE floating-point exception flags.
 const (
-	ExceptionInvalid   = 0x01
-	ExceptionDenormal  = 0x02
-	ExceptionDivbyzero = 0x04
-	ExceptionOverflow  = 0x08
-	ExceptionUnderflow = 0x10
-	ExceptionInexact   = 0x20
+	ExceptionInvalid   Flags = 0x01
+	ExceptionDenormal  Flags = 0x02
+	ExceptionDivbyzero Flags = 0x04
+	ExceptionOverflow  Flags = 0x08
+	ExceptionUnderflow Flags = 0x10
+	ExceptionInexact   Flags = 0x20
 )
 
 // Exception Software IEC/IEEE floating-point exception flags.
-var Exception int = 0
+var Exception Flags = 0
 
 // RoundingPrecision Software IEC/IEEE extended double-precision rounding precision.  Valid
 // values are 32, 64, and 80.
 var RoundingPrecision = 80
 
-// "constants" fpr X80 format
+// SignalDenormal controls whether operations raise ExceptionDenormal when they
+// consume a subnormal or pseudo-denormal operand, as the x87 and 68881 do.  Set
+// it to false to get the SoftFloat behavior, where the flag is never raised.
+// The default differs from earlier versions of this package: exception
+// handlers now also receive ExceptionDenormal.
+var SignalDenormal = true
+
+// DenormalsAreZero makes operations treat subnormal and pseudo-denormal
+// operands as zeros of the same sign, like the DAZ bit of the SSE MXCSR.  No
+// denormal exception is raised for operands replaced this way.
+var DenormalsAreZero = false
+
+// FlushToZero makes operations return a zero of the appropriate sign instead
+// of a subnormal result, like the FTZ bit of the SSE MXCSR.  The underflow and
+// inexact exceptions are raised whenever a result is flushed.
+var FlushToZero = false
+
+// DestinationFormat makes a RoundingPrecision of 32 or 64 also narrow the
+// exponent range to that of single or double precision, so that results
+// overflow, underflow and become subnormal as if they were stored to a
+// variable of the destination format.  By default the rounding precision
+// narrows only the significand and keeps the 15-bit exponent, as the x87
+// precision control does.
+var DestinationFormat = false
+
+// "constants" for X80 format, correctly rounded to nearest
 var (
 	X80Zero     = newFromHexString("00000000000000000000") // 0
 	X80One      = newFromHexString("3FFF8000000000000000") // 1
 	X80MinusOne = newFromHexString("BFFF8000000000000000") // -1
-	X80E        = newFromHexString("4000ADF85458A2BB4800") // e
-	X80Pi       = newFromHexString("4000C90FDAA22168C000") // pi
-	X80Sqrt2    = newFromHexString("BFFFB504F333F9DE6800") // sqrt(2)
-	X80Log2E    = newFromHexString("3FFFB8AA3B295C17F000") // Log2(e)
-	X80Ln2      = newFromHexString("3FFEB17217F7D1CF7800") // Ln(2)
+	X80E        = newFromHexString("4000ADF85458A2BB4A9B") // e
+	X80Pi       = newFromHexString("4000C90FDAA22168C235") // pi
+	X80Sqrt2    = newFromHexString("3FFFB504F333F9DE6484") // sqrt(2)
+	X80Log2E    = newFromHexString("3FFFB8AA3B295C17F0BC") // Log2(e)
+	X80Ln2      = newFromHexString("3FFEB17217F7D1CF79AC") // Ln(2)
 	X80InfPos   = newFromHexString("7FFF8000000000000000") // inf+
 	X80InfNeg   = newFromHexString("FFFF8000000000000000") // inf-
 	X80NaN      = newFromHexString("7FFFC000000000000000") // NaN
 )
 
 // ExceptionHandler is a function that gets called when a floating-point exception occurs.
-type ExceptionHandler func(exception int)
+type ExceptionHandler func(exception Flags)
 
 // Global exception handler. Can be set by users to customize error handling.
 var exceptionHandler ExceptionHandler
@@ -130,12 +160,12 @@ func ClearExceptions() {
 }
 
 // GetExceptions returns the current exception flags.
-func GetExceptions() int {
+func GetExceptions() Flags {
 	return Exception
 }
 
 // HasException checks if a specific exception flag is set.
-func HasException(flag int) bool {
+func HasException(flag Flags) bool {
 	return (Exception & flag) != 0
 }
 
@@ -145,18 +175,141 @@ func HasAnyException() bool {
 }
 
 // ClearException clears a specific exception flag.
-func ClearException(flag int) {
+func ClearException(flag Flags) {
 	Exception &^= flag
 }
 
 // Raise any or all of the software IEC/IEEE floating-point exception flags.
-func Raise(x int) {
+func Raise(x Flags) {
 	Exception |= x
 	if exceptionHandler != nil {
 		exceptionHandler(x)
 	}
 }
 
+// TrapEnable is the mask of exception flags whose traps are enabled, like the
+// unmasked exceptions of the x87 control word or the enable byte of the 68881
+// FPCR.  Masked exceptions produce the default results.  If the overflow or
+// underflow trap is enabled, an operation instead returns the rounded result
+// with its exponent wrapped by 24576 (1536 for double precision) as IEEE
+// 754-1985 requires.  Results too far out of range for the wrapped exponent
+// to fit the format get the default result.
+var TrapEnable Flags = 0
+
+// TrapHandler is a function that gets called when an operation raises an
+// exception whose trap is enabled.  It receives the trapped flags, the result
+// of the operation and the operands.  Integer and boolean results are reported
+// as their exact extended double-precision values.
+type TrapHandler func(exception Flags, result X80, operands []X80)
+
+var trapHandler TrapHandler
+
+// SetTrapHandler sets the handler that is called for exceptions enabled in
+// TrapEnable.  The handler is called once per operation, after the exception
+// flags have been accrued.
+func SetTrapHandler(handler TrapHandler) {
+	trapHandler = handler
+}
+
+// GetTrapHandler returns the current trap handler.
+func GetTrapHandler() TrapHandler {
+	return trapHandler
+}
+
+// Exponent adjustments applied to the results of trapped overflows and
+// underflows.
+const (
+	trapBiasX80     = 0x6000 // 24576
+	trapBiasFloat64 = 0x600  // 1536
+	trapBiasFloat32 = 0xC0   // 192
+)
+
+// status holds the floating-point environment of a single operation and the
+// exception flags it raises.  Every operation captures the global environment
+// in a status and commits the raised flags once it is done, so that nested
+// operations report as one.
+type status struct {
+	roundingMode      Rounding
+	roundingPrecision int
+	detectTininess    int
+	signalDenormal    bool
+	flushToZero       bool
+	denormalsAreZero  bool
+	destinationFormat bool
+	accuracy          Accuracy
+	trapEnable        Flags
+	exception         Flags
+}
+
+// Returns a status initialised from the global floating-point environment.
+func newStatus() status {
+	return status{
+		roundingMode:      RoundingMode.normalize(),
+		roundingPrecision: RoundingPrecision,
+		detectTininess:    DetectTininess,
+		signalDenormal:    SignalDenormal,
+		flushToZero:       FlushToZero,
+		denormalsAreZero:  DenormalsAreZero,
+		destinationFormat: DestinationFormat,
+		accuracy:          TranscendentalAccuracy,
+		trapEnable:        TrapEnable,
+	}
+}
+
+// Raises the exception flags `x' for the operation in progress.
+func (s *status) raise(x Flags) {
+	s.exception |= x
+}
+
+// Finishes the operation `op' with result `z' on `operands': the raised
+// exception flags are accrued in Exception, the exception and event handlers
+// are called and enabled traps are delivered to the trap handler.
+func commit[T any](s *status, op Op, z T, operands ...X80) T {
+	if s.exception == 0 {
+		return z
+	}
+	Raise(s.exception)
+	trapped := s.exception & s.trapEnable
+	if eventHandler == nil && (trapped == 0 || trapHandler == nil) {
+		return z
+	}
+	result, ops := toX80(z), append([]X80(nil), operands...)
+	if eventHandler != nil {
+		eventHandler(Event{Op: op, Operands: ops, Result: result, Raised: s.exception, Accrued: Exception})
+	}
+	if trapped != 0 && trapHandler != nil {
+		trapHandler(trapped, result, ops)
+	}
+	return z
+}
+
+// Returns the exact extended double-precision value of an operation result.
+func toX80(z any) X80 {
+	switch z := z.(type) {
+	case X80:
+		return z
+	case float64:
+		var s status
+		return s.float64ToFloatX80(z)
+	case float32:
+		var s status
+		return s.float64ToFloatX80(float64(z))
+	case int32:
+		return Int32ToFloatX80(z)
+	case int64:
+		return Int64ToFloatX80(z)
+	case int:
+		return Int64ToFloatX80(int64(z))
+	case bool:
+		if z {
+			return X80One
+		}
+	case Ordering:
+		return Int64ToFloatX80(int64(z))
+	}
+	return X80Zero
+}
+
 // NewFromFloat64 returns the result of converting the double-precision floating-point value
 // `a' to the extended double-precision floating-point format.  The conversion
 // is performed according to the IEC/IEEE Standard for Binary Floating-Point
@@ -165,6 +318,19 @@ func NewFromFloat64(a float64) X80 {
 	return Float64ToFloatX80(a)
 }
 
+// NewFromBits returns the extended double-precision value with the sign and
+// exponent `high' and the significand `low', including the explicit integer
+// bit.
+func NewFromBits(high uint16, low uint64) X80 {
+	return X80{high: high, low: low}
+}
+
+// Bits returns the sign and exponent and the significand of `a', the
+// inverse of NewFromBits.
+func (a X80) Bits() (high uint16, low uint64) {
+	return a.high, a.low
+}
+
 // Bytes returns a byte array in byte order LittleEndian or BigEndian of
 // an extended double precision float
 func (a X80) Bytes(order binary.ByteOrder) []byte {
@@ -178,12 +344,10 @@ func (a X80) Bytes(order binary.ByteOrder) []byte {
 // NewFromBytes returns a new extended double precision float from a byte array in
 // byte order LittleEndian or BigEndian
 func NewFromBytes(b []byte, order binary.ByteOrder) X80 {
-	buf := bytes.NewReader(b)
-	var result X80
-	if err := binary.Read(buf, order, result); err != nil {
-		panic(err)
+	if len(b) < 10 {
+		panic(io.ErrUnexpectedEOF)
 	}
-	return result
+	return X80{high: order.Uint16(b), low: order.Uint64(b[2:])}
 }
 
 // Returns the faction bits
@@ -218,10 +382,32 @@ func newFromHexString(s string) X80 {
 	return X80{uint16(high), low}
 }
 
+// Prepares the operands `a' and `b' of an operation.  If DenormalsAreZero is
+// set, subnormal and pseudo-denormal operands are replaced by zeros of the
+// same sign.  Otherwise the denormal exception is raised if denormal signaling
+// is enabled and either operand is subnormal or pseudo-denormal.  Nothing is
+// raised when one of the operands is a NaN, since NaN propagation takes
+// precedence.
+func (s *status) denormalOperands(a, b X80) (X80, X80) {
+	if s.denormalsAreZero {
+		if a.IsDenormal() {
+			a = packFloatX80(a.sign(), 0, 0)
+		}
+		if b.IsDenormal() {
+			b = packFloatX80(b.sign(), 0, 0)
+		}
+		return a, b
+	}
+	if s.signalDenormal && (a.IsDenormal() || b.IsDenormal()) && !a.IsNaN() && !b.IsNaN() {
+		s.raise(ExceptionDenormal)
+	}
+	return a, b
+}
+
 // Takes two extended double-precision floating-point values `a' and `b', one
 // of which is a NaN, and returns the appropriate NaN result.  If either `a' or
 // `b' is a signaling NaN, the invalid exception is raised.
-func propagateFloatX80NaN(a, b X80) X80 {
+func (s *status) propagateFloatX80NaN(a, b X80) X80 {
 	aIsNaN := a.IsNaN()
 	aIsSignalingNaN := a.IsSignalingNaN()
 	bIsNaN := b.IsNaN()
@@ -229,7 +415,7 @@ func propagateFloatX80NaN(a, b X80) X80 {
 	a.low |= 0xC000000000000000
 	b.low |= 0xC000000000000000
 	if aIsSignalingNaN || bIsSignalingNaN {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 	}
 	if aIsNaN {
 		if aIsSignalingNaN && bIsNaN {
@@ -251,6 +437,12 @@ func (a X80) IsSignalingNaN() bool {
 	return (a.high&0x7fff) == 0x7fff && aLow<<1 != 0 && a.low == aLow
 }
 
+// IsDenormal returns true if the value is subnormal or pseudo-denormal, that is
+// the exponent is zero and the significand is not, otherwise false
+func (a X80) IsDenormal() bool {
+	return a.exp() == 0 && a.low != 0
+}
+
 // IsInf returns true if the value is positive or negative infinity, otherwise false
 func (a X80) IsInf() bool {
 	return (a.high&0x7fff) == 0x7fff && a.low == 0x8000000000000000
@@ -267,13 +459,16 @@ func (a X80) IsInf() bool {
 // returned.  If the abstract value is too small, the input value is rounded to
 // a subnormal number, and the underflow and inexact exceptions are raised if
 // the abstract input cannot be represented exactly as a subnormal extended
-// double-precision floating-point number.
+// double-precision floating-point number.  If FlushToZero is set, a tiny
+// result is replaced by a zero of the same sign and the underflow and inexact
+// exceptions are raised instead.
 //
 //	If `roundingPrecision' is 32 or 64, the result is rounded to the same
 //
-// number of bits as single or double precision, respectively.  Otherwise, the
-// result is rounded to the full precision of the extended double-precision
-// format.
+// number of bits as single or double precision, respectively, and if
+// DestinationFormat is set, also to the exponent range of that format.
+// Otherwise, the result is rounded to the full precision of the extended
+// double-precision format.
 //
 //	The input significand must be normalized or smaller.  If the input
 //
@@ -281,13 +476,14 @@ func (a X80) IsInf() bool {
 // returned is a subnormal number, and it must not require rounding.  The
 // handling of underflow and overflow follows the IEC/IEEE Standard for Binary
 // Floating-Point Arithmetic.
-func roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zSig1 uint64) X80 {
-	roundingMode := RoundingMode
+func (s *status) roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zSig1 uint64) X80 {
+	roundingMode := s.roundingMode
 	roundNearestEven := roundingMode == RoundNearestEven
+	roundToOdd := roundingMode == RoundToOdd
 
 	overflow := func(roundMask uint64) X80 {
-		Raise(ExceptionOverflow | ExceptionInexact)
-		if roundingMode == RoundToZero ||
+		s.raise(ExceptionOverflow | ExceptionInexact)
+		if roundingMode == RoundToZero || roundToOdd ||
 			(zSign && roundingMode == RoundUp) ||
 			(!zSign && roundingMode == RoundDown) {
 			return packFloatX80(zSign, 0x7FFE, ^roundMask)
@@ -299,52 +495,68 @@ func roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zS
 		if zSig1 != 0 {
 			zSig0 |= 1
 		}
-		if !roundNearestEven {
-			if roundingMode == RoundToZero {
-				roundIncrement = 0
+		switch roundingMode {
+		case RoundNearestEven, RoundNearestAway:
+		case RoundToZero, RoundToOdd:
+			roundIncrement = 0
+		default:
+			roundIncrement = roundMask
+			if zSign {
+				if roundingMode == RoundUp {
+					roundIncrement = 0
+				}
 			} else {
-				roundIncrement = roundMask
-				if zSign {
-					if roundingMode == RoundUp {
-						roundIncrement = 0
-					}
-				} else {
-					if roundingMode == RoundDown {
-						roundIncrement = 0
-					}
+				if roundingMode == RoundDown {
+					roundIncrement = 0
 				}
 			}
 		}
 		roundBits := zSig0 & roundMask
 		if 0x7FFD <= uint32(zExp-1) {
 			if 0x7FFE < zExp || ((zExp == 0x7FFE) && (zSig0+uint64(roundIncrement) < zSig0)) {
-				return overflow(uint64(roundingMode))
+				if s.trapEnable&ExceptionOverflow == 0 || 0x7FFE <= zExp-trapBiasX80 {
+					return overflow(roundMask)
+				}
+				s.raise(ExceptionOverflow)
+				zExp -= trapBiasX80
 			}
 			if zExp <= 0 {
-				isTiny := DetectTininess == TininessBeforeRounding || zExp < 0 || zSig0 <= zSig0+roundIncrement
-				zSig0 = shift64RightJamming(zSig0, 1-int16(zExp))
-				zExp = 0
-				roundBits = zSig0 & roundMask
-				if isTiny && roundBits != 0 {
-					Raise(ExceptionUnderflow)
-				}
-				if roundBits != 0 {
-					Raise(ExceptionInexact)
-				}
-				zSig0 += roundIncrement
-				if int64(zSig0) < 0 {
-					zExp = 1
-				}
-				roundIncrement = roundMask + 1
-				if roundNearestEven && (roundBits<<1 == roundIncrement) {
-					roundMask |= roundIncrement
+				isTiny := s.detectTininess == TininessBeforeRounding || zExp < 0 || zSig0 <= zSig0+roundIncrement
+				if isTiny && s.trapEnable&ExceptionUnderflow != 0 && 0 < zExp+trapBiasX80 {
+					s.raise(ExceptionUnderflow)
+					zExp += trapBiasX80
+				} else {
+					if s.flushToZero && isTiny && zSig0 != 0 {
+						s.raise(ExceptionUnderflow | ExceptionInexact)
+						return packFloatX80(zSign, 0, 0)
+					}
+					zSig0 = shift64RightJamming(zSig0, 1-int16(zExp))
+					zExp = 0
+					roundBits = zSig0 & roundMask
+					if isTiny && roundBits != 0 {
+						s.raise(ExceptionUnderflow)
+					}
+					if roundBits != 0 {
+						s.raise(ExceptionInexact)
+					}
+					zSig0 += roundIncrement
+					if int64(zSig0) < 0 {
+						zExp = 1
+					}
+					roundIncrement = roundMask + 1
+					if roundNearestEven && (roundBits<<1 == roundIncrement) {
+						roundMask |= roundIncrement
+					}
+					zSig0 &= ^roundMask
+					if roundToOdd && roundBits != 0 {
+						zSig0 |= roundIncrement
+					}
+					return packFloatX80(zSign, zExp, zSig0)
 				}
-				zSig0 &= ^roundMask
-				return packFloatX80(zSign, zExp, zSig0)
 			}
 		}
 		if roundBits != 0 {
-			Raise(ExceptionInexact)
+			s.raise(ExceptionInexact)
 		}
 		zSig0 += roundIncrement
 		if zSig0 < uint64(roundIncrement) {
@@ -356,71 +568,86 @@ func roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zS
 			roundMask |= roundIncrement
 		}
 		zSig0 &= ^uint64(roundMask)
+		if roundToOdd && roundBits != 0 {
+			zSig0 |= roundIncrement
+		}
 		if zSig0 == 0 {
 			zExp = 0
 		}
 		return packFloatX80(zSign, zExp, zSig0)
 	}
 
+	// Returns true if the significand `zSig0' must be incremented to round
+	// away the extra bits in `zSig1' at full extended precision.
+	increment80 := func() bool {
+		switch roundingMode {
+		case RoundNearestEven, RoundNearestAway:
+			return int64(zSig1) < 0
+		case RoundDown:
+			return zSign && zSig1 != 0
+		case RoundUp:
+			return !zSign && zSig1 != 0
+		}
+		return false
+	}
+
+	if s.destinationFormat && (roundingPrecision == 32 || roundingPrecision == 64) {
+		return s.roundAndPackDestination(roundingPrecision, zSign, zExp, zSig0, zSig1)
+	}
 	switch roundingPrecision {
 	case 64:
 		return precision64(0x0000000000000400, 0x00000000000007FF)
 	case 32:
 		return precision64(0x0000008000000000, 0x000000FFFFFFFFFF)
 	default: // 80
-		increment := int64(zSig1) < 0
-		if !roundNearestEven {
-			if roundingMode == RoundToZero {
-				increment = false
-			} else {
-				if zSign {
-					increment = roundingMode == RoundDown && zSig1 != 0
-				} else {
-					increment = roundingMode == RoundUp && zSig1 != 0
-				}
-			}
-		}
+		increment := increment80()
 		if 0x7FFD <= uint32(zExp-1) {
 			if (0x7FFE < zExp) ||
 				(zExp == 0x7FFE && zSig0 == 0xFFFFFFFFFFFFFFFF && increment) {
-				return overflow(0)
+				if s.trapEnable&ExceptionOverflow == 0 || 0x7FFE <= zExp-trapBiasX80 {
+					return overflow(0)
+				}
+				s.raise(ExceptionOverflow)
+				zExp -= trapBiasX80
 			}
 			if zExp <= 0 {
-				isTiny := DetectTininess == TininessBeforeRounding ||
+				isTiny := s.detectTininess == TininessBeforeRounding ||
 					zExp < 0 ||
 					!increment ||
 					zSig0 < 0xFFFFFFFFFFFFFFFF
-				zSig0, zSig1 = shift64ExtraRightJamming(zSig0, zSig1, 1-int16(zExp))
-				zExp = 0
-				if isTiny && zSig1 != 0 {
-					Raise(ExceptionUnderflow)
-				}
-				if zSig1 != 0 {
-					Raise(ExceptionInexact)
-				}
-				if roundNearestEven {
-					increment = int64(zSig1) < 0
+				if isTiny && s.trapEnable&ExceptionUnderflow != 0 && 0 < zExp+trapBiasX80 {
+					s.raise(ExceptionUnderflow)
+					zExp += trapBiasX80
 				} else {
-					if zSign {
-						increment = (roundingMode == RoundDown) && zSig1 != 0
-					} else {
-						increment = (roundingMode == RoundUp) && zSig1 != 0
+					if s.flushToZero && isTiny && (zSig0|zSig1) != 0 {
+						s.raise(ExceptionUnderflow | ExceptionInexact)
+						return packFloatX80(zSign, 0, 0)
 					}
-				}
-				if increment {
-					zSig0++
-					if zSig1<<1 == 0 && roundNearestEven {
-						zSig0 &= ^uint64(1)
+					zSig0, zSig1 = shift64ExtraRightJamming(zSig0, zSig1, 1-int16(zExp))
+					zExp = 0
+					if isTiny && zSig1 != 0 {
+						s.raise(ExceptionUnderflow)
 					}
-					if int64(zSig0) < 0 {
-						zExp = 1
+					if zSig1 != 0 {
+						s.raise(ExceptionInexact)
+					}
+					if increment80() {
+						zSig0++
+						if zSig1<<1 == 0 && roundNearestEven {
+							zSig0 &= ^uint64(1)
+						}
+						if int64(zSig0) < 0 {
+							zExp = 1
+						}
+					} else if roundToOdd && zSig1 != 0 {
+						zSig0 |= 1
 					}
+					return packFloatX80(zSign, zExp, zSig0)
 				}
-				return packFloatX80(zSign, zExp, zSig0)
 			}
 		}
 		if zSig1 != 0 {
-			Raise(ExceptionInexact)
+			s.raise(ExceptionInexact)
 		}
 
 		if increment {
@@ -434,6 +661,9 @@ func roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zS
 				}
 			}
 		} else {
+			if roundToOdd && zSig1 != 0 {
+				zSig0 |= 1
+			}
 			if zSig0 == 0 {
 				zExp = 0
 			}
@@ -442,6 +672,33 @@ func roundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zS
 	}
 }
 
+// Rounds the abstract value of roundAndPackFloatX80 to single precision if
+// `roundingPrecision' is 32 or to double precision otherwise, with the
+// exponent range of that format, and returns the result in the extended
+// format, which represents it exactly.
+func (s *status) roundAndPackDestination(roundingPrecision int, zSign bool, zExp int, zSig0, zSig1 uint64) X80 {
+	if zSig1 != 0 {
+		zSig0 |= 1
+	}
+	bias := 0x3C01
+	if roundingPrecision == 32 {
+		bias = 0x3F81
+	}
+	if zSig0 != 0 {
+		zExp -= bias
+		if zExp < -0x7FFF {
+			zExp = -0x7FFF
+		} else if zExp > 0x7FFF {
+			zExp = 0x7FFF
+		}
+	}
+	var exact status
+	if roundingPrecision == 32 {
+		return exact.float32ToFloatX80(s.roundAndPackFloat32(zSign, int16(zExp), shift64RightJamming(zSig0, 33)))
+	}
+	return exact.float64ToFloatX80(s.roundAndPackFloat64(zSign, int16(zExp), shift64RightJamming(zSig0, 1)))
+}
+
 // Packs the sign `zSign', exponent `zExp', and significand `zSig' into an
 // extended double-precision floating-point value, returning the result.
 func packFloatX80(zSign bool, zExp int, zSig uint64) X80 {
@@ -461,7 +718,7 @@ func packFloatX80(zSign bool, zExp int, zSig uint64) X80 {
 // corresponding to the abstract input.  This routine is just like
 // `roundAndPackFloatx80' except that the input significand does not have to be
 // normalized.
-func normalizeRoundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zSig1 uint64) X80 {
+func (s *status) normalizeRoundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int, zSig0, zSig1 uint64) X80 {
 	if zSig0 == 0 {
 		zSig0 = zSig1
 		zSig1 = 0
@@ -470,7 +727,7 @@ func normalizeRoundAndPackFloatX80(roundingPrecision int, zSign bool, zExp int,
 	shiftCount := bits.LeadingZeros64(zSig0)
 	zSig0, zSig1 = shortShift128Left(zSig0, zSig1, int16(shiftCount))
 	zExp -= shiftCount
-	return roundAndPackFloatX80(roundingPrecision, zSign, zExp, zSig0, zSig1)
+	return s.roundAndPackFloatX80(roundingPrecision, zSign, zExp, zSig0, zSig1)
 }
 
 // Normalizes the subnormal extended double-precision floating-point value
@@ -490,45 +747,47 @@ func normalizeFloatX80Subnormal(aSig uint64) (zExp int, zSig uint64) {
 // input cannot be represented exactly as an integer.  However, if the fixed-
 // point input is too large, the invalid exception is raised and the largest
 // positive or negative integer is returned.
-func roundAndPackInt32(zSign bool, absZ uint64) int32 {
-	roundingMode := RoundingMode
-	roundNearestEven := roundingMode == RoundNearestEven
+func (s *status) roundAndPackInt32(zSign bool, absZ uint64) int32 {
+	roundingMode := s.roundingMode
 	roundIncrement := uint64(0x40)
 
-	if !roundNearestEven {
-		if roundingMode == RoundToZero {
-			roundIncrement = 0
+	switch roundingMode {
+	case RoundNearestEven, RoundNearestAway:
+	case RoundToZero, RoundToOdd:
+		roundIncrement = 0
+	default:
+		roundIncrement = 0x7F
+		if zSign {
+			if roundingMode == RoundUp {
+				roundIncrement = 0
+			}
 		} else {
-			roundIncrement = 0x7F
-			if zSign {
-				if roundingMode == RoundUp {
-					roundIncrement = 0
-				}
-			} else {
-				if roundingMode == RoundDown {
-					roundIncrement = 0
-				}
+			if roundingMode == RoundDown {
+				roundIncrement = 0
 			}
 		}
 	}
 	roundBits := absZ & 0x7F
 	absZ = (absZ + roundIncrement) >> 7
-	if (roundBits^0x40) == 0 && roundNearestEven {
+	if (roundBits^0x40) == 0 && roundingMode == RoundNearestEven {
 		absZ &= ^uint64(1)
 	}
+	if roundBits != 0 && roundingMode == RoundToOdd {
+		absZ |= 1
+	}
 	z := int32(absZ)
 	if zSign {
 		z = -z
 	}
 	if (absZ>>32) != 0 || (z != 0 && (z < 0) != zSign) {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		if zSign {
 			return math.MinInt32
 		}
 		return math.MaxInt32
 	}
 	if roundBits != 0 {
-		Raise(ExceptionInexact)
+		s.raise(ExceptionInexact)
 	}
 	return z
 }
@@ -542,28 +801,27 @@ func roundAndPackInt32(zSign bool, absZ uint64) int32 {
 // an integer.  However, if the fixed-point input is too large, the invalid
 // exception is raised and the largest positive or negative integer is
 // returned.
-func roundAndPackInt64(zSign bool, absZ0, absZ1 uint64) int64 {
-	roundingMode := RoundingMode
-	roundNearestEven := roundingMode == RoundNearestEven
+func (s *status) roundAndPackInt64(zSign bool, absZ0, absZ1 uint64) int64 {
+	roundingMode := s.roundingMode
 	increment := int64(absZ1) < 0
 
 	overflow := func() int64 {
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		if zSign {
 			return math.MinInt64
 		}
 		return math.MaxInt64
 	}
 
-	if !roundNearestEven {
-		if roundingMode == RoundToZero {
-			increment = false
+	switch roundingMode {
+	case RoundNearestEven, RoundNearestAway:
+	case RoundToZero, RoundToOdd:
+		increment = false
+	default:
+		if zSign {
+			increment = roundingMode == RoundDown && absZ1 != 0
 		} else {
-			if zSign {
-				increment = roundingMode == RoundDown && absZ1 != 0
-			} else {
-				increment = roundingMode == RoundUp && absZ1 != 0
-			}
+			increment = roundingMode == RoundUp && absZ1 != 0
 		}
 	}
 	if increment {
@@ -571,9 +829,11 @@ func roundAndPackInt64(zSign bool, absZ0, absZ1 uint64) int64 {
 		if absZ0 == 0 {
 			return overflow()
 		}
-		if absZ1<<1 == 0 && roundNearestEven {
+		if absZ1<<1 == 0 && roundingMode == RoundNearestEven {
 			absZ0 &= ^uint64(1)
 		}
+	} else if absZ1 != 0 && roundingMode == RoundToOdd {
+		absZ0 |= 1
 	}
 	z := int64(absZ0)
 	if zSign {
@@ -583,11 +843,93 @@ func roundAndPackInt64(zSign bool, absZ0, absZ1 uint64) int64 {
 		return overflow()
 	}
 	if absZ1 != 0 {
-		Raise(ExceptionInexact)
+		s.raise(ExceptionInexact)
 	}
 	return z
 }
 
+// Packs the sign `zSign', exponent `zExp', and significand `zSig' into a
+// single-precision floating-point value, returning the result.  As in
+// packFloat64, any integer portion of `zSig' is added into the exponent.
+func packFloat32(zSign bool, zExp int16, zSig uint64) float32 {
+	return math.Float32frombits(uint32(x1(zSign)<<31 + uint64(zExp)<<23 + zSig))
+}
+
+// Takes an abstract floating-point value having sign `zSign', exponent `zExp',
+// and significand `zSig', and returns the proper single-precision floating-
+// point value corresponding to the abstract input.  This is the single-
+// precision counterpart of roundAndPackFloat64: the input significand `zSig'
+// is at most 31 bits wide and has its binary point between bits 30 and 29,
+// which is 7 bits to the left of the usual location.
+func (s *status) roundAndPackFloat32(zSign bool, zExp int16, zSig uint64) float32 {
+	roundingMode := s.roundingMode
+	roundIncrement := uint64(0x40)
+	switch roundingMode {
+	case RoundNearestEven, RoundNearestAway:
+	case RoundToZero, RoundToOdd:
+		roundIncrement = 0
+	default:
+		roundIncrement = 0x7F
+		if zSign {
+			if roundingMode == RoundUp {
+				roundIncrement = 0
+			}
+		} else {
+			if roundingMode == RoundDown {
+				roundIncrement = 0
+			}
+		}
+	}
+	roundBits := zSig & 0x7F
+	if 0xFD <= uint16(zExp) {
+		if 0xFD < zExp || (zExp == 0xFD && zSig+roundIncrement >= 0x80000000) {
+			if s.trapEnable&ExceptionOverflow != 0 && zExp-trapBiasFloat32 < 0xFD {
+				s.raise(ExceptionOverflow)
+				return s.roundAndPackFloat32(zSign, zExp-trapBiasFloat32, zSig)
+			}
+			s.raise(ExceptionOverflow | ExceptionInexact)
+			result := packFloat32(zSign, 0xFF, 0)
+			if roundIncrement == 0 {
+				return math.Float32frombits(math.Float32bits(result) - 1)
+			}
+			return result
+		}
+		if zExp < 0 {
+			isTiny := s.detectTininess == TininessBeforeRounding ||
+				zExp < -1 ||
+				zSig+roundIncrement < 0x80000000
+			if isTiny && s.trapEnable&ExceptionUnderflow != 0 && 0 <= zExp+trapBiasFloat32 {
+				s.raise(ExceptionUnderflow)
+				return s.roundAndPackFloat32(zSign, zExp+trapBiasFloat32, zSig)
+			}
+			if s.flushToZero && isTiny {
+				s.raise(ExceptionUnderflow | ExceptionInexact)
+				return packFloat32(zSign, 0, 0)
+			}
+			zSig = shift64RightJamming(zSig, -zExp)
+			zExp = 0
+			roundBits = zSig & 0x7F
+			if isTiny && roundBits != 0 {
+				s.raise(ExceptionUnderflow)
+			}
+		}
+	}
+	if roundBits != 0 {
+		s.raise(ExceptionInexact)
+	}
+	zSig = (zSig + roundIncrement) >> 7
+	if (roundBits^0x40) == 0 && roundingMode == RoundNearestEven {
+		zSig &= ^uint64(1)
+	}
+	if roundBits != 0 && roundingMode == RoundToOdd {
+		zSig |= 1
+	}
+	if zSig == 0 {
+		zExp = 0
+	}
+	return packFloat32(zSign, zExp, zSig)
+}
+
 // Packs the sign `zSign', exponent `zExp', and significand `zSig' into a
 // double-precision floating-point value, returning the result.  After being
 // shifted into the proper positions, the three fields are simply added
@@ -621,52 +963,68 @@ func packFloat64(zSign bool, zExp int16, zSig uint64) float64 {
 // normalized, `zExp' must be 1 less than the “true” floating-point exponent.
 // The handling of underflow and overflow follows the IEC/IEEE Standard for
 // Binary Floating-Point Arithmetic.
-func roundAndPackFloat64(zSign bool, zExp int16, zSig uint64) float64 {
-	roundingMode := RoundingMode
-	roundNearestEven := roundingMode == RoundNearestEven
+func (s *status) roundAndPackFloat64(zSign bool, zExp int16, zSig uint64) float64 {
+	roundingMode := s.roundingMode
 	roundIncrement := int64(0x200)
-	if !roundNearestEven {
-		if roundingMode == RoundToZero {
-			roundIncrement = 0
+	switch roundingMode {
+	case RoundNearestEven, RoundNearestAway:
+	case RoundToZero, RoundToOdd:
+		roundIncrement = 0
+	default:
+		roundIncrement = 0x3FF
+		if zSign {
+			if roundingMode == RoundUp {
+				roundIncrement = 0
+			}
 		} else {
-			roundIncrement = 0x3FF
-			if zSign {
-				if roundingMode == RoundUp {
-					roundIncrement = 0
-				}
-			} else {
-				if roundingMode == RoundDown {
-					roundIncrement = 0
-				}
+			if roundingMode == RoundDown {
+				roundIncrement = 0
 			}
 		}
 	}
 	roundBits := zSig & 0x3FF
 	if 0x7FD <= uint16(zExp) {
 		if 0x7FD < zExp || (zExp == 0x7FD && int64(zSig)+roundIncrement < 0) {
-			Raise(ExceptionOverflow | ExceptionInexact)
+			if s.trapEnable&ExceptionOverflow != 0 && zExp-trapBiasFloat64 < 0x7FD {
+				s.raise(ExceptionOverflow)
+				return s.roundAndPackFloat64(zSign, zExp-trapBiasFloat64, zSig)
+			}
+			s.raise(ExceptionOverflow | ExceptionInexact)
 			result := packFloat64(zSign, 0x7FF, 0)
 			if roundIncrement == 0 {
-				return result - 1
+				return math.Float64frombits(math.Float64bits(result) - 1)
 			}
 			return result
 		}
 		if zExp < 0 {
-			isTiny := DetectTininess == TininessBeforeRounding || zExp < -1
+			isTiny := s.detectTininess == TininessBeforeRounding ||
+				zExp < -1 ||
+				zSig+uint64(roundIncrement) < 0x8000000000000000
+			if isTiny && s.trapEnable&ExceptionUnderflow != 0 && 0 <= zExp+trapBiasFloat64 {
+				s.raise(ExceptionUnderflow)
+				return s.roundAndPackFloat64(zSign, zExp+trapBiasFloat64, zSig)
+			}
+			if s.flushToZero && isTiny {
+				s.raise(ExceptionUnderflow | ExceptionInexact)
+				return packFloat64(zSign, 0, 0)
+			}
 			zSig = shift64RightJamming(zSig, -zExp)
 			zExp = 0
 			roundBits = zSig & 0x3FF
 			if isTiny && roundBits != 0 {
-				Raise(ExceptionUnderflow)
+				s.raise(ExceptionUnderflow)
 			}
 		}
 	}
 	if roundBits != 0 {
-		Raise(ExceptionInexact)
+		s.raise(ExceptionInexact)
 	}
 	zSig = uint64(int64(zSig)+roundIncrement) >> 10
-	if (roundBits^0x200) == 0 && roundNearestEven {
-		zSig &= uint64(1)
+	if (roundBits^0x200) == 0 && roundingMode == RoundNearestEven {
+		zSig &= ^uint64(1)
+	}
+	if roundBits != 0 && roundingMode == RoundToOdd {
+		zSig |= 1
 	}
 	if zSig == 0 {
 		zExp = 0
@@ -1,7 +1,11 @@
 package float_test
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math"
+	"testing"
 
 	"github.com/jenska/float"
 )
@@ -12,5 +16,81 @@ func ExampleX80() {
 	sqrtpi2 := pi2.Sqrt()
 	epsilon := sqrtpi2.Mul(sqrtpi2).Sub(pi2)
 	fmt.Println(epsilon)
-	// Output: -0.000000000000000000433680868994
+	// Output: 0.000000000000000000000000000000
+}
+
+func TestX80_Bytes(t *testing.T) {
+	tests := []struct {
+		name  string
+		order binary.ByteOrder
+		want  []byte
+	}{
+		{"BigEndian", binary.BigEndian, []byte{0x40, 0x00, 0xC9, 0x0F, 0xDA, 0xA2, 0x21, 0x68, 0xC2, 0x35}},
+		{"LittleEndian", binary.LittleEndian, []byte{0x00, 0x40, 0x35, 0xC2, 0x68, 0x21, 0xA2, 0xDA, 0x0F, 0xC9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := float.X80Pi.Bytes(tt.order)
+			if !bytes.Equal(b, tt.want) {
+				t.Errorf("Bytes() = % X, want % X", b, tt.want)
+			}
+			if got := float.NewFromBytes(b, tt.order); got != float.X80Pi {
+				t.Errorf("NewFromBytes() = %v, want %v", got.Internal(), float.X80Pi.Internal())
+			}
+		})
+	}
+}
+
+func TestX80_Bits(t *testing.T) {
+	for _, x := range []float.X80{float.X80Pi, float.X80MinusOne, float.X80InfPos, float.X80NaN, float.NewFromBits(0x8000, 1)} {
+		high, low := x.Bits()
+		if got := float.NewFromBits(high, low); got != x {
+			t.Errorf("NewFromBits(%04X, %016X) = %v, want %v", high, low, got.Internal(), x.Internal())
+		}
+	}
+	if high, low := float.X80Pi.Bits(); high != 0x4000 || low != 0xC90FDAA22168C235 {
+		t.Errorf("X80Pi.Bits() = %04X, %016X, want 4000, C90FDAA22168C235", high, low)
+	}
+}
+
+// Results that the SoftFloat port rounded incorrectly before the rounding
+// modes were reworked.
+func TestRoundAndPack(t *testing.T) {
+	maxNormal := float.NewFromBits(0x7FFE, 0xFFFFFFFFFFFFFFFF)
+	belowMin := float.NewFromBits(0x3C00, 0xFFFFFFFFFFFFFFF0) // (1 - 2^-60) * 2^-1022
+	two := float.Int32ToFloatX80(2)
+	tests := []struct {
+		name string
+		a    float.X80
+		mode float.Rounding
+		want float64
+		exc  float.Flags
+	}{
+		{"tie to even down", float.NewFromBits(0x3FFF, 0x8000000000000400), float.RoundNearestEven, 1, float.ExceptionInexact},
+		{"tie to even up", float.NewFromBits(0x3FFF, 0x8000000000000C00), float.RoundNearestEven, 1 + 0x1p-51, float.ExceptionInexact},
+		{"overflow to zero", maxNormal, float.RoundToZero, math.MaxFloat64, float.ExceptionOverflow | float.ExceptionInexact},
+		{"negative overflow up", float.NewFromBits(0xFFFE, 0xFFFFFFFFFFFFFFFF), float.RoundUp, -math.MaxFloat64, float.ExceptionOverflow | float.ExceptionInexact},
+		{"tiny after rounding", belowMin, float.RoundToZero, math.Float64frombits(0x000FFFFFFFFFFFFF),
+			float.ExceptionUnderflow | float.ExceptionInexact},
+		{"not tiny after rounding", belowMin, float.RoundNearestEven, 0x1p-1022, float.ExceptionInexact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, exc := float.ToFloat64Flags(tt.a, tt.mode); got != tt.want || exc != tt.exc {
+				t.Errorf("ToFloat64Flags() = %v, %v, want %v, %v", got, exc, tt.want, tt.exc)
+			}
+		})
+	}
+	for _, tt := range []struct {
+		precision int
+		want      float.X80
+	}{
+		{64, float.NewFromBits(0x7FFE, 0xFFFFFFFFFFFFF800)},
+		{32, float.NewFromBits(0x7FFE, 0xFFFFFF0000000000)},
+	} {
+		got, exc := float.MulFlags(maxNormal, two, float.RoundToZero, tt.precision)
+		if got != tt.want || exc != float.ExceptionOverflow|float.ExceptionInexact {
+			t.Errorf("MulFlags() at precision %d = %v, %v", tt.precision, got.Internal(), exc)
+		}
+	}
 }
@@ -0,0 +1,124 @@
+package m68881
+
+// Condition is a conditional predicate of FBcc, FDBcc, FScc and FTRAPcc, as
+// encoded in the six-bit condition field of the instruction.  The IEEE-aware
+// predicates 0x00 to 0x0F never raise BSUN; the IEEE-nonaware predicates
+// 0x10 to 0x1F test the same conditions but raise BSUN if the NaN condition
+// code is set.  Encodings above 0x1F are undefined.
+type Condition int
+
+// IEEE-aware predicates.
+const (
+	CondF   Condition = 0x00 // false
+	CondEQ  Condition = 0x01 // equal
+	CondOGT Condition = 0x02 // ordered greater than
+	CondOGE Condition = 0x03 // ordered greater than or equal
+	CondOLT Condition = 0x04 // ordered less than
+	CondOLE Condition = 0x05 // ordered less than or equal
+	CondOGL Condition = 0x06 // ordered greater or less than
+	CondOR  Condition = 0x07 // ordered
+	CondUN  Condition = 0x08 // unordered
+	CondUEQ Condition = 0x09 // unordered or equal
+	CondUGT Condition = 0x0A // unordered or greater than
+	CondUGE Condition = 0x0B // unordered or greater than or equal
+	CondULT Condition = 0x0C // unordered or less than
+	CondULE Condition = 0x0D // unordered or less than or equal
+	CondNE  Condition = 0x0E // not equal
+	CondT   Condition = 0x0F // true
+)
+
+// IEEE-nonaware predicates.
+const (
+	CondSF   Condition = 0x10 // signaling false
+	CondSEQ  Condition = 0x11 // signaling equal
+	CondGT   Condition = 0x12 // greater than
+	CondGE   Condition = 0x13 // greater than or equal
+	CondLT   Condition = 0x14 // less than
+	CondLE   Condition = 0x15 // less than or equal
+	CondGL   Condition = 0x16 // greater or less than
+	CondGLE  Condition = 0x17 // greater, less or equal
+	CondNGLE Condition = 0x18 // not greater, less or equal
+	CondNGL  Condition = 0x19 // not greater or less than
+	CondNLE  Condition = 0x1A // not less than or equal
+	CondNLT  Condition = 0x1B // not less than
+	CondNGE  Condition = 0x1C // not greater than or equal
+	CondNGT  Condition = 0x1D // not greater than
+	CondSNE  Condition = 0x1E // signaling not equal
+	CondST   Condition = 0x1F // signaling true
+)
+
+var conditionNames = [32]string{
+	"F", "EQ", "OGT", "OGE", "OLT", "OLE", "OGL", "OR",
+	"UN", "UEQ", "UGT", "UGE", "ULT", "ULE", "NE", "T",
+	"SF", "SEQ", "GT", "GE", "LT", "LE", "GL", "GLE",
+	"NGLE", "NGL", "NLE", "NLT", "NGE", "NGT", "SNE", "ST",
+}
+
+// String returns the mnemonic suffix of the predicate, such as "OGT".
+func (c Condition) String() string {
+	if c < 0 || c > CondST {
+		return "?"
+	}
+	return conditionNames[c]
+}
+
+// Eval evaluates the predicate for the condition code bits `cc', as set in
+// FPSR by FCMP, FTST or an arithmetic instruction, and reports whether BSUN
+// is raised, which the IEEE-nonaware predicates do if NaN is set.  The
+// infinity bit does not take part in any predicate.  Undefined encodings
+// evaluate to false.
+func (c Condition) Eval(cc uint32) (result, bsun bool) {
+	if c < 0 || c > CondST {
+		return false, false
+	}
+	n, z, nan := cc&CCN != 0, cc&CCZ != 0, cc&CCNaN != 0
+	switch c & 0x0F {
+	case CondEQ:
+		result = z
+	case CondOGT:
+		result = !(nan || z || n)
+	case CondOGE:
+		result = z || !(nan || n)
+	case CondOLT:
+		result = n && !(nan || z)
+	case CondOLE:
+		result = z || n && !nan
+	case CondOGL:
+		result = !(nan || z)
+	case CondOR:
+		result = !nan
+	case CondUN:
+		result = nan
+	case CondUEQ:
+		result = nan || z
+	case CondUGT:
+		result = nan || !(n || z)
+	case CondUGE:
+		result = nan || z || !n
+	case CondULT:
+		result = nan || n && !z
+	case CondULE:
+		result = nan || z || n
+	case CondNE:
+		result = !z
+	case CondT:
+		result = true
+	}
+	return result, nan && c&0x10 != 0
+}
+
+// Evaluate evaluates the predicate `c' for the condition codes in FPSR, as
+// FBcc, FDBcc, FScc and FTRAPcc do.  If BSUN is raised, it is set in the
+// exception status byte together with IOP in the accrued exception byte;
+// the other exception bits are not changed.  The instruction is to be
+// completed with `result' unless `ok' is false, which an enabled BSUN
+// exception causes.
+func (f *FPU) Evaluate(c Condition) (result, ok bool) {
+	result, bsun := c.Eval(f.FPSR)
+	if bsun {
+		f.FPSR |= BSUN | AccruedIOP
+		f.active = true
+		return result, f.FPCR&BSUN == 0
+	}
+	return result, true
+}
@@ -0,0 +1,74 @@
+package m68881
+
+import (
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func TestCondition_Eval(t *testing.T) {
+	// Results for FPn < src, FPn == src, FPn > src and unordered operands.
+	tests := []struct {
+		aware, nonaware Condition
+		want            string
+	}{
+		{CondF, CondSF, "0000"},
+		{CondEQ, CondSEQ, "0100"},
+		{CondOGT, CondGT, "0010"},
+		{CondOGE, CondGE, "0110"},
+		{CondOLT, CondLT, "1000"},
+		{CondOLE, CondLE, "1100"},
+		{CondOGL, CondGL, "1010"},
+		{CondOR, CondGLE, "1110"},
+		{CondUN, CondNGLE, "0001"},
+		{CondUEQ, CondNGL, "0101"},
+		{CondUGT, CondNLE, "0011"},
+		{CondUGE, CondNLT, "0111"},
+		{CondULT, CondNGE, "1001"},
+		{CondULE, CondNGT, "1101"},
+		{CondNE, CondSNE, "1011"},
+		{CondT, CondST, "1111"},
+	}
+	operands := [4][2]float.X80{{one, two}, {two, two}, {two, one}, {qnanA, one}}
+	for _, tt := range tests {
+		for i, ops := range operands {
+			var f FPU
+			f.FP[0] = ops[0]
+			f.FCMP(ops[1], 0)
+			want := tt.want[i] == '1'
+			for _, c := range []Condition{tt.aware, tt.nonaware} {
+				got, bsun := c.Eval(f.FPSR)
+				if got != want || bsun != (i == 3 && c == tt.nonaware) {
+					t.Errorf("%v.Eval(%#08x) = %v, %v", c, f.FPSR&CCMask, got, bsun)
+				}
+			}
+		}
+	}
+	if got, _ := CondOLT.Eval(CCN | CCZ); got {
+		t.Error("OLT true for -0 == +0")
+	}
+	if got, bsun := Condition(0x20).Eval(CCNaN); got || bsun {
+		t.Errorf("Eval() of undefined predicate = %v, %v", got, bsun)
+	}
+	if s := CondNGLE.String(); s != "NGLE" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestFPU_Evaluate(t *testing.T) {
+	var f FPU
+	f.FPSR = CCNaN | INEX2
+	if result, ok := f.Evaluate(CondUN); !result || !ok || f.FPSR != CCNaN|INEX2 {
+		t.Errorf("Evaluate(UN) = %v, %v, FPSR = %#08x", result, ok, f.FPSR)
+	}
+	if result, ok := f.Evaluate(CondGT); result || !ok || f.FPSR != CCNaN|INEX2|BSUN|AccruedIOP {
+		t.Errorf("Evaluate(GT) = %v, %v, FPSR = %#08x", result, ok, f.FPSR)
+	}
+	f.FPCR = BSUN
+	if _, ok := f.Evaluate(CondNGLE); ok {
+		t.Error("Evaluate(NGLE) completes with BSUN enabled")
+	}
+	if v, ok := f.PendingException(); !ok || v != VectorBSUN {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorBSUN)
+	}
+}
@@ -0,0 +1,198 @@
+package m68881
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/jenska/float"
+)
+
+// Format is the source or destination data format of an FMOVE, encoded as in
+// the source specifier field of the instruction.
+type Format int
+
+const (
+	FormatLong          Format = 0 // L: 32-bit integer
+	FormatSingle        Format = 1 // S: single precision
+	FormatExtended      Format = 2 // X: extended precision
+	FormatPacked        Format = 3 // P: packed decimal real, static k-factor
+	FormatWord          Format = 4 // W: 16-bit integer
+	FormatDouble        Format = 5 // D: double precision
+	FormatByte          Format = 6 // B: 8-bit integer
+	FormatPackedDynamic Format = 7 // P: packed decimal real, dynamic k-factor
+)
+
+// Size returns the size of the format in memory in bytes.
+func (fm Format) Size() int {
+	switch fm {
+	case FormatLong, FormatSingle:
+		return 4
+	case FormatWord:
+		return 2
+	case FormatDouble:
+		return 8
+	case FormatByte:
+		return 1
+	}
+	return 12
+}
+
+// Load converts the operand in format `fm' at the start of `b', in big-endian
+// memory order, to extended precision and returns it with the exception
+// status bits of the conversion.  Integers, single and double precision
+// values convert exactly; signaling NaNs keep their signaling state for the
+// instruction to detect.  Packed decimal reals are rounded in the rounding
+// mode `mode' and set INEX1 if inexact.  Load panics if `b' is shorter than
+// the size of the format.
+func Load(b []byte, fm Format, mode float.Rounding) (a float.X80, exc uint32) {
+	b = b[:fm.Size()]
+	switch fm {
+	case FormatByte:
+		return float.Int32ToFloatX80(int32(int8(b[0]))), 0
+	case FormatWord:
+		return float.Int32ToFloatX80(int32(int16(binary.BigEndian.Uint16(b)))), 0
+	case FormatLong:
+		return float.Int32ToFloatX80(int32(binary.BigEndian.Uint32(b))), 0
+	case FormatSingle:
+		u := binary.BigEndian.Uint32(b)
+		if u&0x7FFFFFFF > 0x7F800000 {
+			return float.NewFromBits(uint16(u>>16)&0x8000|0x7FFF, 0x8000000000000000|uint64(u)<<40), 0
+		}
+		var e float.Env
+		return e.Float32ToFloatX80(math.Float32frombits(u)), 0
+	case FormatDouble:
+		u := binary.BigEndian.Uint64(b)
+		if u&0x7FFFFFFFFFFFFFFF > 0x7FF0000000000000 {
+			return float.NewFromBits(uint16(u>>48)&0x8000|0x7FFF, 0x8000000000000000|u<<11), 0
+		}
+		var e float.Env
+		return e.Float64ToFloatX80(math.Float64frombits(u)), 0
+	case FormatExtended:
+		return float.NewFromBits(binary.BigEndian.Uint16(b), binary.BigEndian.Uint64(b[4:])), 0
+	}
+	var p PackedDecimal
+	copy(p[:], b)
+	a, inexact := p.X80(mode)
+	if inexact {
+		exc = INEX1
+	}
+	return a, exc
+}
+
+// Store converts `a' to format `fm' in big-endian memory order, rounding in
+// the rounding mode `mode', and returns the bytes with the exception status
+// bits of the conversion.  `k' is the k-factor of the packed formats, as
+// described for NewPackedDecimal, and is ignored for the others.
+//
+// Integer conversions of infinities, NaNs and values out of range are
+// operand errors: the largest integer with the sign of the operand is
+// stored, or the most significant bits of the significand of a NaN.  Single
+// and double precision conversions set OVFL, UNFL and INEX2 and store the
+// default results for disabled exceptions.  NaNs keep the most significant
+// bits of their significand, and signaling NaNs set SNAN and are quieted.
+func Store(a float.X80, fm Format, k int, mode float.Rounding) (b []byte, exc uint32) {
+	b = make([]byte, fm.Size())
+	high, low := a.Bits()
+	if a.IsSignalingNaN() {
+		exc = SNAN
+		low |= 0x4000000000000000
+		a = float.NewFromBits(high, low)
+	}
+	switch fm {
+	case FormatByte, FormatWord, FormatLong:
+		bits := 8 * fm.Size()
+		var n int64
+		if a.IsNaN() {
+			n = int64(low >> (64 - bits))
+			exc |= OPERR
+		} else {
+			e := float.Env{RoundingMode: mode, RoundingPrecision: 80}
+			n = e.ToInt64(a)
+			limit := int64(1) << (bits - 1)
+			switch {
+			case e.Exception&float.ExceptionInvalid != 0 || n >= limit || n < -limit:
+				n = limit - 1
+				if negative(a) {
+					n = -limit
+				}
+				exc |= OPERR
+			default:
+				exc |= exceptions(e.Exception)
+			}
+		}
+		for i := range b {
+			b[i] = byte(n >> (bits - 8 - 8*i))
+		}
+	case FormatSingle:
+		var u uint32
+		if a.IsNaN() {
+			u = uint32(high&0x8000)<<16 | 0x7F800000 | uint32(low>>40)&0x7FFFFF
+		} else {
+			exc |= convert(mode, func(e *float.Env) { u = math.Float32bits(e.ToFloat32(a)) })
+		}
+		binary.BigEndian.PutUint32(b, u)
+	case FormatDouble:
+		var u uint64
+		if a.IsNaN() {
+			u = uint64(high&0x8000)<<48 | 0x7FF0000000000000 | low>>11&0xFFFFFFFFFFFFF
+		} else {
+			exc |= convert(mode, func(e *float.Env) { u = math.Float64bits(e.ToFloat64(a)) })
+		}
+		binary.BigEndian.PutUint64(b, u)
+	case FormatExtended:
+		binary.BigEndian.PutUint16(b, high)
+		binary.BigEndian.PutUint64(b[4:], low)
+	default:
+		p, pexc := NewPackedDecimal(a, k, mode)
+		copy(b, p[:])
+		exc |= pexc
+	}
+	return b, exc
+}
+
+// Runs the conversion `op' in the rounding mode `mode' and returns its
+// exception status bits.  Tininess is detected before rounding, as in the
+// FPCR environment; the result of `op' is that of the untrapped conversion.
+func convert(mode float.Rounding, op func(e *float.Env)) uint32 {
+	e := float.Env{
+		RoundingMode:      mode,
+		RoundingPrecision: 80,
+		DetectTininess:    float.TininessBeforeRounding,
+		TrapEnable:        float.ExceptionOverflow | float.ExceptionUnderflow,
+	}
+	op(&e)
+	flags := e.Exception
+	if flags&(float.ExceptionOverflow|float.ExceptionUnderflow) != 0 {
+		e.TrapEnable = 0
+		e.Exception = 0
+		op(&e)
+		flags |= e.Exception
+	}
+	return exceptions(flags)
+}
+
+// FMOVEFrom converts the operand in format `fm' at the start of `src' to
+// extended precision and moves it to FPn, rounded to the selected precision,
+// as FMOVE <ea>,FPn does.
+func (f *FPU) FMOVEFrom(src []byte, fm Format, dst int) {
+	a, lexc := Load(src, fm, f.RoundingMode())
+	z, wrapped, exc := f.execute(func(e *float.Env) float.X80 { return e.Round(a) }, a)
+	if f.complete(a, wrapped, exc|lexc) {
+		f.store(dst, z)
+	}
+}
+
+// FMOVETo converts FPm to format `fm' in the rounding mode selected by FPCR,
+// as FMOVE FPm,<ea> does, with the k-factor `k' for the packed formats.  The
+// condition codes are not changed.  The bytes are to be written to the
+// destination unless `ok' is false, which enabled SNAN and OPERR exceptions
+// cause; for the packed formats only an enabled SNAN exception does.
+func (f *FPU) FMOVETo(src int, fm Format, k int) (b []byte, ok bool) {
+	a := f.FP[src]
+	b, exc := Store(a, fm, k, f.RoundingMode())
+	ok = f.complete(a, a, exc)
+	if fm == FormatPacked || fm == FormatPackedDynamic {
+		ok = exc&f.FPCR&SNAN == 0
+	}
+	return b, ok
+}
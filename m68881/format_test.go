@@ -0,0 +1,138 @@
+package m68881
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func mem(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+		fm   Format
+		mode float.Rounding
+		want float.X80
+		exc  uint32
+	}{
+		{"byte", "FF", FormatByte, float.RoundNearestEven, float.X80MinusOne, 0},
+		{"word", "8000", FormatWord, float.RoundNearestEven, float.Int32ToFloatX80(-32768), 0},
+		{"long", "7FFFFFFF", FormatLong, float.RoundNearestEven, float.Int32ToFloatX80(0x7FFFFFFF), 0},
+		{"single", "3FC00000", FormatSingle, float.RoundNearestEven, float.NewFromFloat64(1.5), 0},
+		{"single denormal", "80000001", FormatSingle, float.RoundNearestEven, float.NewFromBits(0xBF6A, 0x8000000000000000), 0},
+		{"single SNaN", "FFA00001", FormatSingle, float.RoundNearestEven, float.NewFromBits(0xFFFF, 0xA000010000000000), 0},
+		{"double", "BFF8000000000000", FormatDouble, float.RoundNearestEven, float.NewFromFloat64(-1.5), 0},
+		{"double inf", "7FF0000000000000", FormatDouble, float.RoundNearestEven, float.X80InfPos, 0},
+		{"double SNaN", "7FF4000000000001", FormatDouble, float.RoundNearestEven, float.NewFromBits(0x7FFF, 0xA000000000000800), 0},
+		{"extended", "3FFF0000C000000000000000", FormatExtended, float.RoundNearestEven, float.NewFromFloat64(1.5), 0},
+		{"packed", "400100010000000000000000", FormatPacked, float.RoundToZero, float.NewFromBits(0x3FFB, 0xCCCCCCCCCCCCCCCC), INEX1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exc := Load(mem(tt.b), tt.fm, tt.mode)
+			if got != tt.want {
+				t.Errorf("Load() = %s, want %s", got.Internal(), tt.want.Internal())
+			}
+			if exc != tt.exc {
+				t.Errorf("exc = %#04x, want %#04x", exc, tt.exc)
+			}
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name string
+		a    float.X80
+		fm   Format
+		mode float.Rounding
+		want string
+		exc  uint32
+	}{
+		{"byte", float.NewFromFloat64(-128.25), FormatByte, float.RoundNearestEven, "80", INEX2},
+		{"byte overflow", float.NewFromFloat64(127.5), FormatByte, float.RoundNearestEven, "7F", OPERR},
+		{"byte RZ", float.NewFromFloat64(127.5), FormatByte, float.RoundToZero, "7F", INEX2},
+		{"word", float.NewFromFloat64(2.5), FormatWord, float.RoundNearestEven, "0002", INEX2},
+		{"word RP", float.NewFromFloat64(2.5), FormatWord, float.RoundUp, "0003", INEX2},
+		{"word overflow", float.NewFromFloat64(-40000), FormatWord, float.RoundNearestEven, "8000", OPERR},
+		{"word SNaN", snan, FormatWord, float.RoundNearestEven, "E000", SNAN | OPERR},
+		{"long", float.Int32ToFloatX80(-2), FormatLong, float.RoundNearestEven, "FFFFFFFE", 0},
+		{"long overflow", float.NewFromFloat64(1e20), FormatLong, float.RoundNearestEven, "7FFFFFFF", OPERR},
+		{"long -inf", float.X80InfNeg, FormatLong, float.RoundNearestEven, "80000000", OPERR},
+		{"long NaN", qnanA, FormatLong, float.RoundNearestEven, "C0000000", OPERR},
+		{"single", oneThird, FormatSingle, float.RoundNearestEven, "3EAAAAAB", INEX2},
+		{"single RZ", oneThird, FormatSingle, float.RoundToZero, "3EAAAAAA", INEX2},
+		{"single overflow", float.NewFromFloat64(1e39), FormatSingle, float.RoundNearestEven, "7F800000", OVFL | INEX2},
+		{"single overflow RZ", float.NewFromFloat64(-1e39), FormatSingle, float.RoundToZero, "FF7FFFFF", OVFL | INEX2},
+		{"single tiny exact", float.NewFromFloat64(0x1p-149), FormatSingle, float.RoundNearestEven, "00000001", UNFL},
+		{"single underflow", float.NewFromFloat64(0x1p-151), FormatSingle, float.RoundNearestEven, "00000000", UNFL | INEX2},
+		{"single SNaN", snan, FormatSingle, float.RoundNearestEven, "7FE00000", SNAN},
+		{"single NaN", qnanB, FormatSingle, float.RoundNearestEven, "FFC00000", 0},
+		{"double", oneThird, FormatDouble, float.RoundNearestEven, "3FD5555555555555", INEX2},
+		{"double overflow", maxX80, FormatDouble, float.RoundUp, "7FF0000000000000", OVFL | INEX2},
+		{"double denormal", minNorm, FormatDouble, float.RoundUp, "0000000000000001", UNFL | INEX2},
+		{"double NaN", qnanA, FormatDouble, float.RoundNearestEven, "7FF8000000000000", 0},
+		{"extended", maxX80, FormatExtended, float.RoundNearestEven, "7FFE0000FFFFFFFFFFFFFFFF", 0},
+		{"extended SNaN", snan, FormatExtended, float.RoundNearestEven, "7FFF0000E000000000000000", SNAN},
+		{"packed", float.NewFromFloat64(-2.5), FormatPackedDynamic, float.RoundNearestEven, "800000020000000000000000", INEX2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exc := Store(tt.a, tt.fm, 0, tt.mode)
+			if want := mem(tt.want); !bytes.Equal(got, want) {
+				t.Errorf("Store() = % X, want % X", got, want)
+			}
+			if exc != tt.exc {
+				t.Errorf("exc = %#04x, want %#04x", exc, tt.exc)
+			}
+		})
+	}
+}
+
+func TestFPU_FMOVEFormats(t *testing.T) {
+	var f FPU
+	f.FPCR = PrecisionSingle
+	f.FMOVEFrom(mem("3FD5555555555555"), FormatDouble, 0)
+	if want := float.NewFromBits(0x3FFD, 0xAAAAAB0000000000); f.FP[0] != want {
+		t.Errorf("FP0 = %s, want %s", f.FP[0].Internal(), want.Internal())
+	}
+	if want := uint32(INEX2 | AccruedINEX); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+
+	f.FPCR, f.FPSR = 0, 0
+	f.FMOVEFrom(mem("FF"), FormatByte, 1)
+	if f.FP[1] != float.X80MinusOne || f.FPSR != CCN {
+		t.Errorf("FP1 = %s, FPSR = %#08x", f.FP[1].Internal(), f.FPSR)
+	}
+
+	f.FPSR = CCN
+	f.FP[2] = float.NewFromFloat64(300)
+	if b, ok := f.FMOVETo(2, FormatByte, 0); !ok || !bytes.Equal(b, mem("7F")) {
+		t.Errorf("FMOVETo() = % X, %v", b, ok)
+	}
+	if want := uint32(CCN | OPERR | AccruedIOP); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+
+	f.FPCR = OPERR
+	if _, ok := f.FMOVETo(2, FormatWord, 0); !ok {
+		t.Error("FMOVETo(FormatWord) not written")
+	}
+	if _, ok := f.FMOVETo(2, FormatByte, 0); ok {
+		t.Error("FMOVETo(FormatByte) written with OPERR enabled")
+	}
+	if v, ok := f.PendingException(); !ok || v != VectorOPERR {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorOPERR)
+	}
+}
@@ -0,0 +1,580 @@
+// Package m68881 models the Motorola MC68881/MC68882 floating-point
+// coprocessor on top of the extended double-precision arithmetic of package
+// float.
+//
+// An FPU holds the programmer-visible registers and executes the arithmetic,
+// transcendental, move and compare instructions with the condition-code and
+// exception semantics of the chip.  Decoding of instructions and effective
+// addresses is left to the emulator, which passes source operands as X80
+// values and destination registers by number:
+//
+//	var fpu m68881.FPU
+//	fpu.Reset()
+//	fpu.FMOVE(float.X80Pi, 0)   // FMOVE.X #pi,FP0
+//	fpu.FSIN(fpu.FP[0], 1)      // FSIN.X FP0,FP1
+//	if vector, ok := fpu.PendingException(); ok {
+//	    // take the exception through `vector'
+//	}
+package m68881
+
+import "github.com/jenska/float"
+
+// FPCR exception enable byte and FPSR exception status byte.
+const (
+	BSUN  = 0x8000 // branch/set on unordered
+	SNAN  = 0x4000 // signaling not-a-number
+	OPERR = 0x2000 // operand error
+	OVFL  = 0x1000 // overflow
+	UNFL  = 0x0800 // underflow
+	DZ    = 0x0400 // divide by zero
+	INEX2 = 0x0200 // inexact operation
+	INEX1 = 0x0100 // inexact decimal input
+)
+
+// FPCR mode control byte.
+const (
+	PrecisionMask     = 0x00C0
+	PrecisionExtended = 0x0000
+	PrecisionSingle   = 0x0040
+	PrecisionDouble   = 0x0080
+
+	RoundingMask = 0x0030
+	RoundNearest = 0x0000 // RN
+	RoundZero    = 0x0010 // RZ
+	RoundMinus   = 0x0020 // RM
+	RoundPlus    = 0x0030 // RP
+)
+
+// FPSR condition code byte, quotient byte and accrued exception byte.
+const (
+	CCN    = float.M68881N   // negative
+	CCZ    = float.M68881Z   // zero
+	CCI    = float.M68881I   // infinity
+	CCNaN  = float.M68881NaN // not-a-number or unordered
+	CCMask = 0x0F000000
+
+	QuotientSign = 0x00800000
+	QuotientMask = 0x00FF0000
+
+	ExceptionMask = 0x0000FF00
+
+	AccruedIOP  = 0x0080 // invalid operation
+	AccruedOVFL = 0x0040 // overflow
+	AccruedUNFL = 0x0020 // underflow
+	AccruedDZ   = 0x0010 // divide by zero
+	AccruedINEX = 0x0008 // inexact
+	AccruedMask = 0x00F8
+)
+
+// Exception vector numbers, in the order of their priority.
+const (
+	VectorBSUN  = 48
+	VectorSNAN  = 54
+	VectorOPERR = 52
+	VectorOVFL  = 53
+	VectorUNFL  = 51
+	VectorDZ    = 50
+	VectorINEX  = 49
+)
+
+// DefaultNaN is the NaN the FPU delivers for invalid operations.
+var DefaultNaN = float.NewFromBits(0x7FFF, 0xFFFFFFFFFFFFFFFF)
+
+// FPU is the programmer's model of a 68881 or 68882.  The zero value has all
+// registers cleared; Reset puts it into the state after a hardware reset.
+type FPU struct {
+	FP    [8]float.X80 // data registers FP0-FP7
+	FPCR  uint32       // exception enable and mode control bytes
+	FPSR  uint32       // condition code, quotient, exception and accrued bytes
+	FPIAR uint32       // address of the last floating-point instruction
+
+	// Exceptional is the operand of the last enabled exception, as saved in
+	// a busy state frame: the intermediate result with the exponent wrapped
+	// by 0x6000 for OVFL and UNFL, and the source operand otherwise.
+	Exceptional float.X80
+
+	// Model selects the state frames of FSAVE and FRESTORE.
+	Model Model
+
+	active bool // an instruction was executed since the last reset
+}
+
+// Reset sets the data registers to NaNs and clears the control registers, as
+// a hardware reset does.  The model is kept.
+func (f *FPU) Reset() {
+	for i := range f.FP {
+		f.FP[i] = DefaultNaN
+	}
+	f.FPCR, f.FPSR, f.FPIAR = 0, 0, 0
+	f.Exceptional = float.X80Zero
+	f.active = false
+}
+
+var roundingModes = [4]float.Rounding{
+	float.RoundNearestEven, float.RoundToZero, float.RoundDown, float.RoundUp,
+}
+
+// RoundingMode returns the rounding mode selected by FPCR.
+func (f *FPU) RoundingMode() float.Rounding {
+	return roundingModes[f.FPCR&RoundingMask>>4]
+}
+
+// RoundingPrecision returns the rounding precision selected by FPCR: 32, 64
+// or 80 bits.  The undefined encoding selects extended precision.
+func (f *FPU) RoundingPrecision() int {
+	switch f.FPCR & PrecisionMask {
+	case PrecisionSingle:
+		return 32
+	case PrecisionDouble:
+		return 64
+	}
+	return 80
+}
+
+// PendingException returns the vector number of the highest-priority
+// exception that is set in the exception status byte and enabled in FPCR.
+func (f *FPU) PendingException() (vector int, ok bool) {
+	exc := f.FPSR & f.FPCR & ExceptionMask
+	switch {
+	case exc&BSUN != 0:
+		return VectorBSUN, true
+	case exc&SNAN != 0:
+		return VectorSNAN, true
+	case exc&OPERR != 0:
+		return VectorOPERR, true
+	case exc&OVFL != 0:
+		return VectorOVFL, true
+	case exc&UNFL != 0:
+		return VectorUNFL, true
+	case exc&DZ != 0:
+		return VectorDZ, true
+	case exc&(INEX2|INEX1) != 0:
+		return VectorINEX, true
+	}
+	return 0, false
+}
+
+// Returns the arithmetic environment selected by FPCR.  Tininess is detected
+// before rounding, and overflow and underflow are trapped so that tiny exact
+// results are detected and the wrapped intermediate result is available.
+func (f *FPU) env() float.Env {
+	return float.Env{
+		RoundingMode:      f.RoundingMode(),
+		RoundingPrecision: f.RoundingPrecision(),
+		DetectTininess:    float.TininessBeforeRounding,
+		TrapEnable:        float.ExceptionOverflow | float.ExceptionUnderflow,
+	}
+}
+
+// Reports whether the sign bit of `a' is set.
+func negative(a float.X80) bool {
+	high, _ := a.Bits()
+	return high&0x8000 != 0
+}
+
+// Returns the NaN `a' with the quiet bit set.
+func quiet(a float.X80) float.X80 {
+	high, low := a.Bits()
+	return float.NewFromBits(high, low|0x4000000000000000)
+}
+
+// Executes `op' in the FPCR environment and returns the default result, the
+// intermediate result with a wrapped exponent and the exception status bits.
+// If one of `operands' is a NaN, `op' is not called and the first NaN
+// operand, quieted, is the result.
+func (f *FPU) execute(op func(e *float.Env) float.X80, operands ...float.X80) (z, wrapped float.X80, exc uint32) {
+	for _, a := range operands {
+		if a.IsNaN() {
+			for _, b := range operands {
+				if b.IsSignalingNaN() {
+					exc = SNAN
+				}
+			}
+			return quiet(a), quiet(a), exc
+		}
+	}
+	e := f.env()
+	z = op(&e)
+	flags := e.Exception
+	wrapped = z
+	if flags&(float.ExceptionOverflow|float.ExceptionUnderflow) != 0 {
+		e = f.env()
+		e.TrapEnable = 0
+		z = op(&e)
+		flags |= e.Exception
+	}
+	if z.IsNaN() {
+		z, wrapped = DefaultNaN, DefaultNaN
+	}
+	return z, wrapped, exceptions(flags)
+}
+
+// Returns the exception status bits for the exception flags `flags'.
+func exceptions(flags float.Flags) uint32 {
+	var exc uint32
+	if flags&float.ExceptionInvalid != 0 {
+		exc |= OPERR
+	}
+	if flags&float.ExceptionDivbyzero != 0 {
+		exc |= DZ
+	}
+	if flags&float.ExceptionOverflow != 0 {
+		exc |= OVFL
+	}
+	if flags&float.ExceptionUnderflow != 0 {
+		exc |= UNFL
+	}
+	if flags&float.ExceptionInexact != 0 {
+		exc |= INEX2
+	}
+	return exc
+}
+
+// Sets the exception status byte to `exc' and accrues it, then saves the
+// exceptional operand of an enabled exception.  Reports whether the
+// destination is written, which enabled SNAN, OPERR and DZ exceptions
+// prevent.
+func (f *FPU) complete(src, wrapped float.X80, exc uint32) bool {
+	aexc := f.FPSR & AccruedMask
+	if exc&(BSUN|SNAN|OPERR) != 0 {
+		aexc |= AccruedIOP
+	}
+	if exc&OVFL != 0 {
+		aexc |= AccruedOVFL
+	}
+	if exc&UNFL != 0 && exc&INEX2 != 0 {
+		aexc |= AccruedUNFL
+	}
+	if exc&DZ != 0 {
+		aexc |= AccruedDZ
+	}
+	if exc&(INEX1|INEX2|OVFL) != 0 {
+		aexc |= AccruedINEX
+	}
+	f.FPSR = f.FPSR&^(ExceptionMask|AccruedMask) | exc | aexc
+	f.active = true
+	enabled := exc & f.FPCR & ExceptionMask
+	switch {
+	case enabled&(BSUN|SNAN|OPERR|DZ) != 0:
+		f.Exceptional = src
+		return false
+	case enabled&(OVFL|UNFL) != 0:
+		f.Exceptional = wrapped
+	}
+	return true
+}
+
+// Sets the condition code byte to `cc'.
+func (f *FPU) setCC(cc uint32) {
+	f.FPSR = f.FPSR&^CCMask | cc
+}
+
+// Writes `z' to FPn and sets the condition codes for it.
+func (f *FPU) store(dst int, z float.X80) {
+	f.FP[dst] = z
+	f.setCC(z.M68881ConditionCodes())
+}
+
+// Executes a monadic instruction FPn = op(src).
+func (f *FPU) monadic(src float.X80, dst int, op func(e *float.Env, a float.X80) float.X80) {
+	z, wrapped, exc := f.execute(func(e *float.Env) float.X80 { return op(e, src) }, src)
+	if f.complete(src, wrapped, exc) {
+		f.store(dst, z)
+	}
+}
+
+// Executes a dyadic instruction FPn = op(FPn, src).  A NaN in FPn takes
+// precedence over a NaN source operand.
+func (f *FPU) dyadic(src float.X80, dst int, op func(e *float.Env, a, b float.X80) float.X80) {
+	d := f.FP[dst]
+	z, wrapped, exc := f.execute(func(e *float.Env) float.X80 { return op(e, d, src) }, d, src)
+	if f.complete(src, wrapped, exc) {
+		f.store(dst, z)
+	}
+}
+
+// FMOVE moves `src' to FPn, rounded to the selected precision.
+func (f *FPU) FMOVE(src float.X80, dst int) {
+	f.monadic(src, dst, (*float.Env).Round)
+}
+
+// FINT rounds `src' to an integer in the selected rounding mode and then to
+// the selected precision.
+func (f *FPU) FINT(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		return e.Round(extended(e, e.RoundToInt, a))
+	})
+}
+
+// FINTRZ rounds `src' to an integer towards zero.
+func (f *FPU) FINTRZ(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		mode := e.RoundingMode
+		e.RoundingMode = float.RoundToZero
+		a = extended(e, e.RoundToInt, a)
+		e.RoundingMode = mode
+		return e.Round(a)
+	})
+}
+
+// Applies `op' to `a' at extended precision, so that its result is rounded
+// to the selected precision only by the caller.
+func extended(e *float.Env, op func(a float.X80) float.X80, a float.X80) float.X80 {
+	precision := e.RoundingPrecision
+	e.RoundingPrecision = 80
+	z := op(a)
+	e.RoundingPrecision = precision
+	return z
+}
+
+// FABS moves the absolute value of `src' to FPn.
+func (f *FPU) FABS(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		high, low := a.Bits()
+		return e.Round(float.NewFromBits(high&0x7FFF, low))
+	})
+}
+
+// FNEG moves the negated value of `src' to FPn.
+func (f *FPU) FNEG(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		high, low := a.Bits()
+		return e.Round(float.NewFromBits(high^0x8000, low))
+	})
+}
+
+// FSQRT computes the square root of `src'.
+func (f *FPU) FSQRT(src float.X80, dst int) {
+	f.monadic(src, dst, (*float.Env).Sqrt)
+}
+
+// FGETEXP moves the unbiased exponent of `src' to FPn as an integral value.
+// Zeros are moved unchanged; infinities are operand errors.
+func (f *FPU) FGETEXP(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		exp, man, ok := split(e, a)
+		if !ok {
+			return man
+		}
+		return float.Int32ToFloatX80(int32(exp))
+	})
+}
+
+// FGETMAN moves the mantissa of `src' to FPn, as a value in [1, 2) with the
+// sign of `src'.  Zeros are moved unchanged; infinities are operand errors.
+func (f *FPU) FGETMAN(src float.X80, dst int) {
+	f.monadic(src, dst, func(e *float.Env, a float.X80) float.X80 {
+		_, man, _ := split(e, a)
+		return man
+	})
+}
+
+// Splits the finite nonzero value `a' into its unbiased exponent and its
+// mantissa in [1, 2).  For zeros and infinities `ok' is false and the
+// mantissa is the result of both instructions: zeros are returned unchanged,
+// while infinities raise the invalid exception and yield the default NaN.
+func split(e *float.Env, a float.X80) (exp int, man float.X80, ok bool) {
+	high, low := a.Bits()
+	if high&0x7FFF == 0x7FFF {
+		e.Exception |= float.ExceptionInvalid
+		return 0, DefaultNaN, false
+	}
+	if low == 0 {
+		return 0, a, false
+	}
+	exp = int(high&0x7FFF) - 0x3FFF
+	if high&0x7FFF == 0 {
+		exp++
+	}
+	for ; low&0x8000000000000000 == 0; low <<= 1 {
+		exp--
+	}
+	return exp, float.NewFromBits(high&0x8000|0x3FFF, low), true
+}
+
+// FETOX computes e^src.
+func (f *FPU) FETOX(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Exp) }
+
+// FETOXM1 computes e^src - 1.
+func (f *FPU) FETOXM1(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Expm1) }
+
+// FTWOTOX computes 2^src.
+func (f *FPU) FTWOTOX(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Exp2) }
+
+// FTENTOX computes 10^src.
+func (f *FPU) FTENTOX(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Exp10) }
+
+// FLOGN computes the natural logarithm of `src'.
+func (f *FPU) FLOGN(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Ln) }
+
+// FLOGNP1 computes the natural logarithm of src + 1.
+func (f *FPU) FLOGNP1(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Log1p) }
+
+// FLOG10 computes the decimal logarithm of `src'.
+func (f *FPU) FLOG10(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Log10) }
+
+// FLOG2 computes the binary logarithm of `src'.
+func (f *FPU) FLOG2(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Log2) }
+
+// FSIN computes the sine of `src'.
+func (f *FPU) FSIN(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Sin) }
+
+// FCOS computes the cosine of `src'.
+func (f *FPU) FCOS(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Cos) }
+
+// FTAN computes the tangent of `src'.
+func (f *FPU) FTAN(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Tan) }
+
+// FASIN computes the arcsine of `src'.
+func (f *FPU) FASIN(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Asin) }
+
+// FACOS computes the arccosine of `src'.
+func (f *FPU) FACOS(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Acos) }
+
+// FATAN computes the arctangent of `src'.
+func (f *FPU) FATAN(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Atan) }
+
+// FATANH computes the inverse hyperbolic tangent of `src'.
+func (f *FPU) FATANH(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Atanh) }
+
+// FSINH computes the hyperbolic sine of `src'.
+func (f *FPU) FSINH(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Sinh) }
+
+// FCOSH computes the hyperbolic cosine of `src'.
+func (f *FPU) FCOSH(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Cosh) }
+
+// FTANH computes the hyperbolic tangent of `src'.
+func (f *FPU) FTANH(src float.X80, dst int) { f.monadic(src, dst, (*float.Env).Tanh) }
+
+// FSINCOS computes the sine of `src' into FPs and its cosine into FPc.  The
+// condition codes are set for the sine.
+func (f *FPU) FSINCOS(src float.X80, dc, ds int) {
+	c, _, cexc := f.execute(func(e *float.Env) float.X80 { return e.Cos(src) }, src)
+	s, wrapped, sexc := f.execute(func(e *float.Env) float.X80 { return e.Sin(src) }, src)
+	if f.complete(src, wrapped, cexc|sexc) {
+		f.FP[dc] = c
+		f.store(ds, s)
+	}
+}
+
+// FADD adds `src' to FPn.
+func (f *FPU) FADD(src float.X80, dst int) { f.dyadic(src, dst, (*float.Env).Add) }
+
+// FSUB subtracts `src' from FPn.
+func (f *FPU) FSUB(src float.X80, dst int) { f.dyadic(src, dst, (*float.Env).Sub) }
+
+// FMUL multiplies FPn by `src'.
+func (f *FPU) FMUL(src float.X80, dst int) { f.dyadic(src, dst, (*float.Env).Mul) }
+
+// FDIV divides FPn by `src'.
+func (f *FPU) FDIV(src float.X80, dst int) { f.dyadic(src, dst, (*float.Env).Div) }
+
+// FSGLMUL multiplies FPn by `src' and rounds the result to single precision,
+// keeping the extended exponent range.
+func (f *FPU) FSGLMUL(src float.X80, dst int) {
+	f.dyadic(src, dst, func(e *float.Env, a, b float.X80) float.X80 {
+		e.RoundingPrecision = 32
+		return e.Mul(a, b)
+	})
+}
+
+// FSGLDIV divides FPn by `src' and rounds the result to single precision,
+// keeping the extended exponent range.
+func (f *FPU) FSGLDIV(src float.X80, dst int) {
+	f.dyadic(src, dst, func(e *float.Env, a, b float.X80) float.X80 {
+		e.RoundingPrecision = 32
+		return e.Div(a, b)
+	})
+}
+
+// FMOD computes the remainder of FPn divided by `src' with the quotient
+// truncated towards zero, and loads the sign and the seven least significant
+// bits of the quotient into the quotient byte.
+func (f *FPU) FMOD(src float.X80, dst int) {
+	f.remainder(src, dst, (*float.Env).ModQuo)
+}
+
+// FREM computes the IEEE remainder of FPn divided by `src', with the
+// quotient rounded to nearest, and loads the sign and the seven least
+// significant bits of the quotient into the quotient byte.
+func (f *FPU) FREM(src float.X80, dst int) {
+	f.remainder(src, dst, (*float.Env).RemQuo)
+}
+
+// Executes FMOD or FREM.
+func (f *FPU) remainder(src float.X80, dst int, op func(e *float.Env, a, b float.X80) (float.X80, uint64)) {
+	var quotient uint32
+	f.dyadic(src, dst, func(e *float.Env, a, b float.X80) float.X80 {
+		precision := e.RoundingPrecision
+		e.RoundingPrecision = 80
+		z, q := op(e, a, b)
+		e.RoundingPrecision = precision
+		if e.Exception&float.ExceptionInvalid == 0 {
+			quotient = uint32(q&0x7F) << 16
+			if negative(a) != negative(b) {
+				quotient |= QuotientSign
+			}
+		}
+		return e.Round(z)
+	})
+	f.FPSR = f.FPSR&^QuotientMask | quotient
+}
+
+// FSCALE multiplies FPn by two to the power of `src' truncated to an
+// integer.  An infinite `src' is an operand error.
+func (f *FPU) FSCALE(src float.X80, dst int) {
+	f.dyadic(src, dst, func(e *float.Env, a, b float.X80) float.X80 {
+		high, low := b.Bits()
+		exp := int(high&0x7FFF) - 0x3FFF
+		var n int
+		switch {
+		case high&0x7FFF == 0x7FFF:
+			e.Exception |= float.ExceptionInvalid
+			return DefaultNaN
+		case exp >= 16:
+			n = 0x10000
+		case exp >= 0:
+			n = int(low >> (63 - exp))
+		}
+		if high&0x8000 != 0 {
+			n = -n
+		}
+		return e.Scalbn(a, n)
+	})
+}
+
+// FCMP compares FPn with `src' and sets the condition codes for the
+// difference FPn - src.  Equal operands set Z, together with N if the
+// difference is a negative zero (-0 - +0) or both operands are -inf.  Only
+// signaling NaNs raise an exception.
+func (f *FPU) FCMP(src float.X80, dst int) {
+	d := f.FP[dst]
+	e := f.env()
+	o := e.CompareQuiet(d, src)
+	var exc uint32
+	if e.Exception&float.ExceptionInvalid != 0 {
+		exc = SNAN
+	}
+	if !f.complete(src, src, exc) {
+		return
+	}
+	cc := o.M68881()
+	if o == float.Equal {
+		if _, low := d.Bits(); negative(d) && (d.IsInf() || low == 0 && !negative(src)) {
+			cc |= CCN
+		}
+	}
+	f.setCC(cc)
+}
+
+// FTST sets the condition codes for `src'.  Only signaling NaNs raise an
+// exception.
+func (f *FPU) FTST(src float.X80) {
+	var exc uint32
+	if src.IsSignalingNaN() {
+		exc = SNAN
+	}
+	if f.complete(src, src, exc) {
+		f.setCC(src.M68881ConditionCodes())
+	}
+}
@@ -0,0 +1,232 @@
+package m68881
+
+import (
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+var (
+	one      = float.X80One
+	two      = float.Int32ToFloatX80(2)
+	three    = float.Int32ToFloatX80(3)
+	seven    = float.Int32ToFloatX80(7)
+	half     = float.NewFromBits(0x3FFE, 0x8000000000000000)
+	negZero  = float.NewFromBits(0x8000, 0)
+	maxX80   = float.NewFromBits(0x7FFE, 0xFFFFFFFFFFFFFFFF)
+	minNorm  = float.NewFromBits(0x0001, 0x8000000000000000)
+	snan     = float.NewFromBits(0x7FFF, 0xA000000000000000)
+	qnanA    = float.NewFromBits(0x7FFF, 0xC000000000000001)
+	qnanB    = float.NewFromBits(0xFFFF, 0xC000000000000002)
+	oneThird = float.X80One.Div(float.Int32ToFloatX80(3))
+)
+
+func TestFPU_Instructions(t *testing.T) {
+	tests := []struct {
+		name string
+		fpcr uint32
+		dst  float.X80 // initial FP0
+		exec func(f *FPU)
+		want float.X80 // FP0 afterwards
+		fpsr uint32
+	}{
+		{"FADD", 0, one, func(f *FPU) { f.FADD(two, 0) }, three, 0},
+		{"FSUB zero", 0, one, func(f *FPU) { f.FSUB(one, 0) }, float.X80Zero, CCZ},
+		{"FMUL", 0, two, func(f *FPU) { f.FMUL(three, 0) }, float.Int32ToFloatX80(6), 0},
+		{"FDIV inexact", 0, one, func(f *FPU) { f.FDIV(three, 0) }, oneThird, INEX2 | AccruedINEX},
+		{"FDIV by zero", 0, one, func(f *FPU) { f.FDIV(float.X80Zero, 0) }, float.X80InfPos, CCI | DZ | AccruedDZ},
+		{"FDIV by zero enabled", DZ, one, func(f *FPU) { f.FDIV(float.X80Zero, 0) }, one, DZ | AccruedDZ},
+		{"FMUL overflow", 0, maxX80, func(f *FPU) { f.FMUL(two, 0) }, float.X80InfPos,
+			CCI | OVFL | INEX2 | AccruedOVFL | AccruedINEX},
+		{"FMUL overflow RZ", RoundZero, maxX80, func(f *FPU) { f.FMUL(two, 0) }, maxX80,
+			OVFL | INEX2 | AccruedOVFL | AccruedINEX},
+		{"FMUL exact underflow", 0, minNorm, func(f *FPU) { f.FMUL(half, 0) },
+			float.NewFromBits(0, 0x4000000000000000), UNFL},
+		{"FMUL inexact underflow", 0, float.NewFromBits(0x0001, 0x8000000000000001), func(f *FPU) { f.FMUL(half, 0) },
+			float.NewFromBits(0, 0x4000000000000000), UNFL | INEX2 | AccruedUNFL | AccruedINEX},
+		{"FSQRT negative", 0, one, func(f *FPU) { f.FSQRT(float.X80MinusOne, 0) }, DefaultNaN,
+			CCNaN | OPERR | AccruedIOP},
+		{"FSQRT -0", 0, one, func(f *FPU) { f.FSQRT(negZero, 0) }, negZero, CCN | CCZ},
+		{"FADD signaling NaN", 0, one, func(f *FPU) { f.FADD(snan, 0) },
+			float.NewFromBits(0x7FFF, 0xE000000000000000), CCNaN | SNAN | AccruedIOP},
+		{"FADD signaling NaN enabled", SNAN, one, func(f *FPU) { f.FADD(snan, 0) }, one, SNAN | AccruedIOP},
+		{"FADD destination NaN first", 0, qnanA, func(f *FPU) { f.FADD(qnanB, 0) }, qnanA, CCNaN},
+		{"FMOVE single", PrecisionSingle, one, func(f *FPU) { f.FMOVE(oneThird, 0) },
+			float.NewFromBits(0x3FFD, 0xAAAAAB0000000000), INEX2 | AccruedINEX},
+		{"FMOVE denormal", 0, one, func(f *FPU) { f.FMOVE(float.NewFromBits(0, 1), 0) }, float.NewFromBits(0, 1), 0},
+		{"FABS", 0, one, func(f *FPU) { f.FABS(float.X80InfNeg, 0) }, float.X80InfPos, CCI},
+		{"FNEG", 0, one, func(f *FPU) { f.FNEG(float.X80Zero, 0) }, negZero, CCN | CCZ},
+		{"FINT RN", 0, one, func(f *FPU) { f.FINT(float.NewFromFloat64(2.5), 0) }, two, INEX2 | AccruedINEX},
+		{"FINT RP", RoundPlus, one, func(f *FPU) { f.FINT(float.NewFromFloat64(2.25), 0) }, three, INEX2 | AccruedINEX},
+		{"FINTRZ", RoundPlus, one, func(f *FPU) { f.FINTRZ(float.NewFromFloat64(-2.75), 0) },
+			float.Int32ToFloatX80(-2), CCN | INEX2 | AccruedINEX},
+		{"FGETEXP", 0, one, func(f *FPU) { f.FGETEXP(float.Int32ToFloatX80(12), 0) }, three, 0},
+		{"FGETEXP denormal", 0, one, func(f *FPU) { f.FGETEXP(float.NewFromBits(0, 1), 0) },
+			float.Int32ToFloatX80(-16445), CCN},
+		{"FGETEXP inf", 0, one, func(f *FPU) { f.FGETEXP(float.X80InfNeg, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FGETMAN", 0, one, func(f *FPU) { f.FGETMAN(float.Int32ToFloatX80(-12), 0) },
+			float.NewFromFloat64(-1.5), CCN},
+		{"FGETMAN zero", 0, one, func(f *FPU) { f.FGETMAN(negZero, 0) }, negZero, CCN | CCZ},
+		{"FSCALE", 0, one, func(f *FPU) { f.FSCALE(float.NewFromFloat64(3.7), 0) }, float.Int32ToFloatX80(8), 0},
+		{"FSCALE negative", 0, one, func(f *FPU) { f.FSCALE(float.NewFromFloat64(-1.5), 0) }, half, 0},
+		{"FSCALE inf", 0, one, func(f *FPU) { f.FSCALE(float.X80InfPos, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FMOD", 0, seven, func(f *FPU) { f.FMOD(two, 0) }, one, 0x00030000},
+		{"FMOD negative", 0, float.Int32ToFloatX80(-7), func(f *FPU) { f.FMOD(two, 0) },
+			float.X80MinusOne, CCN | QuotientSign | 0x00030000},
+		{"FREM", 0, seven, func(f *FPU) { f.FREM(two, 0) }, float.X80MinusOne, CCN | 0x00040000},
+		{"FREM quotient bits", 0, float.Int32ToFloatX80(1000), func(f *FPU) { f.FREM(three, 0) }, one, 0x004D0000},
+		{"FREM by zero", 0, seven, func(f *FPU) { f.FREM(float.X80Zero, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FSGLMUL", 0, oneThird, func(f *FPU) { f.FSGLMUL(one, 0) },
+			float.NewFromBits(0x3FFD, 0xAAAAAB0000000000), INEX2 | AccruedINEX},
+		{"FSGLDIV", 0, one, func(f *FPU) { f.FSGLDIV(three, 0) },
+			float.NewFromBits(0x3FFD, 0xAAAAAB0000000000), INEX2 | AccruedINEX},
+		{"FLOGN zero", 0, one, func(f *FPU) { f.FLOGN(float.X80Zero, 0) }, float.X80InfNeg, CCN | CCI | DZ | AccruedDZ},
+		{"FLOGN negative", 0, one, func(f *FPU) { f.FLOGN(float.X80MinusOne, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FATANH one", 0, one, func(f *FPU) { f.FATANH(one, 0) }, float.X80InfPos, CCI | DZ | AccruedDZ},
+		{"FASIN two", 0, one, func(f *FPU) { f.FASIN(two, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FSIN zero", 0, one, func(f *FPU) { f.FSIN(negZero, 0) }, negZero, CCN | CCZ},
+		{"FSIN inf", 0, one, func(f *FPU) { f.FSIN(float.X80InfPos, 0) }, DefaultNaN, CCNaN | OPERR | AccruedIOP},
+		{"FCOS zero", 0, two, func(f *FPU) { f.FCOS(float.X80Zero, 0) }, one, 0},
+		{"FETOX", 0, one, func(f *FPU) { f.FETOX(one, 0) }, float.NewFromBits(0x4000, 0xADF85458A2BB4A9B), INEX2 | AccruedINEX},
+		{"FTWOTOX exact", 0, one, func(f *FPU) { f.FTWOTOX(float.Int32ToFloatX80(-3), 0) },
+			float.NewFromBits(0x3FFC, 0x8000000000000000), 0},
+		{"FTENTOX exact", 0, one, func(f *FPU) { f.FTENTOX(three, 0) }, float.Int32ToFloatX80(1000), 0},
+		{"FLOG2 exact", 0, one, func(f *FPU) { f.FLOG2(float.Int32ToFloatX80(1024), 0) }, float.Int32ToFloatX80(10), 0},
+		{"FLOG10 exact", 0, one, func(f *FPU) { f.FLOG10(float.Int32ToFloatX80(100), 0) }, two, 0},
+		{"FETOX overflow", 0, one, func(f *FPU) { f.FETOX(float.Int32ToFloatX80(20000), 0) }, float.X80InfPos,
+			CCI | OVFL | INEX2 | AccruedOVFL | AccruedINEX},
+		{"FTANH inf", 0, one, func(f *FPU) { f.FTANH(float.X80InfNeg, 0) }, float.X80MinusOne, CCN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.Reset()
+			f.FPCR = tt.fpcr
+			f.FP[0] = tt.dst
+			tt.exec(&f)
+			if f.FP[0] != tt.want {
+				t.Errorf("FP0 = %s, want %s", f.FP[0].Internal(), tt.want.Internal())
+			}
+			if f.FPSR != tt.fpsr {
+				t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, tt.fpsr)
+			}
+		})
+	}
+}
+
+func TestFPU_FCMP(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst, src float.X80
+		want     uint32
+	}{
+		{"less", one, two, CCN},
+		{"greater", two, one, 0},
+		{"equal", two, two, CCZ},
+		{"equal negative", float.X80MinusOne, float.X80MinusOne, CCZ},
+		{"-0 - +0", negZero, float.X80Zero, CCN | CCZ},
+		{"+0 - -0", float.X80Zero, negZero, CCZ},
+		{"-inf - -inf", float.X80InfNeg, float.X80InfNeg, CCN | CCZ},
+		{"+inf - +inf", float.X80InfPos, float.X80InfPos, CCZ},
+		{"unordered", qnanA, one, CCNaN},
+		{"signaling", one, snan, CCNaN | SNAN | AccruedIOP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FP[0] = tt.dst
+			f.FCMP(tt.src, 0)
+			if f.FPSR != tt.want {
+				t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, tt.want)
+			}
+			if f.FP[0] != tt.dst {
+				t.Errorf("FCMP modified FP0")
+			}
+		})
+	}
+}
+
+func TestFPU_FTST(t *testing.T) {
+	var f FPU
+	f.FTST(float.X80InfNeg)
+	if f.FPSR != CCN|CCI {
+		t.Errorf("FTST(-inf): FPSR = %#08x", f.FPSR)
+	}
+	f.FTST(negZero)
+	if f.FPSR != CCN|CCZ {
+		t.Errorf("FTST(-0): FPSR = %#08x", f.FPSR)
+	}
+}
+
+func TestFPU_AccruedExceptions(t *testing.T) {
+	var f FPU
+	f.FP[0], f.FP[1] = one, one
+	f.FDIV(three, 0)
+	f.FDIV(float.X80Zero, 1)
+	f.FADD(one, 2)
+	if got, want := f.FPSR&(ExceptionMask|AccruedMask), uint32(AccruedINEX|AccruedDZ); got != want {
+		t.Errorf("FPSR exception bytes = %#04x, want %#04x", got, want)
+	}
+}
+
+func TestFPU_PendingException(t *testing.T) {
+	var f FPU
+	if _, ok := f.PendingException(); ok {
+		t.Errorf("PendingException() reported an exception after reset")
+	}
+	f.FPCR = OVFL | INEX2
+	f.FP[0] = maxX80
+	f.FMUL(two, 0)
+	if v, ok := f.PendingException(); !ok || v != VectorOVFL {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorOVFL)
+	}
+	if f.FP[0] != float.X80InfPos {
+		t.Errorf("FP0 = %s, want the default result", f.FP[0].Internal())
+	}
+	if want := float.NewFromBits(0x1FFF, 0xFFFFFFFFFFFFFFFF); f.Exceptional != want {
+		t.Errorf("Exceptional = %s, want %s", f.Exceptional.Internal(), want.Internal())
+	}
+
+	// Results beyond the reach of the exponent wrap keep the default result
+	// as the exceptional operand rather than wrapping into the sign bit.
+	f.FPCR = OVFL | UNFL
+	f.FPSR = 0
+	f.FP[0] = maxX80
+	f.FSCALE(float.Int32ToFloatX80(1<<16), 0)
+	if v, ok := f.PendingException(); !ok || v != VectorOVFL {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorOVFL)
+	}
+	if f.FP[0] != float.X80InfPos || f.Exceptional != float.X80InfPos {
+		t.Errorf("FSCALE overflow FP0, Exceptional = %s, %s, want +inf", f.FP[0].Internal(), f.Exceptional.Internal())
+	}
+	f.FPSR = 0
+	f.FP[0] = float.NewFromBits(0x8001, 0x8000000000000000)
+	f.FSCALE(float.Int32ToFloatX80(-1<<16), 0)
+	if v, ok := f.PendingException(); !ok || v != VectorUNFL {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorUNFL)
+	}
+	if f.FP[0] != negZero || f.Exceptional != negZero {
+		t.Errorf("FSCALE underflow FP0, Exceptional = %s, %s, want -0", f.FP[0].Internal(), f.Exceptional.Internal())
+	}
+
+	f.FPCR = DZ
+	f.FP[0] = one
+	f.FDIV(float.X80Zero, 0)
+	if v, ok := f.PendingException(); !ok || v != VectorDZ {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorDZ)
+	}
+	if f.Exceptional != float.X80Zero {
+		t.Errorf("Exceptional = %s, want the source operand", f.Exceptional.Internal())
+	}
+}
+
+func TestFPU_FSINCOS(t *testing.T) {
+	var f FPU
+	f.FSINCOS(float.X80Zero, 1, 2)
+	if f.FP[1] != one || f.FP[2] != float.X80Zero {
+		t.Errorf("FSINCOS(0) = %s, %s", f.FP[1].Internal(), f.FP[2].Internal())
+	}
+	if f.FPSR != CCZ {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, CCZ)
+	}
+}
@@ -0,0 +1,153 @@
+package m68881
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/jenska/float"
+)
+
+// Model identifies the coprocessor whose state frames FSAVE produces and
+// FRESTORE accepts.
+type Model int
+
+const (
+	MC68881 Model = iota
+	MC68882
+)
+
+// State frame format word: version number and frame sizes, which do not
+// include the format word itself.
+const (
+	FrameVersion   = 0x1F
+	FrameIdle68881 = 0x18
+	FrameIdle68882 = 0x38
+	FrameBusy68881 = 0xB4
+	FrameBusy68882 = 0xD4
+
+	// BIUNoException is set in the BIU flags of a frame if no exception is
+	// pending.  A trap handler sets it before FRESTORE to dismiss the
+	// exception.
+	BIUNoException = 0x08000000
+)
+
+// ErrFrameFormat is returned for state frames with an unknown version number
+// or a size that does not match the model, for which FRESTORE takes a format
+// error exception.
+var ErrFrameFormat = errors.New("m68881: invalid state frame format")
+
+// Frame is an FSAVE/FRESTORE state frame.  A frame with version number 0 is
+// the null frame of an FPU in its reset state.  Idle and busy frames share
+// their layout: the command/condition register word, internal registers,
+// the exceptional operand in memory extended format, the operand register
+// and the BIU flags.
+type Frame struct {
+	Version     uint8
+	Size        uint8
+	CIR         uint16    // command/condition register
+	Internal    []byte    // Size - 24 bytes of undocumented internal registers
+	Exceptional float.X80 // exceptional operand
+	OPR         uint32    // operand register
+	BIU         uint32    // BIU flags
+}
+
+// Returns the sizes of the idle and busy frames of the model `m'.
+func (m Model) frameSizes() (idle, busy uint8) {
+	if m == MC68882 {
+		return FrameIdle68882, FrameBusy68882
+	}
+	return FrameIdle68881, FrameBusy68881
+}
+
+// MarshalBinary returns the frame as FSAVE writes it to memory.
+func (fr Frame) MarshalBinary() ([]byte, error) {
+	if fr.Version == 0 {
+		return make([]byte, 4), nil
+	}
+	if int(fr.Size) != 24+len(fr.Internal) {
+		return nil, ErrFrameFormat
+	}
+	b := make([]byte, 4+int(fr.Size))
+	b[0], b[1] = fr.Version, fr.Size
+	binary.BigEndian.PutUint16(b[4:], fr.CIR)
+	n := 8 + copy(b[8:], fr.Internal)
+	x := fr.Exceptional.Bytes(binary.BigEndian)
+	copy(b[n:], x[:2])
+	copy(b[n+4:], x[2:])
+	binary.BigEndian.PutUint32(b[n+12:], fr.OPR)
+	binary.BigEndian.PutUint32(b[n+16:], fr.BIU)
+	return b, nil
+}
+
+// UnmarshalBinary decodes a frame written by FSAVE.  The length of `b' must
+// match the frame size in its format word.
+func (fr *Frame) UnmarshalBinary(b []byte) error {
+	if len(b) < 4 {
+		return ErrFrameFormat
+	}
+	if b[0] == 0 {
+		if len(b) != 4 {
+			return ErrFrameFormat
+		}
+		*fr = Frame{}
+		return nil
+	}
+	size := int(b[1])
+	if size < 24 || len(b) != 4+size {
+		return ErrFrameFormat
+	}
+	n := 8 + size - 24
+	x := make([]byte, 10)
+	copy(x, b[n:n+2])
+	copy(x[2:], b[n+4:n+12])
+	*fr = Frame{
+		Version:     b[0],
+		Size:        b[1],
+		CIR:         binary.BigEndian.Uint16(b[4:]),
+		Internal:    append([]byte(nil), b[8:n]...),
+		Exceptional: float.NewFromBytes(x, binary.BigEndian),
+		OPR:         binary.BigEndian.Uint32(b[n+12:]),
+		BIU:         binary.BigEndian.Uint32(b[n+16:]),
+	}
+	return nil
+}
+
+// FSAVE returns the state frame of the FPU: the null frame after a reset and
+// an idle frame of the model in FPU.Model once an instruction has been
+// executed.  Instructions execute atomically, so there are no busy frames to
+// save.  The BIU flags report whether an exception is pending.
+func (f *FPU) FSAVE() Frame {
+	if !f.active {
+		return Frame{}
+	}
+	idle, _ := f.Model.frameSizes()
+	fr := Frame{
+		Version:     FrameVersion,
+		Size:        idle,
+		Internal:    make([]byte, idle-24),
+		Exceptional: f.Exceptional,
+	}
+	if _, pending := f.PendingException(); !pending {
+		fr.BIU = BIUNoException
+	}
+	return fr
+}
+
+// FRESTORE restores the state frame `fr'.  The null frame resets the FPU.
+// Idle and busy frames of the model in FPU.Model restore the exceptional
+// operand; as instructions execute atomically, the instruction interrupted
+// in a busy frame is not resumed.  Other frames leave the FPU unchanged and
+// return ErrFrameFormat.
+func (f *FPU) FRESTORE(fr Frame) error {
+	if fr.Version == 0 {
+		f.Reset()
+		return nil
+	}
+	idle, busy := f.Model.frameSizes()
+	if fr.Version != FrameVersion || fr.Size != idle && fr.Size != busy || len(fr.Internal) != int(fr.Size)-24 {
+		return ErrFrameFormat
+	}
+	f.Exceptional = fr.Exceptional
+	f.active = true
+	return nil
+}
@@ -0,0 +1,100 @@
+package m68881
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func TestFrame_Binary(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame Frame
+		want  []byte
+	}{
+		{"null", Frame{}, []byte{0, 0, 0, 0}},
+		{"idle 68881", Frame{Version: FrameVersion, Size: FrameIdle68881, CIR: 0x0802, Internal: []byte{},
+			Exceptional: float.X80Pi, OPR: 0x12345678, BIU: BIUNoException},
+			[]byte{
+				0x1F, 0x18, 0, 0, 0x08, 0x02, 0, 0,
+				0x40, 0x00, 0, 0, 0xC9, 0x0F, 0xDA, 0xA2, 0x21, 0x68, 0xC2, 0x35,
+				0x12, 0x34, 0x56, 0x78, 0x08, 0, 0, 0,
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.frame.MarshalBinary()
+			if err != nil || !bytes.Equal(b, tt.want) {
+				t.Fatalf("MarshalBinary() = % X, %v, want % X", b, err, tt.want)
+			}
+			var fr Frame
+			if err := fr.UnmarshalBinary(b); err != nil {
+				t.Fatalf("UnmarshalBinary() = %v", err)
+			}
+			if b2, _ := fr.MarshalBinary(); !bytes.Equal(b2, b) {
+				t.Errorf("round trip = % X, want % X", b2, b)
+			}
+		})
+	}
+
+	busy := make([]byte, 4+FrameBusy68882)
+	busy[0], busy[1], busy[0x18] = FrameVersion, FrameBusy68882, 0xAA
+	var fr Frame
+	if err := fr.UnmarshalBinary(busy); err != nil || len(fr.Internal) != FrameBusy68882-24 || fr.Internal[0x10] != 0xAA {
+		t.Errorf("UnmarshalBinary(busy) = %+v, %v", fr, err)
+	}
+	for _, b := range [][]byte{{0x1F}, {0, 0, 0, 0, 0}, {0x1F, 0x18, 0, 0}, {0x1F, 0x04, 0, 0, 0, 0, 0, 0}} {
+		if err := fr.UnmarshalBinary(b); !errors.Is(err, ErrFrameFormat) {
+			t.Errorf("UnmarshalBinary(% X) = %v, want ErrFrameFormat", b, err)
+		}
+	}
+	if _, err := (Frame{Version: FrameVersion, Size: FrameIdle68882}).MarshalBinary(); !errors.Is(err, ErrFrameFormat) {
+		t.Errorf("MarshalBinary() of short frame = %v, want ErrFrameFormat", err)
+	}
+}
+
+func TestFPU_FSAVE(t *testing.T) {
+	var f FPU
+	f.Reset()
+	f.Model = MC68882
+	if fr := f.FSAVE(); fr.Version != 0 {
+		t.Errorf("FSAVE() after reset = %+v, want null frame", fr)
+	}
+
+	f.FPCR = DZ
+	f.FP[0] = one
+	f.FDIV(float.X80Zero, 0)
+	fr := f.FSAVE()
+	if fr.Version != FrameVersion || fr.Size != FrameIdle68882 || len(fr.Internal) != 32 {
+		t.Fatalf("FSAVE() = %+v, want 68882 idle frame", fr)
+	}
+	if fr.BIU&BIUNoException != 0 || fr.Exceptional != float.X80Zero {
+		t.Errorf("FSAVE() = %+v, want pending exception with operand 0", fr)
+	}
+
+	var g FPU
+	g.Model = MC68882
+	fr.BIU |= BIUNoException
+	fr.Exceptional = one
+	if err := g.FRESTORE(fr); err != nil {
+		t.Fatalf("FRESTORE() = %v", err)
+	}
+	if g.Exceptional != one || g.FSAVE().Size != FrameIdle68882 {
+		t.Errorf("FRESTORE() did not restore the idle state")
+	}
+	g.Model = MC68881
+	if err := g.FRESTORE(fr); !errors.Is(err, ErrFrameFormat) {
+		t.Errorf("FRESTORE() of 68882 frame on 68881 = %v, want ErrFrameFormat", err)
+	}
+	fr.Version = 0x21
+	g.Model = MC68882
+	if err := g.FRESTORE(fr); !errors.Is(err, ErrFrameFormat) {
+		t.Errorf("FRESTORE() of version 0x21 = %v, want ErrFrameFormat", err)
+	}
+	g.FP[0], g.FPCR = one, RoundZero
+	if err := g.FRESTORE(Frame{}); err != nil || g.FPCR != 0 || g.FP[0] != DefaultNaN || g.FSAVE().Version != 0 {
+		t.Errorf("FRESTORE() of null frame did not reset the FPU")
+	}
+}
@@ -0,0 +1,205 @@
+package m68881
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/jenska/float"
+)
+
+// PackedDecimal is a packed decimal real in memory order.  The first long
+// word holds the mantissa sign SM, the exponent sign SE, the two YY bits, the
+// three exponent digits, the fourth exponent digit EXP3 written on overflow
+// of the exponent, and the integer digit of the mantissa.  The remaining two
+// long words hold the sixteen fraction digits:
+//
+//	SM SE YY EXP2 EXP1 EXP0 EXP3 0000 0000 D16 | D15 ... D8 | D7 ... D0
+//
+// An exponent of 0xFFF with SE and YY set encodes an infinity if the mantissa
+// is zero and a NaN with the mantissa as its significand otherwise.
+type PackedDecimal [12]byte
+
+// Returns the math/big rounding mode equivalent to `mode'.
+func bigRounding(mode float.Rounding) big.RoundingMode {
+	switch mode {
+	case float.RoundToZero:
+		return big.ToZero
+	case float.RoundDown:
+		return big.ToNegativeInf
+	case float.RoundUp:
+		return big.ToPositiveInf
+	}
+	return big.ToNearestEven
+}
+
+// Returns 10^n as a rational number.
+func pow10(n int) *big.Rat {
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n)), nil))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
+
+// X80 converts `p' to extended double precision, rounding in the rounding
+// mode `mode', and reports whether the conversion was inexact (INEX1).  Only
+// the three exponent digits EXP2 to EXP0 are used; illegal digits above 9
+// are weighted like legal ones.
+func (p PackedDecimal) X80(mode float.Rounding) (z float.X80, inexact bool) {
+	w := binary.BigEndian.Uint32(p[:4])
+	sign := uint16(w>>16) & 0x8000
+	frac := binary.BigEndian.Uint64(p[4:])
+	if w>>16&0x7FFF == 0x7FFF {
+		if frac == 0 {
+			return float.NewFromBits(sign|0x7FFF, 0x8000000000000000), false
+		}
+		return float.NewFromBits(sign|0x7FFF, frac), false
+	}
+	m := uint64(w & 0xF)
+	for i := 60; i >= 0; i -= 4 {
+		m = m*10 + frac>>i&0xF
+	}
+	if m == 0 {
+		return float.NewFromBits(sign, 0), false
+	}
+	exp := int(w>>24&0xF)*100 + int(w>>20&0xF)*10 + int(w>>16&0xF)
+	if w&0x40000000 != 0 {
+		exp = -exp
+	}
+	r := new(big.Rat).SetInt(new(big.Int).SetUint64(m))
+	r.Mul(r, pow10(exp-16))
+	if sign != 0 {
+		r.Neg(r)
+	}
+	x := new(big.Float).SetPrec(64).SetMode(bigRounding(mode)).SetRat(r)
+	mant := new(big.Float)
+	e := x.MantExp(mant)
+	sig, _ := mant.Abs(mant).SetMantExp(mant, 64).Uint64()
+	return float.NewFromBits(sign|uint16(e-1+0x3FFF), sig), x.Acc() != big.Exact
+}
+
+// NewPackedDecimal converts `a' to a packed decimal real with the k-factor
+// `k', rounding in the rounding mode `mode', and returns the packed value
+// together with the exception status bits of the conversion.
+//
+// Only the seven least significant bits of `k' are used, as a two's
+// complement number.  A k-factor from -64 to 0 selects the number of digits
+// to the right of the decimal point, a k-factor from 1 to 17 the number of
+// significant digits.  The mantissa always has at least one and at most 17
+// digits; a k-factor above 17 selects 17 digits and is an operand error.
+// An exponent above 999 is written as four digits and is an operand error.
+// INEX2 is set if the conversion is inexact.  NaNs keep their significand.
+func NewPackedDecimal(a float.X80, k int, mode float.Rounding) (p PackedDecimal, exc uint32) {
+	k = int(int8(k<<1)) >> 1
+	if k > 17 {
+		exc |= OPERR
+	}
+	high, low := a.Bits()
+	var w uint32
+	if high&0x8000 != 0 {
+		w = 0x80000000
+	}
+	switch {
+	case high&0x7FFF == 0x7FFF:
+		if low<<1 != 0 {
+			binary.BigEndian.PutUint64(p[4:], low)
+		}
+		binary.BigEndian.PutUint32(p[:4], w|0x7FFF0000)
+		return p, exc
+	case low == 0:
+		binary.BigEndian.PutUint32(p[:4], w)
+		return p, exc
+	}
+
+	exp := int(high&0x7FFF) - 0x3FFF - 63
+	if high&0x7FFF == 0 {
+		exp++
+	}
+	x := new(big.Rat).SetInt(new(big.Int).SetUint64(low))
+	if exp < 0 {
+		x.Quo(x, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(-exp))))
+	} else {
+		x.Mul(x, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(exp))))
+	}
+
+	// Find ILOG = floor(log10(|a|)), starting from an estimate based on the
+	// binary exponent of the leading one.
+	lead := exp + 63
+	for l := low; l&0x8000000000000000 == 0; l <<= 1 {
+		lead--
+	}
+	ilog := int(float64(lead) * 0.30102999566398120)
+	for x.Cmp(pow10(ilog)) < 0 {
+		ilog--
+	}
+	for x.Cmp(pow10(ilog+1)) >= 0 {
+		ilog++
+	}
+
+	n := k
+	if k <= 0 {
+		n = ilog + 1 - k
+	}
+	n = max(1, min(n, 17))
+	x.Mul(x, pow10(n-1-ilog))
+	q, r := new(big.Int).QuoRem(x.Num(), x.Denom(), new(big.Int))
+	if r.Sign() != 0 {
+		exc |= INEX2
+		var up bool
+		switch mode {
+		case float.RoundNearestEven:
+			c := new(big.Int).Lsh(r, 1).Cmp(x.Denom())
+			up = c > 0 || c == 0 && q.Bit(0) != 0
+		case float.RoundDown:
+			up = w != 0
+		case float.RoundUp:
+			up = w == 0
+		}
+		if up {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	digits := q.String()
+	if len(digits) > n {
+		// The mantissa rounded up to 10^n.
+		digits = digits[:n]
+		ilog++
+	}
+
+	if ilog < 0 {
+		w |= 0x40000000
+		ilog = -ilog
+	}
+	if ilog > 999 {
+		exc |= OPERR
+	}
+	w |= uint32(ilog/100%10)<<24 | uint32(ilog/10%10)<<20 | uint32(ilog%10)<<16 | uint32(ilog/1000%10)<<12
+	w |= uint32(digits[0] - '0')
+	var frac uint64
+	for i := 1; i <= 16; i++ {
+		frac <<= 4
+		if i < len(digits) {
+			frac |= uint64(digits[i] - '0')
+		}
+	}
+	binary.BigEndian.PutUint32(p[:4], w)
+	binary.BigEndian.PutUint64(p[4:], frac)
+	return p, exc
+}
+
+// FMOVEFromPacked converts the packed decimal real `src' to extended
+// precision and moves it to FPn, rounded to the selected precision.  An
+// inexact decimal conversion sets INEX1.
+func (f *FPU) FMOVEFromPacked(src PackedDecimal, dst int) {
+	f.FMOVEFrom(src[:], FormatPacked, dst)
+}
+
+// FMOVEToPacked converts FPm to a packed decimal real with the static or
+// dynamic k-factor `k', as described for NewPackedDecimal.  The condition
+// codes are not changed.  The result is to be written to the destination
+// unless `ok' is false, which only an enabled SNAN exception causes; operand
+// errors still deliver the 17-digit or four-digit exponent result.
+func (f *FPU) FMOVEToPacked(src, k int) (p PackedDecimal, ok bool) {
+	b, ok := f.FMOVETo(src, FormatPacked, k)
+	copy(p[:], b)
+	return p, ok
+}
@@ -0,0 +1,133 @@
+package m68881
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func packed(w uint32, frac uint64) (p PackedDecimal) {
+	binary.BigEndian.PutUint32(p[:4], w)
+	binary.BigEndian.PutUint64(p[4:], frac)
+	return p
+}
+
+func TestNewPackedDecimal(t *testing.T) {
+	x := float.NewFromFloat64(12345.6875)
+	tests := []struct {
+		name string
+		a    float.X80
+		k    int
+		mode float.Rounding
+		want PackedDecimal
+		exc  uint32
+	}{
+		{"k=-4", x, -4, float.RoundNearestEven, packed(0x00040001, 0x2345687500000000), 0},
+		{"k=-2", x, -2, float.RoundNearestEven, packed(0x00040001, 0x2345690000000000), INEX2},
+		{"k=-2 RZ", x, -2, float.RoundToZero, packed(0x00040001, 0x2345680000000000), INEX2},
+		{"k=0", x, 0, float.RoundNearestEven, packed(0x00040001, 0x2346000000000000), INEX2},
+		{"k=1", x, 1, float.RoundNearestEven, packed(0x00040001, 0), INEX2},
+		{"k=1 RP", x, 1, float.RoundUp, packed(0x00040002, 0), INEX2},
+		{"k=3", x, 3, float.RoundNearestEven, packed(0x00040001, 0x2300000000000000), INEX2},
+		{"k=18", x, 18, float.RoundNearestEven, packed(0x00040001, 0x2345687500000000), OPERR},
+		{"k=0x7F", x, 0x7F, float.RoundNearestEven, packed(0x00040001, 0x2345700000000000), INEX2},
+		{"k=-8 small", float.NewFromFloat64(0.00390625), -8, float.RoundNearestEven, packed(0x40030003, 0x9062500000000000), 0},
+		{"k=-1 small", float.NewFromFloat64(0.00390625), -1, float.RoundNearestEven, packed(0x40030004, 0), INEX2},
+		{"negative", float.NewFromFloat64(-0.00390625), 3, float.RoundNearestEven, packed(0xC0030003, 0x9100000000000000), INEX2},
+		{"negative RM", float.NewFromFloat64(-0.00390625), 3, float.RoundDown, packed(0xC0030003, 0x9100000000000000), INEX2},
+		{"negative RP", float.NewFromFloat64(-0.00390625), 3, float.RoundUp, packed(0xC0030003, 0x9000000000000000), INEX2},
+		{"carry", float.NewFromFloat64(9.96875), 2, float.RoundNearestEven, packed(0x00010001, 0), INEX2},
+		{"exponent 10", float.Int32ToFloatX80(10).Exp10(), 1, float.RoundNearestEven, packed(0x00100001, 0), 0},
+		{"exponent overflow", float.Int32ToFloatX80(4000).Exp10(), 1, float.RoundNearestEven, packed(0x00004001, 0), OPERR | INEX2},
+		{"denormal", float.NewFromBits(0, 1), 2, float.RoundNearestEven, packed(0x49514003, 0x6000000000000000), OPERR | INEX2},
+		{"-0", float.NewFromBits(0x8000, 0), 5, float.RoundNearestEven, packed(0x80000000, 0), 0},
+		{"-inf", float.X80InfNeg, 5, float.RoundNearestEven, packed(0xFFFF0000, 0), 0},
+		{"NaN", qnanA, 5, float.RoundNearestEven, packed(0x7FFF0000, 0xC000000000000001), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exc := NewPackedDecimal(tt.a, tt.k, tt.mode)
+			if got != tt.want {
+				t.Errorf("NewPackedDecimal() = % X, want % X", got, tt.want)
+			}
+			if exc != tt.exc {
+				t.Errorf("exc = %#04x, want %#04x", exc, tt.exc)
+			}
+		})
+	}
+}
+
+func TestPackedDecimal_X80(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       PackedDecimal
+		mode    float.Rounding
+		want    float.X80
+		inexact bool
+	}{
+		{"exact", packed(0x00040001, 0x2345687500000000), float.RoundNearestEven, float.NewFromFloat64(12345.6875), false},
+		{"one", packed(0x40000001, 0), float.RoundNearestEven, one, false},
+		{"tenth", packed(0x40010001, 0), float.RoundNearestEven, float.NewFromBits(0x3FFB, 0xCCCCCCCCCCCCCCCD), true},
+		{"tenth RZ", packed(0x40010001, 0), float.RoundToZero, float.NewFromBits(0x3FFB, 0xCCCCCCCCCCCCCCCC), true},
+		{"-tenth RM", packed(0xC0010001, 0), float.RoundDown, float.NewFromBits(0xBFFB, 0xCCCCCCCCCCCCCCCD), true},
+		{"fraction only", packed(0x00020000, 0x5000000000000000), float.RoundNearestEven, float.Int32ToFloatX80(50), false},
+		{"EXP3 ignored", packed(0x00009001, 0), float.RoundNearestEven, one, false},
+		{"large", packed(0x09990009, 0x9999999999999999), float.RoundNearestEven, float.NewFromBits(0x4CF8, 0xF38DB1F9DD3DAB56), true},
+		{"-0", packed(0xC0120000, 0), float.RoundNearestEven, negZero, false},
+		{"+inf", packed(0x7FFF0000, 0), float.RoundNearestEven, float.X80InfPos, false},
+		{"NaN", packed(0xFFFF0000, 0xC000000000000002), float.RoundNearestEven, qnanB, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, inexact := tt.p.X80(tt.mode)
+			if got != tt.want {
+				t.Errorf("X80() = %s, want %s", got.Internal(), tt.want.Internal())
+			}
+			if inexact != tt.inexact {
+				t.Errorf("inexact = %v, want %v", inexact, tt.inexact)
+			}
+		})
+	}
+}
+
+func TestFPU_FMOVEPacked(t *testing.T) {
+	var f FPU
+	f.FPCR = PrecisionSingle
+	f.FMOVEFromPacked(packed(0x40010001, 0), 0)
+	if want := float.NewFromBits(0x3FFB, 0xCCCCCD0000000000); f.FP[0] != want {
+		t.Errorf("FP0 = %s, want %s", f.FP[0].Internal(), want.Internal())
+	}
+	if want := uint32(INEX1 | INEX2 | AccruedINEX); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+
+	f.FPCR = 0
+	f.FPSR = 0
+	f.FP[1] = float.X80MinusOne
+	f.FMOVEFromPacked(packed(0x7FFF0000, 0xA000000000000000), 1)
+	if want := float.NewFromBits(0x7FFF, 0xE000000000000000); f.FP[1] != want {
+		t.Errorf("FP1 = %s, want %s", f.FP[1].Internal(), want.Internal())
+	}
+	if want := uint32(CCNaN | SNAN | AccruedIOP); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+
+	f.FPSR = CCZ
+	f.FP[2] = float.NewFromFloat64(-2.5)
+	if p, ok := f.FMOVEToPacked(2, 0); !ok || p != packed(0x80000002, 0) {
+		t.Errorf("FMOVEToPacked() = % X, %v", p, ok)
+	}
+	if want := uint32(CCZ | INEX2 | AccruedINEX); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+
+	f.FPCR = SNAN
+	f.FP[3] = snan
+	if p, ok := f.FMOVEToPacked(3, 17); ok || p != packed(0x7FFF0000, 0xE000000000000000) {
+		t.Errorf("FMOVEToPacked(SNaN) = % X, %v", p, ok)
+	}
+	if v, ok := f.PendingException(); !ok || v != VectorSNAN {
+		t.Errorf("PendingException() = %d, %v, want %d", v, ok, VectorSNAN)
+	}
+}
@@ -0,0 +1,109 @@
+package m68881
+
+import "github.com/jenska/float"
+
+// An entry of the constant ROM: the constant rounded to nearest with a 67-bit
+// mantissa, the 64 bits of the extended format in `mant' and three more bits
+// in `extra', and whether this is the exact value of the constant.
+type romEntry struct {
+	high  uint16
+	mant  uint64
+	extra uint64
+	exact bool
+}
+
+// Offsets of the constant ROM.
+const (
+	ROMPi       = 0x00 // pi
+	ROMLog10Of2 = 0x0B // log10(2)
+	ROME        = 0x0C // e
+	ROMLog2E    = 0x0D // log2(e)
+	ROMLog10E   = 0x0E // log10(e)
+	ROMZero     = 0x0F // 0.0
+	ROMLn2      = 0x30 // ln(2)
+	ROMLn10     = 0x31 // ln(10)
+	ROMPow10    = 0x32 // 10^0; offsets 0x33 to 0x3F hold 10^1, 10^2, 10^4 ... 10^4096
+)
+
+var constantROM = map[int]romEntry{
+	ROMPi:       {0x4000, 0xC90FDAA22168C234, 6, false},
+	ROMLog10Of2: {0x3FFD, 0x9A209A84FBCFF798, 4, false},
+	ROME:        {0x4000, 0xADF85458A2BB4A9A, 5, false},
+	ROMLog2E:    {0x3FFF, 0xB8AA3B295C17F0BB, 6, false},
+	ROMLog10E:   {0x3FFD, 0xDE5BD8A937287195, 2, false},
+	ROMLn2:      {0x3FFE, 0xB17217F7D1CF79AB, 6, false},
+	ROMLn10:     {0x4000, 0x935D8DDDAAA8AC16, 7, false},
+	0x32:        {0x3FFF, 0x8000000000000000, 0, true},  // 10^0
+	0x33:        {0x4002, 0xA000000000000000, 0, true},  // 10^1
+	0x34:        {0x4005, 0xC800000000000000, 0, true},  // 10^2
+	0x35:        {0x400C, 0x9C40000000000000, 0, true},  // 10^4
+	0x36:        {0x4019, 0xBEBC200000000000, 0, true},  // 10^8
+	0x37:        {0x4034, 0x8E1BC9BF04000000, 0, true},  // 10^16
+	0x38:        {0x4069, 0x9DC5ADA82B70B59E, 0, false}, // 10^32
+	0x39:        {0x40D3, 0xC2781F49FFCFA6D5, 2, false}, // 10^64
+	0x3A:        {0x41A8, 0x93BA47C980E98CDF, 6, false}, // 10^128
+	0x3B:        {0x4351, 0xAA7EEBFB9DF9DE8D, 7, false}, // 10^256
+	0x3C:        {0x46A3, 0xE319A0AEA60E91C6, 6, false}, // 10^512
+	0x3D:        {0x4D48, 0xC976758681750C17, 3, false}, // 10^1024
+	0x3E:        {0x5A92, 0x9E8B3B5DC53D5DE4, 5, false}, // 10^2048
+	0x3F:        {0x7525, 0xC46052028A20979A, 6, false}, // 10^4096
+}
+
+// ConstantROM returns the constant at `offset' of the constant ROM rounded to
+// extended precision in the rounding mode `mode', as FMOVECR delivers it, and
+// reports whether it is inexact.  The ROM holds the constants with 67-bit
+// mantissas and rounds from these, so log10(2) rounded to nearest is
+// 0x3FFD9A209A84FBCFF798 rather than the correctly rounded ...F799.  The
+// reserved offsets return +0.0.
+func ConstantROM(offset int, mode float.Rounding) (z float.X80, inexact bool) {
+	return constant(offset, mode, 64)
+}
+
+// Returns the constant at `offset' rounded to `bits' significant bits in the
+// rounding mode `mode', and whether it is inexact.
+func constant(offset int, mode float.Rounding, bits int) (float.X80, bool) {
+	c, ok := constantROM[offset]
+	if !ok {
+		return float.X80Zero, false
+	}
+	shift := 64 - bits
+	kept := c.mant >> shift
+	rest := (c.mant&(1<<shift-1))<<3 | c.extra
+	half := uint64(1) << (shift + 2)
+	var up bool
+	switch mode {
+	case float.RoundNearestEven:
+		up = rest > half || rest == half && kept&1 != 0
+	case float.RoundUp:
+		up = rest != 0
+	}
+	high := c.high
+	if up {
+		kept++
+		if kept == 1<<bits || bits == 64 && kept == 0 {
+			kept = 1 << (bits - 1)
+			high++
+		}
+	}
+	return float.NewFromBits(high, kept<<shift), rest != 0 || !c.exact
+}
+
+// FMOVECR moves the constant at `offset' of the constant ROM to FPn, rounded
+// to the selected precision.  INEX2 is set for inexact constants.
+func (f *FPU) FMOVECR(offset, dst int) {
+	bits := 64
+	switch f.RoundingPrecision() {
+	case 32:
+		bits = 24
+	case 64:
+		bits = 53
+	}
+	z, inexact := constant(offset, f.RoundingMode(), bits)
+	var exc uint32
+	if inexact {
+		exc = INEX2
+	}
+	if f.complete(z, z, exc) {
+		f.store(dst, z)
+	}
+}
@@ -0,0 +1,64 @@
+package m68881
+
+import (
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func TestConstantROM(t *testing.T) {
+	tests := []struct {
+		name    string
+		offset  int
+		mode    float.Rounding
+		want    float.X80
+		inexact bool
+	}{
+		{"pi RN", ROMPi, float.RoundNearestEven, float.X80Pi, true},
+		{"pi RZ", ROMPi, float.RoundToZero, float.NewFromBits(0x4000, 0xC90FDAA22168C234), true},
+		{"pi RM", ROMPi, float.RoundDown, float.NewFromBits(0x4000, 0xC90FDAA22168C234), true},
+		{"pi RP", ROMPi, float.RoundUp, float.X80Pi, true},
+		{"log10(2) RN", ROMLog10Of2, float.RoundNearestEven, float.NewFromBits(0x3FFD, 0x9A209A84FBCFF798), true},
+		{"log10(2) RP", ROMLog10Of2, float.RoundUp, float.NewFromBits(0x3FFD, 0x9A209A84FBCFF799), true},
+		{"e RN", ROME, float.RoundNearestEven, float.X80E, true},
+		{"log2(e) RN", ROMLog2E, float.RoundNearestEven, float.X80Log2E, true},
+		{"log10(e) RN", ROMLog10E, float.RoundNearestEven, float.NewFromBits(0x3FFD, 0xDE5BD8A937287195), true},
+		{"log10(e) RP", ROMLog10E, float.RoundUp, float.NewFromBits(0x3FFD, 0xDE5BD8A937287196), true},
+		{"zero", ROMZero, float.RoundUp, float.X80Zero, false},
+		{"ln(2) RN", ROMLn2, float.RoundNearestEven, float.X80Ln2, true},
+		{"ln(10) RZ", ROMLn10, float.RoundToZero, float.NewFromBits(0x4000, 0x935D8DDDAAA8AC16), true},
+		{"10^0", 0x32, float.RoundUp, float.X80One, false},
+		{"10^16", 0x37, float.RoundUp, float.NewFromBits(0x4034, 0x8E1BC9BF04000000), false},
+		{"10^32", 0x38, float.RoundUp, float.NewFromBits(0x4069, 0x9DC5ADA82B70B59E), true},
+		{"10^4096 RN", 0x3F, float.RoundNearestEven, float.NewFromBits(0x7525, 0xC46052028A20979B), true},
+		{"reserved", 0x20, float.RoundNearestEven, float.X80Zero, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, inexact := ConstantROM(tt.offset, tt.mode)
+			if got != tt.want {
+				t.Errorf("ConstantROM() = %s, want %s", got.Internal(), tt.want.Internal())
+			}
+			if inexact != tt.inexact {
+				t.Errorf("inexact = %v, want %v", inexact, tt.inexact)
+			}
+		})
+	}
+}
+
+func TestFPU_FMOVECR(t *testing.T) {
+	var f FPU
+	f.FPCR = PrecisionSingle | RoundZero
+	f.FMOVECR(ROMPi, 3)
+	if want := float.NewFromBits(0x4000, 0xC90FDA0000000000); f.FP[3] != want {
+		t.Errorf("FP3 = %s, want %s", f.FP[3].Internal(), want.Internal())
+	}
+	if want := uint32(INEX2 | AccruedINEX); f.FPSR != want {
+		t.Errorf("FPSR = %#08x, want %#08x", f.FPSR, want)
+	}
+	f.FPCR = 0
+	f.FMOVECR(ROMZero, 3)
+	if f.FP[3] != float.X80Zero || f.FPSR != CCZ|AccruedINEX {
+		t.Errorf("FMOVECR(0) = %s, FPSR = %#08x", f.FP[3].Internal(), f.FPSR)
+	}
+}
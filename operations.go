@@ -1,16 +1,85 @@
 package float
 
-import "math"
-
 // RoundToInt rounds the extended double-precision floating-point value `a' to an integer,
 // and returns the result as an extended quadruple-precision floating-point
 // value.  The operation is performed according to the IEC/IEEE Standard for
 // Binary Floating-Point Arithmetic.
 func (a X80) RoundToInt() X80 {
+	s := newStatus()
+	return commit(&s, OpRoundToInt, s.roundFloatX80ToInt(a, s.roundingMode, true), a)
+}
+
+// RoundToIntegral rounds `a' to an integer using the rounding mode `mode'
+// instead of the global RoundingMode.  It implements the roundToIntegral
+// operations of IEEE 754 and does not raise the inexact exception.
+func (a X80) RoundToIntegral(mode Rounding) X80 {
+	s := newStatus()
+	return commit(&s, OpRoundToIntegral, s.roundFloatX80ToInt(a, mode, false), a)
+}
+
+// RoundToIntegralExact rounds `a' to an integer using the rounding mode `mode'
+// instead of the global RoundingMode.  It implements roundToIntegralExact of
+// IEEE 754 and raises the inexact exception if the result differs from `a', as
+// the 68881 FINT and FINTRZ instructions do.
+func (a X80) RoundToIntegralExact(mode Rounding) X80 {
+	s := newStatus()
+	return commit(&s, OpRoundToIntegralExact, s.roundFloatX80ToInt(a, mode, true), a)
+}
+
+// Floor returns the greatest integer value less than or equal to `a'.
+func (a X80) Floor() X80 {
+	s := newStatus()
+	return commit(&s, OpFloor, s.roundFloatX80ToInt(a, RoundDown, false), a)
+}
+
+// Ceil returns the least integer value greater than or equal to `a'.
+func (a X80) Ceil() X80 {
+	s := newStatus()
+	return commit(&s, OpCeil, s.roundFloatX80ToInt(a, RoundUp, false), a)
+}
+
+// Trunc returns the integer value of `a' rounded toward zero.
+func (a X80) Trunc() X80 {
+	s := newStatus()
+	return commit(&s, OpTrunc, s.roundFloatX80ToInt(a, RoundToZero, false), a)
+}
+
+// Round returns the nearest integer to `a', rounding half-way cases away from
+// zero.
+func (a X80) Round() X80 {
+	s := newStatus()
+	return commit(&s, OpRound, s.roundFloatX80ToInt(a, RoundNearestAway, false), a)
+}
+
+// RoundEven returns the nearest integer to `a', rounding half-way cases to the
+// even integer.
+func (a X80) RoundEven() X80 {
+	s := newStatus()
+	return commit(&s, OpRoundEven, s.roundFloatX80ToInt(a, RoundNearestEven, false), a)
+}
+
+// Rounds the extended double-precision floating-point value `a' to an integer
+// using the rounding mode `roundingMode'.  If `exact' is set, the inexact
+// exception is raised when the result differs from `a'.  Integers too wide
+// for the rounding precision are rounded to it directly rather than twice.
+func (s *status) roundFloatX80ToInt(a X80, roundingMode Rounding, exact bool) X80 {
+	roundingMode = roundingMode.normalize()
+	a, _ = s.denormalOperands(a, a)
 	aExp := a.exp()
+	if n := s.precisionBits(); n < 64 && 0x3FFF+n-1 <= aExp && aExp < 0x7FFF {
+		// Rounding to the precision already yields an integer.
+		mode, exception := s.roundingMode, s.exception
+		s.roundingMode = roundingMode
+		z := s.roundToPrecision(a)
+		s.roundingMode = mode
+		if !exact && s.exception&ExceptionOverflow == 0 {
+			s.exception = exception | s.exception&^ExceptionInexact
+		}
+		return z
+	}
 	if 0x403E <= aExp {
 		if aExp == 0x7FFF && a.frac()<<1 != 0 {
-			return propagateFloatX80NaN(a, a)
+			return s.propagateFloatX80NaN(a, a)
 		}
 		return a
 	}
@@ -18,13 +87,21 @@ func (a X80) RoundToInt() X80 {
 		if aExp == 0 && a.frac()<<1 == 0 {
 			return a
 		}
-		Raise(ExceptionInexact)
+		if exact {
+			s.raise(ExceptionInexact)
+		}
 		aSign := a.sign()
-		switch RoundingMode {
+		switch roundingMode {
 		case RoundNearestEven:
 			if aExp == 0x3FFE && a.frac()<<1 != 0 {
 				return packFloatX80(aSign, 0x3FFF, 0x8000000000000000)
 			}
+		case RoundNearestAway:
+			if aExp == 0x3FFE {
+				return packFloatX80(aSign, 0x3FFF, 0x8000000000000000)
+			}
+		case RoundToOdd:
+			return packFloatX80(aSign, 0x3FFF, 0x8000000000000000)
 		case RoundDown:
 			if aSign {
 				return packFloatX80(true, 0x3FFF, 0x8000000000000000)
@@ -38,16 +115,23 @@ func (a X80) RoundToInt() X80 {
 		}
 		return packFloatX80(aSign, 0, 0)
 	}
-	lastBitMask := uint64(1 << (0x403E - aExp))
+	lastBitMask := uint64(1) << (0x403E - aExp)
 	roundBitsMask := lastBitMask - 1
 	z := a
-	roundingMode := RoundingMode
-	if roundingMode == RoundNearestEven {
+	switch roundingMode {
+	case RoundNearestEven:
 		z.low += lastBitMask >> 1
 		if z.low&roundBitsMask == 0 {
 			z.low &= ^lastBitMask
 		}
-	} else if roundingMode != RoundToZero {
+	case RoundNearestAway:
+		z.low += lastBitMask >> 1
+	case RoundToOdd:
+		if z.low&roundBitsMask != 0 {
+			z.low |= lastBitMask
+		}
+	case RoundToZero:
+	default:
 		if z.sign() != (roundingMode == RoundUp) {
 			z.low += roundBitsMask
 		}
@@ -57,32 +141,87 @@ func (a X80) RoundToInt() X80 {
 		z.high++
 		z.low = 0x8000000000000000
 	}
-	if z.low != a.low {
-		Raise(ExceptionInexact)
+	if exact && z.low != a.low {
+		s.raise(ExceptionInexact)
 	}
 	return z
 }
 
+// Returns the number of significand bits of the rounding precision.
+func (s *status) precisionBits() int {
+	switch s.roundingPrecision {
+	case 32:
+		return 24
+	case 64:
+		return 53
+	}
+	return 64
+}
+
+// Returns the operand `a' as the result of an operation, rounded to a
+// rounding precision of 32 or 64.
+func (s *status) narrow(a X80) X80 {
+	if s.precisionBits() < 64 {
+		return s.roundToPrecision(a)
+	}
+	return a
+}
+
+// Rounds `a' to the rounding precision, as when it is loaded into a
+// register.  Subnormal values are kept at full extended precision.
+func (s *status) roundToPrecision(a X80) X80 {
+	a, _ = s.denormalOperands(a, a)
+	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
+	if aExp == 0x7FFF {
+		if aSig<<1 != 0 {
+			return s.propagateFloatX80NaN(a, a)
+		}
+		return a
+	}
+	if aSig == 0 {
+		return packFloatX80(aSign, 0, 0)
+	}
+	if aExp == 0 {
+		if s.roundingPrecision != 32 && s.roundingPrecision != 64 {
+			return a
+		}
+		aExp = 1
+	}
+	return s.normalizeRoundAndPackFloatX80(s.roundingPrecision, aSign, aExp, aSig, 0)
+}
+
 // Add returns the result of adding the extended double-precision floating-point
 // values `a' and `b'.  The operation is performed according to the IEC/IEEE
 // Standard for Binary Floating-Point Arithmetic.
 func (a X80) Add(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpAdd, s.add(a, b), a, b)
+}
+
+func (s *status) add(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
 	aSign, bSign := a.sign(), b.sign()
 	if aSign == bSign {
-		return addFloatx80Sigs(a, b, aSign)
+		return s.addFloatx80Sigs(a, b, aSign)
 	}
-	return subFloatx80Sigs(a, b, aSign)
+	return s.subFloatx80Sigs(a, b, aSign)
 }
 
 // Sub returns the result of subtracting the extended double-precision floating-
 // point values `a' and `b'.  The operation is performed according to the
 // IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) Sub(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpSub, s.sub(a, b), a, b)
+}
+
+func (s *status) sub(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
 	aSign, bSign := a.sign(), b.sign()
 	if aSign == bSign {
-		return subFloatx80Sigs(a, b, aSign)
+		return s.subFloatx80Sigs(a, b, aSign)
 	}
-	return addFloatx80Sigs(a, b, aSign)
+	return s.addFloatx80Sigs(a, b, aSign)
 
 }
 
@@ -91,7 +230,7 @@ func (a X80) Sub(b X80) X80 {
 // negated before being returned.  `zSign' is ignored if the result is a NaN.
 // The addition is performed according to the IEC/IEEE Standard for Binary
 // Floating-Point Arithmetic.
-func addFloatx80Sigs(a, b X80, zSign bool) X80 {
+func (s *status) addFloatx80Sigs(a, b X80, zSign bool) X80 {
 	aSig, bSig := a.frac(), b.frac()
 	aExp, bExp := a.exp(), b.exp()
 	var zSig0, zSig1 uint64
@@ -100,7 +239,7 @@ func addFloatx80Sigs(a, b X80, zSign bool) X80 {
 	if 0 < expDiff {
 		if aExp == 0x7FFF {
 			if aSig<<1 != 0 {
-				return propagateFloatX80NaN(a, b)
+				return s.propagateFloatX80NaN(a, b)
 			}
 			return a
 		}
@@ -112,7 +251,7 @@ func addFloatx80Sigs(a, b X80, zSign bool) X80 {
 	} else if expDiff < 0 {
 		if bExp == 0x7FFF {
 			if bSig<<1 != 0 {
-				return propagateFloatX80NaN(a, b)
+				return s.propagateFloatX80NaN(a, b)
 			}
 			return packFloatX80(zSign, 0x7FFF, 0x8000000000000000)
 		}
@@ -124,7 +263,7 @@ func addFloatx80Sigs(a, b X80, zSign bool) X80 {
 	} else {
 		if aExp == 0x7FFF {
 			if (aSig|bSig)<<1 != 0 {
-				return propagateFloatX80NaN(a, b)
+				return s.propagateFloatX80NaN(a, b)
 			}
 			return a
 		}
@@ -132,20 +271,20 @@ func addFloatx80Sigs(a, b X80, zSign bool) X80 {
 		zSig0 = aSig + bSig
 		if aExp == 0 {
 			zExp, zSig0 = normalizeFloatX80Subnormal(zSig0)
-			return roundAndPackFloatX80(RoundingPrecision, zSign, zExp, zSig0, zSig1)
+			return s.roundAndPackFloatX80(s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 		}
 		zExp = aExp
 		goto shiftRight
 	}
 	zSig0 = aSig + bSig
 	if int64(zSig0) < 0 {
-		return roundAndPackFloatX80(RoundingPrecision, zSign, zExp, zSig0, zSig1)
+		return s.roundAndPackFloatX80(s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 	}
 shiftRight:
 	zSig0, zSig1 = shift64ExtraRightJamming(zSig0, zSig1, 1)
 	zSig0 |= 0x8000000000000000
 	zExp++
-	return roundAndPackFloatX80(RoundingPrecision, zSign, zExp, zSig0, zSig1)
+	return s.roundAndPackFloatX80(s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 }
 
 // Returns the result of subtracting the absolute values of the extended
@@ -153,7 +292,7 @@ shiftRight:
 // difference is negated before being returned.  `zSign' is ignored if the
 // result is a NaN.  The subtraction is performed according to the IEC/IEEE
 // Standard for Binary Floating-Point Arithmetic.
-func subFloatx80Sigs(a, b X80, zSign bool) X80 {
+func (s *status) subFloatx80Sigs(a, b X80, zSign bool) X80 {
 	aSig, bSig := a.frac(), b.frac()
 	aExp, bExp := a.exp(), b.exp()
 	var zSig0, zSig1 uint64
@@ -168,9 +307,9 @@ func subFloatx80Sigs(a, b X80, zSign bool) X80 {
 	}
 	if aExp == 0x7FFF {
 		if (aSig|bSig)<<1 != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return X80NaN
 	}
 	if aExp == 0 {
@@ -183,11 +322,11 @@ func subFloatx80Sigs(a, b X80, zSign bool) X80 {
 	if aSig < bSig {
 		goto bBigger
 	}
-	return packFloatX80(RoundingMode == RoundDown, 0, 0)
+	return packFloatX80(s.roundingMode == RoundDown, 0, 0)
 bExpBigger:
 	if bExp == 0x7FFF {
 		if bSig<<1 != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		return packFloatX80(!zSign, 0x7FFF, 0x8000000000000000)
 	}
@@ -203,7 +342,7 @@ bBigger:
 aExpBigger:
 	if aExp == 0x7FFF {
 		if uint64(aSig<<1) != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		return a
 	}
@@ -215,8 +354,8 @@ aBigger:
 	zSig0, zSig1 = sub128(aSig, 0, bSig, zSig1)
 	zExp = aExp
 normalizeRoundAndPack:
-	return normalizeRoundAndPackFloatX80(
-		RoundingPrecision, zSign, zExp, zSig0, zSig1)
+	return s.normalizeRoundAndPackFloatX80(
+		s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 
 }
 
@@ -224,16 +363,22 @@ normalizeRoundAndPack:
 // point values `a' and `b'.  The operation is performed according to the
 // IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) Mul(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpMul, s.mul(a, b), a, b)
+}
+
+func (s *status) mul(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
 	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
 	bSig, bExp, bSign := b.frac(), b.exp(), b.sign()
 	zSign := aSign != bSign
 
 	if aExp == 0x7FFF {
 		if aSig<<1 != 0 || (bExp == 0x7FFF && bSig<<1 != 0) {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		if bExp == 0 && bSig == 0 {
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 			return X80NaN
 		}
 		return packFloatX80(zSign, 0x7FFF, 0x8000000000000000)
@@ -241,10 +386,10 @@ func (a X80) Mul(b X80) X80 {
 
 	if bExp == 0x7FFF {
 		if bSig<<1 != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		if aExp == 0 && aSig == 0 {
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 			return X80NaN
 		}
 		return packFloatX80(zSign, 0x7FFF, 0x8000000000000000)
@@ -263,46 +408,52 @@ func (a X80) Mul(b X80) X80 {
 	}
 	zExp := aExp + bExp - 0x3FFE
 	zSig0, zSig1 := mul64To128(aSig, bSig)
-	if 0 < zSig0 {
+	if int64(zSig0) > 0 {
 		zSig0, zSig1 = shortShift128Left(zSig0, zSig1, 1)
 		zExp--
 	}
-	return roundAndPackFloatX80(RoundingPrecision, zSign, zExp, zSig0, zSig1)
+	return s.roundAndPackFloatX80(s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 }
 
 // Div returns the result of dividing the extended double-precision floating-point
 // value `a' by the corresponding value `b'.  The operation is performed
 // according to the IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) Div(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpDiv, s.div(a, b), a, b)
+}
+
+func (s *status) div(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
 	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
 	bSig, bExp, bSign := b.frac(), b.exp(), b.sign()
 	zSign := aSign != bSign
 	if aExp == 0x7FFF {
 		if uint64(aSig<<1) != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		if bExp == 0x7FFF {
 			if uint64(bSig<<1) != 0 {
-				return propagateFloatX80NaN(a, b)
+				return s.propagateFloatX80NaN(a, b)
 			}
-			Raise(ExceptionInvalid)
+			s.raise(ExceptionInvalid)
 			return X80NaN
 		}
 		return packFloatX80(zSign, 0x7FFF, 0x8000000000000000)
 	}
 	if bExp == 0x7FFF {
 		if bSig<<1 != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b)
 		}
 		return packFloatX80(zSign, 0, 0)
 	}
 	if bExp == 0 {
 		if bSig == 0 {
-			if aExp != 0 && aSig != 0 {
-				Raise(ExceptionInvalid)
+			if aExp == 0 && aSig == 0 {
+				s.raise(ExceptionInvalid)
 				return X80NaN
 			}
-			Raise(ExceptionDivbyzero)
+			s.raise(ExceptionDivbyzero)
 			return packFloatX80(zSign, 0x7FFF, 0x8000000000000000)
 		}
 		bExp, bSig = normalizeFloatX80Subnormal(bSig)
@@ -334,44 +485,69 @@ func (a X80) Div(b X80) X80 {
 			zSig1--
 			rem1, rem2 = add128(rem1, rem2, 0, bSig)
 		}
-		if rem1 != 0 && rem2 != 0 {
+		if rem1 != 0 || rem2 != 0 {
 			zSig1 |= 1
 		}
 	}
-	return roundAndPackFloatX80(RoundingPrecision, zSign, zExp, zSig0, zSig1)
+	return s.roundAndPackFloatX80(s.roundingPrecision, zSign, zExp, zSig0, zSig1)
 }
 
 // Rem returns the remainder of the extended double-precision floating-point value
 // `a' with respect to the corresponding value `b'.  The operation is performed
 // according to the IEC/IEEE Standard for Binary Floating-Point Arithmetic.
 func (a X80) Rem(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpRem, s.rem(a, b), a, b)
+}
+
+func (s *status) rem(a, b X80) X80 {
+	z, _ := s.remQuo(a, b, false)
+	return z
+}
+
+// Mod returns the remainder of the extended double-precision floating-point
+// value `a' with respect to the corresponding value `b', where the quotient
+// is truncated towards zero as in C's fmod.  The result has the sign of `a'
+// and is exact unless it is rounded to a RoundingPrecision of 32 or 64.
+func (a X80) Mod(b X80) X80 {
+	s := newStatus()
+	z, _ := s.remQuo(a, b, true)
+	return commit(&s, OpMod, z, a, b)
+}
+
+// Returns the remainder of `a' with respect to `b' and the low 64 bits of
+// the magnitude of the integral quotient.  The quotient is rounded to nearest
+// even as required by the IEC/IEEE Standard, or truncated if `truncate' is
+// set.
+func (s *status) remQuo(a, b X80, truncate bool) (X80, uint64) {
+	a, b = s.denormalOperands(a, b)
 	aSig0, aExp, aSign := a.frac(), a.exp(), a.sign()
 	bSig, bExp := b.frac(), b.exp()
-	var term0, term1, q uint64
+	var term0, term1, q, quo uint64
 
 	if aExp == 0x7FFF {
 		if aSig0<<1 != 0 || (bExp == 0x7FFF && bSig<<1 != 0) {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b), 0
 		}
-		Raise(ExceptionInvalid)
-		return X80NaN
+		s.raise(ExceptionInvalid)
+		return X80NaN, 0
 	}
 	if bExp == 0x7FFF {
 		if bSig<<1 != 0 {
-			return propagateFloatX80NaN(a, b)
+			return s.propagateFloatX80NaN(a, b), 0
 		}
-		return a
+		return s.narrow(a), 0
 	}
 	if bExp == 0 {
 		if bSig == 0 {
-			Raise(ExceptionInvalid)
-			return X80NaN
+			s.raise(ExceptionInvalid)
+			return X80NaN, 0
 		}
 		bExp, bSig = normalizeFloatX80Subnormal(bSig)
 	}
 	if aExp == 0 {
 		if aSig0<<1 == 0 {
-			return a
+			return a, 0
 		}
 		aExp, aSig0 = normalizeFloatX80Subnormal(aSig0)
 	}
@@ -380,18 +556,20 @@ func (a X80) Rem(b X80) X80 {
 	expDiff := aExp - bExp
 	aSig1 := uint64(0)
 	if expDiff < 0 {
-		if expDiff < -1 {
-			return a
+		if expDiff < -1 || truncate {
+			return s.narrow(a), 0
 		}
 		aSig0, aSig1 = shift128Right(aSig0, 0, 1)
 		expDiff = 0
 	}
 	if bSig <= aSig0 {
 		aSig0 -= bSig
+		q = 1
 	}
+	quo = q
 	expDiff -= 64
 	for 0 < expDiff {
-		q := estimateDiv128To64(aSig0, aSig1, bSig)
+		q = estimateDiv128To64(aSig0, aSig1, bSig)
 		if 2 < q {
 			q -= 2
 		} else {
@@ -400,11 +578,12 @@ func (a X80) Rem(b X80) X80 {
 		term0, term1 = mul64To128(bSig, q)
 		aSig0, aSig1 = sub128(aSig0, aSig1, term0, term1)
 		aSig0, aSig1 = shortShift128Left(aSig0, aSig1, 62)
+		quo = quo<<62 + q
 		expDiff -= 62
 	}
 	expDiff += 64
 	if 0 < expDiff {
-		q := estimateDiv128To64(aSig0, aSig1, bSig)
+		q = estimateDiv128To64(aSig0, aSig1, bSig)
 		if 2 < q {
 			q -= 2
 		} else {
@@ -418,42 +597,85 @@ func (a X80) Rem(b X80) X80 {
 			q++
 			aSig0, aSig1 = sub128(aSig0, aSig1, term0, term1)
 		}
+		quo = quo<<expDiff + q
 	} else {
 		term1 = 0
 		term0 = bSig
 	}
-	alternateASig0, alternateASig1 := sub128(term0, term1, aSig0, aSig1)
-	if lt128(alternateASig0, alternateASig1, aSig0, aSig1) ||
-		eq128(alternateASig0, alternateASig1, aSig0, aSig1) &&
-			(q&1) != 0 {
-		aSig0 = alternateASig0
-		aSig1 = alternateASig1
-		zSign = !zSign
+	if !truncate {
+		alternateASig0, alternateASig1 := sub128(term0, term1, aSig0, aSig1)
+		if lt128(alternateASig0, alternateASig1, aSig0, aSig1) ||
+			eq128(alternateASig0, alternateASig1, aSig0, aSig1) &&
+				(q&1) != 0 {
+			aSig0 = alternateASig0
+			aSig1 = alternateASig1
+			zSign = !zSign
+			quo++
+		}
+	}
+	return s.normalizeRoundAndPackFloatX80(s.roundingPrecision, zSign, bExp+expDiff, aSig0, aSig1), quo
+}
+
+// Scalbn returns a * 2^n for the extended double-precision floating-point
+// value `a', rounded to the current precision.
+func (a X80) Scalbn(n int) X80 {
+	s := newStatus()
+	return commit(&s, OpScalbn, s.scalbn(a, n), a)
+}
+
+func (s *status) scalbn(a X80, n int) X80 {
+	a, _ = s.denormalOperands(a, a)
+	aSig, aExp, aSign := a.frac(), a.exp(), a.sign()
+	if aExp == 0x7FFF {
+		if aSig<<1 != 0 {
+			return s.propagateFloatX80NaN(a, a)
+		}
+		return a
+	}
+	if aSig == 0 {
+		return packFloatX80(aSign, 0, 0)
+	}
+	if aExp == 0 {
+		aExp = 1
+	}
+	if n > 0x7000 {
+		n = 0x7000
+	} else if n < -0x7000 {
+		n = -0x7000
 	}
-	return normalizeRoundAndPackFloatX80(80, zSign, bExp+expDiff, aSig0, aSig1)
+	return s.normalizeRoundAndPackFloatX80(s.roundingPrecision, aSign, aExp+n, aSig, 0)
 }
 
 // Sqrt returns the square root of the extended double-precision floating-point
 // value `a'.  The operation is performed according to the IEC/IEEE Standard
 // for Binary Floating-Point Arithmetic.
 func (a X80) Sqrt() X80 {
+	s := newStatus()
+	return commit(&s, OpSqrt, s.sqrt(a), a)
+}
+
+func (s *status) sqrt(a X80) X80 {
+	// A negative subnormal operand is invalid rather than denormal.
+	if !a.sign() || s.denormalsAreZero {
+		a, _ = s.denormalOperands(a, a)
+	}
 	aSig0, aExp, aSign := a.frac(), a.exp(), a.sign()
 	var aSig1 uint64
 	if aExp == 0x7FFF {
 		if aSig0<<1 != 0 {
-			return propagateFloatX80NaN(a, a)
+			return s.propagateFloatX80NaN(a, a)
 		}
 		if !aSign {
 			return a
 		}
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return X80NaN
 	}
 	if aSign {
-		if aExp != 0 && aSig0 != 0 {
+		if aExp == 0 && aSig0 == 0 {
 			return a
 		}
-		Raise(ExceptionInvalid)
+		s.raise(ExceptionInvalid)
 		return X80NaN
 	}
 	if aExp == 0 {
@@ -496,122 +718,5 @@ func (a X80) Sqrt() X80 {
 	}
 	zSig0, zSig1 = shortShift128Left(0, zSig1, 1)
 	zSig0 |= doubleZSig0
-	return roundAndPackFloatX80(RoundingPrecision, false, zExp, zSig0, zSig1)
-}
-
-// Ln returns the natural logarithm of the extended double-precision floating-point value `a'.
-// The operation is performed using a series expansion for ln(1+x).
-func (a X80) Ln() X80 {
-	if a.IsNaN() || a.sign() {
-		Raise(ExceptionInvalid)
-		return X80NaN
-	}
-	if a.Eq(X80Zero) {
-		Raise(ExceptionDivbyzero)
-		return X80InfNeg
-	}
-	if a.Eq(X80One) {
-		return X80Zero
-	}
-	if a.IsInf() {
-		return X80InfPos
-	}
-
-	// Reduce to [1, 2)
-	exp := 0
-	x := a
-	two := newFromHexString("40008000000000000000") // 2
-	for x.Gt(two) {                                 // > 2
-		x = x.Div(two) // / 2
-		exp++
-	}
-	for x.Lt(X80One) { // < 1
-		x = x.Mul(two) // * 2
-		exp--
-	}
-
-	// Now x is in [1, 2), compute ln(x) using series ln(1+y) where y = x - 1
-	y := x.Sub(X80One)
-	result := X80Zero
-	term := y
-	sign := X80One
-	for i := 1; i <= 20; i++ {
-		result = result.Add(term.Div(Int64ToFloatX80(int64(i))).Mul(sign))
-		term = term.Mul(y)
-		sign = sign.Mul(X80MinusOne)
-	}
-
-	// Add exp * ln(2)
-	if exp != 0 {
-		result = result.Add(Int64ToFloatX80(int64(exp)).Mul(X80Ln2))
-	}
-
-	return result
-}
-
-// Atan returns the arctangent of the extended double-precision floating-point value `a'.
-// The operation is performed using a series expansion.
-func (a X80) Atan() X80 {
-	if a.IsNaN() {
-		return X80NaN
-	}
-	if a.IsInf() {
-		if a.sign() {
-			return X80Pi.Mul(X80MinusOne).Div(Int64ToFloatX80(2))
-		}
-		return X80Pi.Div(Int64ToFloatX80(2))
-	}
-
-	absA := a
-	if a.sign() {
-		absA = a.Mul(X80MinusOne)
-	}
-
-	var result X80
-	if absA.Gt(X80One) {
-		// For |x| > 1, atan(x) = pi/2 - atan(1/x)
-		invA := X80One.Div(absA)
-		result = X80Pi.Div(Int64ToFloatX80(2)).Sub(invA.Atan())
-	} else {
-		// Series: atan(x) = x - x^3/3 + x^5/5 - x^7/7 + ...
-		result = X80Zero
-		term := absA
-		x2 := absA.Mul(absA)
-		sign := X80One
-		for i := 1; i <= 15; i += 2 {
-			result = result.Add(term.Div(Int64ToFloatX80(int64(i))).Mul(sign))
-			term = term.Mul(x2)
-			sign = sign.Mul(X80MinusOne)
-		}
-	}
-
-	if a.sign() {
-		result = result.Mul(X80MinusOne)
-	}
-
-	return result
-}
-
-// Sin returns the sine of the extended double-precision floating-point value `a'.
-func (a X80) Sin() X80 {
-	if a.IsNaN() {
-		return X80NaN
-	}
-	return Float64ToFloatX80(math.Sin(a.ToFloat64()))
-}
-
-// Cos returns the cosine of the extended double-precision floating-point value `a'.
-func (a X80) Cos() X80 {
-	if a.IsNaN() {
-		return X80NaN
-	}
-	return Float64ToFloatX80(math.Cos(a.ToFloat64()))
-}
-
-// Tan returns the tangent of the extended double-precision floating-point value `a'.
-func (a X80) Tan() X80 {
-	if a.IsNaN() {
-		return X80NaN
-	}
-	return Float64ToFloatX80(math.Tan(a.ToFloat64()))
+	return s.roundAndPackFloatX80(s.roundingPrecision, false, zExp, zSig0, zSig1)
 }
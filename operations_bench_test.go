@@ -46,3 +46,17 @@ func BenchmarkX80_Atan(b *testing.B) {
 		a = a.Atan()
 	}
 }
+
+func BenchmarkX80_Exp(b *testing.B) {
+	a := X80Pi
+	for i := 0; i < b.N; i++ {
+		a.Exp()
+	}
+}
+
+func BenchmarkX80_Sin(b *testing.B) {
+	a := X80Pi
+	for i := 0; i < b.N; i++ {
+		a.Sin()
+	}
+}
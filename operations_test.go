@@ -2,6 +2,7 @@ package float
 
 import (
 	"math"
+	"slices"
 	"testing"
 )
 
@@ -96,6 +97,29 @@ func TestX80_Sub(t *testing.T) {
 	}
 }
 
+func TestX80_Mul(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b X80
+		want X80
+	}{
+		// Products of 2 or more must not be normalized by a left shift.
+		{"1.5*1.5", Float64ToFloatX80(1.5), Float64ToFloatX80(1.5), Float64ToFloatX80(2.25)},
+		{"max significand squared", X80{0x3FFF, 0xFFFFFFFFFFFFFFFF}, X80{0x3FFF, 0xFFFFFFFFFFFFFFFF}, X80{0x4000, 0xFFFFFFFFFFFFFFFE}},
+		{"2*3", Int32ToFloatX80(2), Int32ToFloatX80(3), Int32ToFloatX80(6)},
+		{"sqrt2*sqrt2", X80Sqrt2, X80Sqrt2, newFromHexString("3FFFFFFFFFFFFFFFFFFF")},
+		{"-1*pi", X80MinusOne, X80Pi, newFromHexString("C000C90FDAA22168C235")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Mul(tt.b); got != tt.want {
+				t.Errorf("X80.Mul() = %v, want %v", got.Internal(), tt.want.Internal())
+			}
+		})
+	}
+	ClearExceptions()
+}
+
 func TestX80_Ln(t *testing.T) {
 	tests := []struct {
 		name string
@@ -222,8 +246,8 @@ func TestExceptionHandling(t *testing.T) {
 
 	// Test exception handler
 	handlerCalled := false
-	var raisedException int
-	SetExceptionHandler(func(exc int) {
+	var raisedException Flags
+	SetExceptionHandler(func(exc Flags) {
 		handlerCalled = true
 		raisedException = exc
 	})
@@ -240,3 +264,483 @@ func TestExceptionHandling(t *testing.T) {
 	// Reset handler
 	SetExceptionHandler(nil)
 }
+
+func TestX80_RoundingFunctions(t *testing.T) {
+	half := newFromHexString("3FFE8000000000000000")         // 0.5
+	onePointFive := newFromHexString("3FFFC000000000000000") // 1.5
+	twoPointFive := newFromHexString("4000A000000000000000") // 2.5
+	minusTwoPointFive := newFromHexString("C000A000000000000000")
+	minusOnePointSeven := Float64ToFloatX80(-1.7)
+	negZero := newFromHexString("80000000000000000000")
+	two := Int64ToFloatX80(2)
+	three := Int64ToFloatX80(3)
+	minusTwo := Int64ToFloatX80(-2)
+	minusThree := Int64ToFloatX80(-3)
+
+	tests := []struct {
+		name string
+		a    X80
+		fn   func(X80) X80
+		want X80
+	}{
+		{"Floor(2.5)", twoPointFive, X80.Floor, two},
+		{"Floor(-2.5)", minusTwoPointFive, X80.Floor, minusThree},
+		{"Floor(-0.5)", half.Mul(X80MinusOne), X80.Floor, X80MinusOne},
+		{"Floor(-0)", negZero, X80.Floor, negZero},
+		{"Ceil(2.5)", twoPointFive, X80.Ceil, three},
+		{"Ceil(-2.5)", minusTwoPointFive, X80.Ceil, minusTwo},
+		{"Ceil(-0.5)", half.Mul(X80MinusOne), X80.Ceil, negZero},
+		{"Trunc(2.5)", twoPointFive, X80.Trunc, two},
+		{"Trunc(-1.7)", minusOnePointSeven, X80.Trunc, X80MinusOne},
+		{"Round(0.5)", half, X80.Round, X80One},
+		{"Round(2.5)", twoPointFive, X80.Round, three},
+		{"Round(-2.5)", minusTwoPointFive, X80.Round, minusThree},
+		{"Round(-1.7)", minusOnePointSeven, X80.Round, minusTwo},
+		{"RoundEven(0.5)", half, X80.RoundEven, X80Zero},
+		{"RoundEven(1.5)", onePointFive, X80.RoundEven, two},
+		{"RoundEven(2.5)", twoPointFive, X80.RoundEven, two},
+		{"RoundEven(-2.5)", minusTwoPointFive, X80.RoundEven, minusTwo},
+		{"Floor(inf)", X80InfPos, X80.Floor, X80InfPos},
+		{"Round(pi)", X80Pi, X80.Round, three},
+	}
+	savedMode := RoundingMode
+	RoundingMode = RoundUp
+	defer func() { RoundingMode = savedMode }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			if got := tt.fn(tt.a); got != tt.want {
+				t.Errorf("got %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if HasException(ExceptionInexact) {
+				t.Error("unexpected inexact exception")
+			}
+		})
+	}
+	if RoundingMode != RoundUp {
+		t.Error("rounding functions modified RoundingMode")
+	}
+}
+
+func TestX80_RoundToIntegralExact(t *testing.T) {
+	twoPointFive := newFromHexString("4000A000000000000000")
+	tests := []struct {
+		name        string
+		a           X80
+		mode        Rounding
+		want        X80
+		wantInexact bool
+	}{
+		{"FINT(2.5) nearest", twoPointFive, RoundNearestEven, Int64ToFloatX80(2), true},
+		{"FINTRZ(2.5)", twoPointFive, RoundToZero, Int64ToFloatX80(2), true},
+		{"FINT(2.5) up", twoPointFive, RoundUp, Int64ToFloatX80(3), true},
+		{"FINT(-2.5) down", twoPointFive.Mul(X80MinusOne), RoundDown, Int64ToFloatX80(-3), true},
+		{"FINT(3)", Int64ToFloatX80(3), RoundNearestEven, Int64ToFloatX80(3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			if got := tt.a.RoundToIntegralExact(tt.mode); got != tt.want {
+				t.Errorf("RoundToIntegralExact() = %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if HasException(ExceptionInexact) != tt.wantInexact {
+				t.Errorf("inexact = %v, want %v", HasException(ExceptionInexact), tt.wantInexact)
+			}
+			ClearExceptions()
+			if got := tt.a.RoundToIntegral(tt.mode); got != tt.want {
+				t.Errorf("RoundToIntegral() = %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if HasException(ExceptionInexact) {
+				t.Error("RoundToIntegral() raised inexact")
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestRoundingModes_Ties(t *testing.T) {
+	onePlusUlp := newFromHexString("3FFF8000000000000001")    // 1 + 2^-63
+	onePlusTwoUlp := newFromHexString("3FFF8000000000000002") // 1 + 2^-62
+	halfUlp := newFromHexString("3FBF8000000000000000")       // 2^-64
+	threeHalfUlp := newFromHexString("3FC0C000000000000000")  // 3 * 2^-64
+	halfUlp64 := newFromHexString("3FCA8000000000000000")     // 2^-53
+	onePlusUlp64 := newFromHexString("3FFF8000000000000800")  // 1 + 2^-52
+	negOnePlusUlp := newFromHexString("BFFF8000000000000001") // -(1 + 2^-63)
+	negHalfUlp := newFromHexString("BFBF8000000000000000")    // -2^-64
+	maxFinite := newFromHexString("7FFEFFFFFFFFFFFFFFFF")     // largest finite value
+	maxFinite64 := newFromHexString("7FFEFFFFFFFFFFFFF800")   // largest value at precision 64
+
+	tests := []struct {
+		name      string
+		mode      Rounding
+		precision int
+		a, b      X80
+		want      X80
+	}{
+		{"1 + half ulp, nearest even", RoundNearestEven, 80, X80One, halfUlp, X80One},
+		{"1 + half ulp, nearest away", RoundNearestAway, 80, X80One, halfUlp, onePlusUlp},
+		{"1 + half ulp, to odd", RoundToOdd, 80, X80One, halfUlp, onePlusUlp},
+		{"1 + 3/2 ulp, nearest even", RoundNearestEven, 80, X80One, threeHalfUlp, onePlusTwoUlp},
+		{"1 + 3/2 ulp, nearest away", RoundNearestAway, 80, X80One, threeHalfUlp, onePlusTwoUlp},
+		{"1 + 3/2 ulp, to odd", RoundToOdd, 80, X80One, threeHalfUlp, onePlusUlp},
+		{"-1 - half ulp, nearest away", RoundNearestAway, 80, X80MinusOne, negHalfUlp, negOnePlusUlp},
+		{"-1 - half ulp, to odd", RoundToOdd, 80, X80MinusOne, negHalfUlp, negOnePlusUlp},
+		{"1 + 2^-53 at precision 64, nearest even", RoundNearestEven, 64, X80One, halfUlp64, X80One},
+		{"1 + 2^-53 at precision 64, nearest away", RoundNearestAway, 64, X80One, halfUlp64, onePlusUlp64},
+		{"1 + 2^-53 at precision 64, to odd", RoundToOdd, 64, X80One, halfUlp64, onePlusUlp64},
+		{"1 + 0 to odd is exact", RoundToOdd, 80, X80One, X80Zero, X80One},
+		{"overflow, nearest away", RoundNearestAway, 80, maxFinite, maxFinite, X80InfPos},
+		{"overflow, to odd", RoundToOdd, 80, maxFinite, maxFinite, maxFinite},
+		{"overflow at precision 64, to odd", RoundToOdd, 64, maxFinite, maxFinite, maxFinite64},
+	}
+	savedMode, savedPrecision := RoundingMode, RoundingPrecision
+	defer func() { RoundingMode, RoundingPrecision = savedMode, savedPrecision }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode, RoundingPrecision = tt.mode, tt.precision
+			if got := tt.a.Add(tt.b); got != tt.want {
+				t.Errorf("Add() = %v, want %v", got.Internal(), tt.want.Internal())
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestRoundingModes_RoundToInt(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Rounding
+		a    float64
+		want float64
+	}{
+		{"2.5 nearest even", RoundNearestEven, 2.5, 2},
+		{"2.5 nearest away", RoundNearestAway, 2.5, 3},
+		{"-2.5 nearest away", RoundNearestAway, -2.5, -3},
+		{"0.5 nearest away", RoundNearestAway, 0.5, 1},
+		{"2.5 to odd", RoundToOdd, 2.5, 3},
+		{"4.25 to odd", RoundToOdd, 4.25, 5},
+		{"4 to odd", RoundToOdd, 4, 4},
+		{"-0.25 to odd", RoundToOdd, -0.25, -1},
+	}
+	savedMode := RoundingMode
+	defer func() { RoundingMode = savedMode }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode = tt.mode
+			if got := Float64ToFloatX80(tt.a).RoundToInt(); got != Float64ToFloatX80(tt.want) {
+				t.Errorf("RoundToInt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestDenormalOperand(t *testing.T) {
+	denormal := X80{0x0000, 0x0000000000000001}
+	pseudoDenormal := X80{0x8000, 0x8000000000000000}
+	one := X80One
+	tests := []struct {
+		name string
+		op   func()
+		want bool
+	}{
+		{"Add", func() { denormal.Add(one) }, true},
+		{"Sub", func() { one.Sub(pseudoDenormal) }, true},
+		{"Mul", func() { denormal.Mul(one) }, true},
+		{"Div", func() { one.Div(denormal) }, true},
+		{"Rem", func() { denormal.Rem(one) }, true},
+		{"Sqrt", func() { denormal.Sqrt() }, true},
+		{"Sqrt negative", func() { pseudoDenormal.Sqrt() }, false},
+		{"RoundToInt", func() { pseudoDenormal.RoundToInt() }, true},
+		{"Floor", func() { denormal.Floor() }, true},
+		{"Eq", func() { denormal.Eq(one) }, true},
+		{"Lt", func() { one.Lt(denormal) }, true},
+		{"Le", func() { denormal.Le(one) }, true},
+		{"EqSignaling", func() { denormal.EqSignaling(one) }, true},
+		{"LtQuiet", func() { denormal.LtQuiet(one) }, true},
+		{"LeQuiet", func() { denormal.LeQuiet(one) }, true},
+		{"CompareOrdered", func() { denormal.CompareOrdered(one) }, true},
+		{"CompareQuiet", func() { one.CompareQuiet(denormal) }, true},
+		{"Min", func() { denormal.Min(one) }, true},
+		{"MaxMag", func() { one.MaxMag(denormal) }, true},
+		{"Minimum", func() { denormal.Minimum(one) }, true},
+		{"ToInt32", func() { denormal.ToInt32() }, true},
+		{"ToInt32RoundZero", func() { denormal.ToInt32RoundZero() }, true},
+		{"ToInt64", func() { denormal.ToInt64() }, true},
+		{"ToInt64RoundZero", func() { denormal.ToInt64RoundZero() }, true},
+		{"ToFloat64", func() { pseudoDenormal.ToFloat64() }, true},
+		{"Float64ToFloatX80", func() { Float64ToFloatX80(math.SmallestNonzeroFloat64) }, true},
+		{"Float32ToFloatX80", func() { Float32ToFloatX80(math.SmallestNonzeroFloat32) }, true},
+		{"normal operands", func() { one.Add(one) }, false},
+		{"zero operand", func() { X80Zero.Mul(one) }, false},
+		{"NaN operand", func() { denormal.Add(X80NaN) }, false},
+		{"TotalOrder", func() { denormal.TotalOrder(one) }, false},
+	}
+	defer func() { SignalDenormal = true }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SignalDenormal = true
+			ClearExceptions()
+			tt.op()
+			if HasException(ExceptionDenormal) != tt.want {
+				t.Errorf("denormal = %v, want %v", HasException(ExceptionDenormal), tt.want)
+			}
+			SignalDenormal = false
+			ClearExceptions()
+			tt.op()
+			if HasException(ExceptionDenormal) {
+				t.Error("denormal raised with SignalDenormal disabled")
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestDenormalsAreZero(t *testing.T) {
+	denormal := X80{0x0000, 0x0000000000000001}
+	negDenormal := X80{0x8000, 0x4000000000000000}
+	negZero := X80{0x8000, 0}
+	tests := []struct {
+		name string
+		op   func() any
+		want any
+		exc  Flags
+	}{
+		{"Add", func() any { return denormal.Add(X80One) }, X80One, 0},
+		{"Sub", func() any { return denormal.Sub(denormal) }, X80Zero, 0},
+		{"Mul", func() any { return negDenormal.Mul(X80One) }, negZero, 0},
+		{"Div", func() any { return X80One.Div(denormal) }, X80InfPos, ExceptionDivbyzero},
+		{"Rem", func() any { return X80One.Rem(denormal) }, X80NaN, ExceptionInvalid},
+		{"Sqrt", func() any { return negDenormal.Sqrt() }, negZero, 0},
+		{"RoundToIntegralExact", func() any { return denormal.RoundToIntegralExact(RoundUp) }, X80Zero, 0},
+		{"Eq", func() any { return denormal.Eq(negZero) }, true, 0},
+		{"Lt", func() any { return negDenormal.Lt(denormal) }, false, 0},
+		{"Max", func() any { return negDenormal.Max(negZero) }, negZero, 0},
+		{"ToInt32RoundZero", func() any { return denormal.ToInt32RoundZero() }, int32(0), 0},
+		{"ToInt64", func() any { return denormal.ToInt64() }, int64(0), 0},
+		{"ToFloat64", func() any { return negDenormal.ToFloat64() }, math.Copysign(0, -1), 0},
+		{"Float64ToFloatX80", func() any { return Float64ToFloatX80(-math.SmallestNonzeroFloat64) }, negZero, 0},
+		{"Float32ToFloatX80", func() any { return Float32ToFloatX80(math.SmallestNonzeroFloat32) }, X80Zero, 0},
+	}
+	defer func() { DenormalsAreZero = false }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DenormalsAreZero = true
+			ClearExceptions()
+			if got := tt.op(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if Exception != tt.exc {
+				t.Errorf("exceptions = %x, want %x", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestFlushToZero(t *testing.T) {
+	minNormal := X80{0x0001, 0x8000000000000000}
+	minNormal15 := X80{0x0001, 0xC000000000000000}
+	half := Float64ToFloatX80(0.5)
+	negZero := X80{0x8000, 0}
+	tests := []struct {
+		name      string
+		precision int
+		op        func() any
+		want      any
+		exc       Flags
+	}{
+		{"Add", 80, func() any { return minNormal.Add(X80{0x8001, 0xC000000000000000}) }, negZero, ExceptionUnderflow | ExceptionInexact},
+		{"Sub", 80, func() any { return minNormal15.Sub(minNormal) }, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"Mul", 80, func() any { return minNormal.Mul(half) }, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"Mul precision 64", 64, func() any { return X80{0x8001, 0x8000000000000000}.Mul(half) }, negZero, ExceptionUnderflow | ExceptionInexact},
+		{"Mul precision 32", 32, func() any { return minNormal.Mul(half) }, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"Div", 80, func() any { return minNormal.Div(Float64ToFloatX80(3)) }, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		// 1.5 rem 1 is -0.5, as the quotient 1.5 rounds to the even 2.
+		{"Rem", 80, func() any { return minNormal15.Rem(minNormal) }, negZero, ExceptionUnderflow | ExceptionInexact},
+		{"ToFloat64", 80, func() any { return Float64ToFloatX80(2.2250738585072014e-308).Mul(half).ToFloat64() }, 0.0, ExceptionUnderflow | ExceptionInexact},
+		{"normal result", 80, func() any { return minNormal.Add(minNormal) }, X80{0x0002, 0x8000000000000000}, 0},
+		{"exact zero", 80, func() any { return minNormal.Sub(minNormal) }, X80Zero, 0},
+	}
+	defer func() {
+		FlushToZero = false
+		RoundingPrecision = 80
+	}()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			FlushToZero = true
+			RoundingPrecision = tt.precision
+			ClearExceptions()
+			if got := tt.op(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if Exception != tt.exc {
+				t.Errorf("exceptions = %x, want %x", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_DivSqrtSpecial(t *testing.T) {
+	negZero := X80{0x8000, 0}
+	tests := []struct {
+		name string
+		op   func() X80
+		want X80
+		exc  Flags
+	}{
+		{"1/0", func() X80 { return X80One.Div(X80Zero) }, X80InfPos, ExceptionDivbyzero},
+		{"-1/0", func() X80 { return X80MinusOne.Div(X80Zero) }, X80InfNeg, ExceptionDivbyzero},
+		{"0/0", func() X80 { return X80Zero.Div(X80Zero) }, X80NaN, ExceptionInvalid},
+		{"sqrt(-0)", func() X80 { return negZero.Sqrt() }, negZero, 0},
+		{"sqrt(-1)", func() X80 { return X80MinusOne.Sqrt() }, X80NaN, ExceptionInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			if got := tt.op(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if Exception != tt.exc {
+				t.Errorf("exceptions = %x, want %x", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestTraps(t *testing.T) {
+	maxNormal := X80{0x7FFE, 0xFFFFFFFFFFFFFFFF}
+	minNormal := X80{0x0001, 0x8000000000000000}
+	two := Int32ToFloatX80(2)
+	half := Float64ToFloatX80(0.5)
+	tests := []struct {
+		name     string
+		enable   Flags
+		op       func() any
+		want     any
+		trapped  Flags
+		result   X80
+		operands []X80
+	}{
+		{"overflow", ExceptionOverflow, func() any { return maxNormal.Mul(two) },
+			X80{0x1FFF, 0xFFFFFFFFFFFFFFFF}, ExceptionOverflow, X80{0x1FFF, 0xFFFFFFFFFFFFFFFF}, []X80{maxNormal, two}},
+		{"overflow masked", ExceptionUnderflow, func() any { return maxNormal.Mul(two) },
+			X80InfPos, 0, X80{}, nil},
+		{"underflow exact", ExceptionUnderflow, func() any { return minNormal.Mul(half) },
+			X80{0x6000, 0x8000000000000000}, ExceptionUnderflow, X80{0x6000, 0x8000000000000000}, []X80{minNormal, half}},
+		{"underflow precision 64", ExceptionUnderflow, func() any {
+			RoundingPrecision = 64
+			defer func() { RoundingPrecision = 80 }()
+			return minNormal.Div(two)
+		}, X80{0x6000, 0x8000000000000000}, ExceptionUnderflow, X80{0x6000, 0x8000000000000000}, []X80{minNormal, two}},
+		{"overflow too large to wrap", ExceptionOverflow, func() any { return maxNormal.Scalbn(0x7000) },
+			X80InfPos, ExceptionOverflow, X80InfPos, nil},
+		{"overflow too large to wrap precision 64", ExceptionOverflow, func() any {
+			RoundingPrecision, RoundingMode = 64, RoundToZero
+			defer func() { RoundingPrecision, RoundingMode = 80, RoundNearestEven }()
+			return X80{0xFFFE, 0xFFFFFFFFFFFFFFFF}.Scalbn(0x7000)
+		}, X80{0xFFFE, 0xFFFFFFFFFFFFF800}, ExceptionOverflow, X80{0xFFFE, 0xFFFFFFFFFFFFF800}, nil},
+		{"underflow too small to wrap", ExceptionUnderflow, func() any { return X80{0x8001, 0x8000000000000000}.Scalbn(-0x7000) },
+			X80{0x8000, 0}, ExceptionUnderflow, X80{0x8000, 0}, nil},
+		{"underflow too small to wrap precision 64", ExceptionUnderflow, func() any {
+			RoundingPrecision = 64
+			defer func() { RoundingPrecision = 80 }()
+			return minNormal.Scalbn(-0x7000)
+		}, X80Zero, ExceptionUnderflow, X80Zero, nil},
+		{"overflow of largest product", ExceptionOverflow, func() any { return maxNormal.Mul(maxNormal) },
+			X80{0x5FFE, 0xFFFFFFFFFFFFFFFE}, ExceptionOverflow, X80{0x5FFE, 0xFFFFFFFFFFFFFFFE}, nil},
+		{"overflow of largest quotient", ExceptionOverflow, func() any { return maxNormal.Div(X80{0, 1}) },
+			X80{0x603B, 0xFFFFFFFFFFFFFFFF}, ExceptionOverflow, X80{0x603B, 0xFFFFFFFFFFFFFFFF}, nil},
+		{"underflow of smallest quotient", ExceptionUnderflow, func() any { return X80{0, 1}.Div(maxNormal) },
+			X80{0x1FC2, 0x8000000000000001}, ExceptionUnderflow, X80{0x1FC2, 0x8000000000000001}, nil},
+		{"invalid", ExceptionInvalid, func() any { return X80Zero.Div(X80Zero) },
+			X80NaN, ExceptionInvalid, X80NaN, []X80{X80Zero, X80Zero}},
+		{"divide by zero", ExceptionDivbyzero | ExceptionInvalid, func() any { return X80MinusOne.Div(X80Zero) },
+			X80InfNeg, ExceptionDivbyzero, X80InfNeg, []X80{X80MinusOne, X80Zero}},
+		{"ToFloat64 overflow", ExceptionOverflow, func() any { return Float64ToFloatX80(math.MaxFloat64).Mul(two).ToFloat64() },
+			math.Ldexp(math.MaxFloat64, 1-1536), ExceptionOverflow, Float64ToFloatX80(math.Ldexp(math.MaxFloat64, 1-1536)), nil},
+		{"ToInt32 inexact", ExceptionInexact, func() any { return Float64ToFloatX80(1.5).ToInt32() },
+			int32(2), ExceptionInexact, two, []X80{Float64ToFloatX80(1.5)}},
+	}
+	defer func() {
+		TrapEnable = 0
+		SetTrapHandler(nil)
+	}()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trapped Flags
+			var result X80
+			var operands []X80
+			SetTrapHandler(func(exception Flags, r X80, ops []X80) {
+				trapped, result, operands = exception, r, ops
+			})
+			TrapEnable = tt.enable
+			ClearExceptions()
+			if got := tt.op(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			TrapEnable = 0
+			if trapped != tt.trapped {
+				t.Errorf("trapped = %x, want %x", trapped, tt.trapped)
+			}
+			if !HasException(tt.trapped) && tt.trapped != 0 {
+				t.Errorf("exceptions = %x, want %x accrued", Exception, tt.trapped)
+			}
+			if tt.trapped != 0 && result != tt.result {
+				t.Errorf("result = %v, want %v", result, tt.result)
+			}
+			if tt.operands != nil && !slices.Equal(operands, tt.operands) {
+				t.Errorf("operands = %v, want %v", operands, tt.operands)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_RemModScalbn(t *testing.T) {
+	seven, two := Int32ToFloatX80(7), Int32ToFloatX80(2)
+	tests := []struct {
+		name string
+		got  func() X80
+		want X80
+		exc  Flags
+	}{
+		{"Rem", func() X80 { return seven.Rem(two) }, X80MinusOne, 0},
+		{"Rem tie to odd quotient", func() X80 { return Int32ToFloatX80(3).Rem(two) }, X80MinusOne, 0},
+		{"Rem tie to even quotient", func() X80 { return Int32ToFloatX80(5).Rem(two) }, X80One, 0},
+		{"Rem negative tie", func() X80 { return Int32ToFloatX80(-3).Rem(two) }, X80One, 0},
+		{"Rem half", func() X80 { return Float64ToFloatX80(1.5).Rem(X80One) }, Float64ToFloatX80(-0.5), 0},
+		{"Rem large quotient", func() X80 { return X80{0x403E, 0x8000000000000003}.Rem(two) }, X80MinusOne, 0},
+		{"Mod", func() X80 { return seven.Mod(two) }, X80One, 0},
+		{"Mod negative", func() X80 { return Int32ToFloatX80(-7).Mod(two) }, X80MinusOne, 0},
+		{"Mod small", func() X80 { return X80One.Mod(seven) }, X80One, 0},
+		{"Mod by zero", func() X80 { return seven.Mod(X80Zero) }, X80NaN, ExceptionInvalid},
+		{"Mod inf", func() X80 { return X80InfPos.Mod(two) }, X80NaN, ExceptionInvalid},
+		{"Mod by inf", func() X80 { return seven.Mod(X80InfNeg) }, seven, 0},
+		{"Scalbn", func() X80 { return X80One.Scalbn(10) }, Int32ToFloatX80(1024), 0},
+		{"Scalbn negative", func() X80 { return seven.Scalbn(-1) }, Float64ToFloatX80(3.5), 0},
+		{"Scalbn denormal", func() X80 { return X80{0x0001, 0x8000000000000000}.Scalbn(-1) }, X80{0, 0x4000000000000000}, 0},
+		{"Scalbn overflow", func() X80 { return X80One.Scalbn(1 << 20) }, X80InfPos, ExceptionOverflow | ExceptionInexact},
+		{"Scalbn underflow", func() X80 { return X80One.Scalbn(-1 << 20) }, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"Scalbn inf", func() X80 { return X80InfNeg.Scalbn(-5) }, X80InfNeg, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			got := tt.got()
+			if tt.want.IsNaN() {
+				if !got.IsNaN() {
+					t.Errorf("got %v, want NaN", got.Internal())
+				}
+			} else if got != tt.want {
+				t.Errorf("got %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if Exception != tt.exc {
+				t.Errorf("Exception = %v, want %v", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
@@ -0,0 +1,249 @@
+package float
+
+// The functions in this file perform a single operation with an explicit
+// rounding mode and precision and return the exception flags it raised.  They
+// neither read nor modify any package variable, so they are safe for
+// concurrent use and suited for differential testing against hardware.  The
+// remaining attributes are fixed: tininess is detected after rounding, the
+// denormal exception is signaled, and flush-to-zero, denormals-are-zero and
+// traps are disabled.  A precision of 0 is the same as 80.
+
+// Returns the environment of an operation that does not use the globals.
+func pureStatus(mode Rounding, precision int) status {
+	if precision == 0 {
+		precision = 80
+	}
+	return status{
+		roundingMode:      mode.normalize(),
+		roundingPrecision: precision,
+		detectTininess:    TininessAfterRounding,
+		signalDenormal:    true,
+	}
+}
+
+// AddFlags returns a + b and the exception flags raised.
+func AddFlags(a, b X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.add(a, b), s.exception
+}
+
+// SubFlags returns a - b and the exception flags raised.
+func SubFlags(a, b X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.sub(a, b), s.exception
+}
+
+// MulFlags returns a * b and the exception flags raised.
+func MulFlags(a, b X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.mul(a, b), s.exception
+}
+
+// DivFlags returns a / b and the exception flags raised.
+func DivFlags(a, b X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.div(a, b), s.exception
+}
+
+// RemFlags returns the IEEE remainder of a with respect to b and the
+// exception flags raised.
+func RemFlags(a, b X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.rem(a, b), s.exception
+}
+
+// SqrtFlags returns the square root of a and the exception flags raised.
+func SqrtFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.sqrt(a), s.exception
+}
+
+// LnFlags returns the natural logarithm of a and the exception flags raised.
+func LnFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.ln(a), s.exception
+}
+
+// AtanFlags returns the arctangent of a and the exception flags raised.
+func AtanFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.atan(a), s.exception
+}
+
+// SinFlags returns the sine of a and the exception flags raised.
+func SinFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.sin(a), s.exception
+}
+
+// CosFlags returns the cosine of a and the exception flags raised.
+func CosFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.cos(a), s.exception
+}
+
+// TanFlags returns the tangent of a and the exception flags raised.
+func TanFlags(a X80, mode Rounding, precision int) (X80, Flags) {
+	s := pureStatus(mode, precision)
+	return s.tan(a), s.exception
+}
+
+// RoundToIntFlags rounds a to an integer in the rounding mode `mode', like
+// RoundToIntegralExact, and returns the exception flags raised.
+func RoundToIntFlags(a X80, mode Rounding) (X80, Flags) {
+	s := pureStatus(mode, 80)
+	return s.roundFloatX80ToInt(a, mode, true), s.exception
+}
+
+// EqFlags returns a.Eq(b) and the exception flags raised.
+func EqFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.eq(a, b), s.exception
+}
+
+// LeFlags returns a.Le(b) and the exception flags raised.
+func LeFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.le(a, b), s.exception
+}
+
+// LtFlags returns a.Lt(b) and the exception flags raised.
+func LtFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.lt(a, b), s.exception
+}
+
+// EqSignalingFlags returns a.EqSignaling(b) and the exception flags raised.
+func EqSignalingFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.eqSignaling(a, b), s.exception
+}
+
+// LeQuietFlags returns a.LeQuiet(b) and the exception flags raised.
+func LeQuietFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.leQuiet(a, b), s.exception
+}
+
+// LtQuietFlags returns a.LtQuiet(b) and the exception flags raised.
+func LtQuietFlags(a, b X80) (bool, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.ltQuiet(a, b), s.exception
+}
+
+// CompareOrderedFlags returns a.CompareOrdered(b) and the exception flags
+// raised.
+func CompareOrderedFlags(a, b X80) (Ordering, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.compareFloatX80(a, b, false), s.exception
+}
+
+// CompareQuietFlags returns a.CompareQuiet(b) and the exception flags raised.
+func CompareQuietFlags(a, b X80) (Ordering, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.compareFloatX80(a, b, true), s.exception
+}
+
+// MinFlags returns a.Min(b) and the exception flags raised.
+func MinFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.min(a, b), s.exception
+}
+
+// MaxFlags returns a.Max(b) and the exception flags raised.
+func MaxFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.max(a, b), s.exception
+}
+
+// MinNumFlags returns a.MinNum(b) and the exception flags raised.
+func MinNumFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.minNum(a, b), s.exception
+}
+
+// MaxNumFlags returns a.MaxNum(b) and the exception flags raised.
+func MaxNumFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.maxNum(a, b), s.exception
+}
+
+// MinMagFlags returns a.MinMag(b) and the exception flags raised.
+func MinMagFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.minMag(a, b), s.exception
+}
+
+// MaxMagFlags returns a.MaxMag(b) and the exception flags raised.
+func MaxMagFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.maxMag(a, b), s.exception
+}
+
+// MinimumFlags returns a.Minimum(b) and the exception flags raised.
+func MinimumFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.minimum(a, b), s.exception
+}
+
+// MaximumFlags returns a.Maximum(b) and the exception flags raised.
+func MaximumFlags(a, b X80) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.maximum(a, b), s.exception
+}
+
+// Float32ToFloatX80Flags converts a to extended precision and returns the
+// exception flags raised.
+func Float32ToFloatX80Flags(a float32) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.float32ToFloatX80(a), s.exception
+}
+
+// Float64ToFloatX80Flags converts a to extended precision and returns the
+// exception flags raised.
+func Float64ToFloatX80Flags(a float64) (X80, Flags) {
+	s := pureStatus(RoundNearestEven, 80)
+	return s.float64ToFloatX80(a), s.exception
+}
+
+// ToInt32Flags converts a to a 32-bit integer in the rounding mode `mode' and
+// returns the exception flags raised.
+func ToInt32Flags(a X80, mode Rounding) (int32, Flags) {
+	s := pureStatus(mode, 80)
+	return s.toInt32(a), s.exception
+}
+
+// ToInt32RoundZeroFlags converts a to a 32-bit integer, rounding toward zero,
+// and returns the exception flags raised.
+func ToInt32RoundZeroFlags(a X80) (int32, Flags) {
+	s := pureStatus(RoundToZero, 80)
+	return s.toInt32RoundZero(a), s.exception
+}
+
+// ToInt64Flags converts a to a 64-bit integer in the rounding mode `mode' and
+// returns the exception flags raised.
+func ToInt64Flags(a X80, mode Rounding) (int64, Flags) {
+	s := pureStatus(mode, 80)
+	return s.toInt64(a), s.exception
+}
+
+// ToInt64RoundZeroFlags converts a to a 64-bit integer, rounding toward zero,
+// and returns the exception flags raised.
+func ToInt64RoundZeroFlags(a X80) (int64, Flags) {
+	s := pureStatus(RoundToZero, 80)
+	return s.toInt64RoundZero(a), s.exception
+}
+
+// ToFloat32Flags converts a to single precision in the rounding mode `mode'
+// and returns the exception flags raised.
+func ToFloat32Flags(a X80, mode Rounding) (float32, Flags) {
+	s := pureStatus(mode, 80)
+	return s.toFloat32(a), s.exception
+}
+
+// ToFloat64Flags converts a to double precision in the rounding mode `mode'
+// and returns the exception flags raised.
+func ToFloat64Flags(a X80, mode Rounding) (float64, Flags) {
+	s := pureStatus(mode, 80)
+	return s.toFloat64(a), s.exception
+}
@@ -0,0 +1,97 @@
+package float
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestFlagsFunctions(t *testing.T) {
+	third := X80One.Div(Int32ToFloatX80(3))
+	denormal := X80{0x0000, 0x0000000000000001}
+	sNaN := X80{0x7FFF, 0xA000000000000000}
+	tests := []struct {
+		name  string
+		op    func() (any, Flags)
+		want  any
+		flags Flags
+	}{
+		{"Add exact", func() (any, Flags) { return AddFlags(X80One, X80One, RoundNearestEven, 80) }, Int32ToFloatX80(2), 0},
+		{"Sub denormal", func() (any, Flags) { return SubFlags(X80One, denormal, RoundToZero, 80) }, X80{0x3FFE, 0xFFFFFFFFFFFFFFFF}, ExceptionDenormal | ExceptionInexact},
+		{"Mul overflow", func() (any, Flags) {
+			return MulFlags(X80{0x7FFE, 0x8000000000000000}, Int32ToFloatX80(2), RoundToZero, 80)
+		},
+			X80{0x7FFE, 0xFFFFFFFFFFFFFFFF}, ExceptionOverflow | ExceptionInexact},
+		{"Div inexact", func() (any, Flags) { return DivFlags(X80One, Int32ToFloatX80(3), RoundNearestEven, 80) }, third, ExceptionInexact},
+		{"Div exact 64-bit quotient", func() (any, Flags) {
+			return DivFlags(X80{0x3FFF, 0x8000000000000001}, Float64ToFloatX80(1.5), RoundNearestEven, 80)
+		}, X80{0x3FFE, 0xAAAAAAAAAAAAAAAC}, 0},
+		{"Div sticky remainder", func() (any, Flags) {
+			return DivFlags(X80One, X80{0x3FFF, 0xFFFFFFFFFFFFFFFF}, RoundNearestEven, 80)
+		}, X80{0x3FFE, 0x8000000000000001}, ExceptionInexact},
+		{"Div precision 32", func() (any, Flags) { return DivFlags(X80One, Int32ToFloatX80(3), RoundNearestEven, 32) },
+			Float32ToFloatX80(float32(1) / 3), ExceptionInexact},
+		{"Div by zero", func() (any, Flags) { return DivFlags(X80One, X80Zero, RoundNearestEven, 80) }, X80InfPos, ExceptionDivbyzero},
+		{"Rem", func() (any, Flags) { return RemFlags(Int32ToFloatX80(7), Int32ToFloatX80(2), RoundNearestEven, 80) }, X80MinusOne, 0},
+		{"Sqrt", func() (any, Flags) { return SqrtFlags(Int32ToFloatX80(4), RoundNearestEven, 80) }, Int32ToFloatX80(2), 0},
+		{"Ln", func() (any, Flags) { return LnFlags(X80MinusOne, RoundNearestEven, 80) }, X80NaN, ExceptionInvalid},
+		{"RoundToInt", func() (any, Flags) { return RoundToIntFlags(Float64ToFloatX80(2.5), RoundUp) }, Int32ToFloatX80(3), ExceptionInexact},
+		{"Eq quiet NaN", func() (any, Flags) { return EqFlags(X80NaN, X80One) }, false, 0},
+		{"Lt NaN", func() (any, Flags) { return LtFlags(X80NaN, X80One) }, false, ExceptionInvalid},
+		{"LeQuiet signaling NaN", func() (any, Flags) { return LeQuietFlags(sNaN, X80One) }, false, ExceptionInvalid},
+		{"CompareQuiet", func() (any, Flags) { return CompareQuietFlags(X80NaN, X80One) }, Unordered, 0},
+		{"CompareOrdered", func() (any, Flags) { return CompareOrderedFlags(X80NaN, X80One) }, Unordered, ExceptionInvalid},
+		{"MinNum signaling NaN", func() (any, Flags) { return MinNumFlags(sNaN, X80One) }, X80{0x7FFF, 0xE000000000000000}, ExceptionInvalid},
+		{"Maximum", func() (any, Flags) { return MaximumFlags(X80One, X80MinusOne) }, X80One, 0},
+		{"Float64ToFloatX80 denormal", func() (any, Flags) { return Float64ToFloatX80Flags(5e-324) }, X80{0x3BCD, 0x8000000000000000}, ExceptionDenormal},
+		{"ToInt32 NaN", func() (any, Flags) { return ToInt32Flags(X80NaN, RoundNearestEven) }, int32(math.MaxInt32), ExceptionInvalid},
+		{"ToInt64 down", func() (any, Flags) { return ToInt64Flags(Float64ToFloatX80(-1.5), RoundDown) }, int64(-2), ExceptionInexact},
+		{"ToInt32RoundZero", func() (any, Flags) { return ToInt32RoundZeroFlags(Float64ToFloatX80(-1.5)) }, int32(-1), ExceptionInexact},
+		{"ToFloat64 up", func() (any, Flags) { return ToFloat64Flags(third, RoundUp) }, math.Nextafter(1.0/3, 1), ExceptionInexact},
+	}
+	savedMode := RoundingMode
+	RoundingMode = RoundDown
+	RoundingPrecision = 64
+	defer func() {
+		RoundingMode = savedMode
+		RoundingPrecision = 80
+	}()
+	SetExceptionHandler(func(exc Flags) { t.Errorf("exception handler called with %v", exc) })
+	defer SetExceptionHandler(nil)
+	ClearExceptions()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, flags := tt.op()
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if flags != tt.flags {
+				t.Errorf("flags = %v, want %v", flags, tt.flags)
+			}
+		})
+	}
+	if Exception != 0 {
+		t.Errorf("Exception = %v, want 0", Exception)
+	}
+}
+
+func TestFlagsFunctions_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(mode Rounding) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if _, flags := DivFlags(X80One, Int32ToFloatX80(3), mode, 80); flags != ExceptionInexact {
+					t.Errorf("flags = %v", flags)
+					return
+				}
+				if _, flags := AddFlags(X80One, X80One, mode, 80); flags != 0 {
+					t.Errorf("flags = %v", flags)
+					return
+				}
+			}
+		}(Rounding(i % 6))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,1222 @@
+package float
+
+import (
+	"math/big"
+	"sync"
+)
+
+// The transcendental functions are evaluated on math/big floating-point
+// numbers and then rounded to the extended format by roundAndPackFloatX80.
+// The working precision starts with enough guard bits for the magnitude of
+// the operand and is raised until the result can be rounded correctly, so the
+// results are correctly rounded in all rounding modes and precisions, and
+// always inexact unless stated otherwise.
+
+// Relative error bound of the kernels, in bits below the working precision.
+const bigGuardBits = 16
+
+// Returns the exact value of the finite extended double-precision value `a'
+// with a precision of at least `prec' bits.
+func bigFromX80(a X80, prec uint) *big.Float {
+	if prec < 64 {
+		prec = 64
+	}
+	exp := a.exp()
+	if exp == 0 {
+		exp = 1
+	}
+	x := new(big.Float).SetPrec(prec).SetUint64(a.frac())
+	x.SetMantExp(x, exp-0x3FFF-63)
+	if a.sign() {
+		x.Neg(x)
+	}
+	return x
+}
+
+// Returns the binary exponent e of the finite nonzero value `a', with
+// 2^e <= |a| < 2^(e+1).
+func unbiasedExp(a X80) int {
+	exp, sig := a.exp(), a.frac()
+	if exp == 0 {
+		exp = 1
+	}
+	for ; int64(sig) > 0; sig <<= 1 {
+		exp--
+	}
+	return exp - 0x3FFF
+}
+
+// Returns the initial working precision for an operand with binary exponent
+// `e'.  Functions that behave like 1 + c*x^2 or x + c*x^3 near zero need
+// twice the exponent of a tiny operand as extra bits.
+func startPrec(e int) uint {
+	prec := uint(128)
+	if e < 0 {
+		prec += uint(-2 * e)
+	}
+	return prec
+}
+
+// Returns a new value of precision `prec' holding x rounded.
+func bigPrec(prec uint, x *big.Float) *big.Float {
+	return new(big.Float).SetPrec(prec).Set(x)
+}
+
+// Returns the exponent of `x' in the sense of big.Float.MantExp, or a very
+// small number for zero.
+func bigExp(x *big.Float) int {
+	if x.Sign() == 0 {
+		return -1 << 30
+	}
+	return x.MantExp(nil)
+}
+
+var bigConst struct {
+	sync.Mutex
+	prec          uint
+	pi, ln2, ln10 *big.Float
+}
+
+// Returns pi, ln(2) and ln(10) rounded to `prec' bits.  The constants are
+// computed on first use and recomputed when more bits are needed, as for
+// the argument reduction of very large operands.
+func bigConstants(prec uint) (pi, ln2, ln10 *big.Float) {
+	bigConst.Lock()
+	defer bigConst.Unlock()
+	if bigConst.prec < prec {
+		p := prec + 64
+		fixed := func(x *big.Int) *big.Float {
+			f := new(big.Float).SetPrec(p).SetInt(x)
+			return f.SetMantExp(f, -int(p+32))
+		}
+		// pi = 16 atan(1/5) - 4 atan(1/239)
+		t := new(big.Int).Lsh(arctanInv(5, p+32, true), 4)
+		t.Sub(t, new(big.Int).Lsh(arctanInv(239, p+32, true), 2))
+		bigConst.pi = fixed(t)
+		// ln(2) = 2 atanh(1/3), ln(10) = 3 ln(2) + 2 atanh(1/9)
+		l2 := new(big.Int).Lsh(arctanInv(3, p+32, false), 1)
+		l10 := new(big.Int).Mul(l2, big.NewInt(3))
+		l10.Add(l10, new(big.Int).Lsh(arctanInv(9, p+32, false), 1))
+		bigConst.ln2 = fixed(l2)
+		bigConst.ln10 = fixed(l10)
+		bigConst.prec = p
+	}
+	return bigPrec(prec, bigConst.pi), bigPrec(prec, bigConst.ln2), bigPrec(prec, bigConst.ln10)
+}
+
+// Returns atan(1/n), or atanh(1/n) if `alternate' is false, as a fixed-point
+// number with `bits' fraction bits.
+func arctanInv(n int64, bits uint, alternate bool) *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(1), bits)
+	x.Quo(x, big.NewInt(n))
+	n2 := big.NewInt(n * n)
+	sum := new(big.Int).Set(x)
+	t, k := new(big.Int), new(big.Int)
+	for i := int64(3); x.Sign() != 0; i += 2 {
+		x.Quo(x, n2)
+		t.Quo(x, k.SetInt64(i))
+		if alternate && i&3 == 3 {
+			sum.Sub(sum, t)
+		} else {
+			sum.Add(sum, t)
+		}
+	}
+	return sum
+}
+
+// Reports whether the approximation `z' computed with `prec' bits can be
+// rounded correctly to `roundingPrecision', that is, whether the bits after
+// the rounding position are far enough from a rounding boundary.
+func roundable(z *big.Float, prec uint, roundingPrecision int) bool {
+	n := uint(64)
+	switch roundingPrecision {
+	case 32:
+		n = 24
+	case 64:
+		n = 53
+	}
+	if z.Sign() == 0 || prec < n+bigGuardBits+8 {
+		return false
+	}
+	w := prec - bigGuardBits - n
+	m := new(big.Float)
+	z.MantExp(m)
+	m.SetMantExp(m.Abs(m), int(prec-bigGuardBits))
+	i, _ := m.Int(nil)
+	one := big.NewInt(1)
+	tail := new(big.Int).And(i, new(big.Int).Sub(new(big.Int).Lsh(one, w), one))
+	next := new(big.Int).Add(tail, one)
+	half := new(big.Int).Lsh(one, w-1)
+	// Exact, halfway or just below either.
+	return tail.Sign() != 0 && next.BitLen() <= int(w) && tail.Cmp(half) != 0 && next.Cmp(half) != 0
+}
+
+// Rounds `z' to the extended format.  Unless `exact' is set, the result is
+// known to be irrational and is treated as inexact even if `z' happens to be
+// representable.
+func (s *status) packBig(z *big.Float, exact bool) X80 {
+	if z.Sign() == 0 {
+		if !exact {
+			s.raise(ExceptionInexact)
+		}
+		return packFloatX80(z.Signbit(), 0, 0)
+	}
+	m := new(big.Float)
+	exp := z.MantExp(m)
+	m.SetMantExp(m.Abs(m), 128)
+	i, acc := m.Int(nil)
+	zSig0 := new(big.Int).Rsh(i, 64).Uint64()
+	zSig1 := i.And(i, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	if acc != big.Exact || !exact {
+		zSig1 |= 1
+	}
+	zExp := exp - 1 + 0x3FFF
+	if zExp < -0x7000 {
+		zExp = -0x7000
+	} else if zExp > 0xA000 {
+		zExp = 0xA000
+	}
+	return s.roundAndPackFloatX80(s.roundingPrecision, z.Signbit(), zExp, zSig0, zSig1)
+}
+
+// Evaluates the kernel `f', which returns an approximation with a relative
+// error below 2^(bigGuardBits-prec), with increasing precision until the
+// result can be rounded, and returns the inexact result.
+func (s *status) roundTranscendental(prec uint, f func(prec uint) *big.Float) X80 {
+	for i := 0; ; i++ {
+		z := f(prec)
+		if i == 4 || roundable(z, prec, s.roundingPrecision) {
+			return s.packBig(z, false)
+		}
+		prec *= 2
+	}
+}
+
+// Returns true if the number of bits after the binary point of the finite
+// value `a' is zero, that is if `a' is an integer.
+func isIntegral(a X80) bool {
+	if a.frac() == 0 {
+		return true
+	}
+	e := a.exp() - 0x3FFF
+	if e < 0 {
+		return false
+	}
+	return e >= 63 || a.frac()<<(e+1) == 0
+}
+
+// Returns the integer value of the integral operand `a' with |a| < 2^62.
+func integralValue(a X80) int64 {
+	n := int64(a.frac() >> (63 - (a.exp() - 0x3FFF)))
+	if a.sign() {
+		n = -n
+	}
+	return n
+}
+
+// Returns true if `a' is a zero or pseudo-zero, that is a finite value with
+// a zero significand.
+func isZeroValue(a X80) bool {
+	return a.frac() == 0 && a.exp() != 0x7FFF
+}
+
+// Returns a value so large (or small, if `e' is negative) that it overflows
+// (or underflows) in every rounding precision.
+func bigHuge(neg bool, e int) *big.Float {
+	z := new(big.Float).SetMantExp(big.NewFloat(1), e)
+	if neg {
+		z.Neg(z)
+	}
+	return z
+}
+
+// Prepares the operand of a transcendental function: denormal operands are
+// signaled or flushed and NaNs are propagated.  Reports whether the returned
+// value is the result.
+func (s *status) transcendentalOperand(a X80) (X80, bool) {
+	a, _ = s.denormalOperands(a, a)
+	if a.IsNaN() {
+		return s.propagateFloatX80NaN(a, a), true
+	}
+	return a, false
+}
+
+// Kernels.  Each takes the exact operand and a working precision and returns
+// an approximation with a relative error below 2^(bigGuardBits-prec).
+
+// Returns exp(x) for |x| < 2^20.
+func expKernel(x *big.Float, prec uint) *big.Float {
+	w := prec + 32
+	// exp(x) = 2^k exp(r) with r = x - k ln(2) and exp(r) = exp(r / 256)^256
+	var k int64
+	r, scale := bigPrec(w+64, x), 8
+	if e := bigExp(x); e >= 0 {
+		_, ln2, _ := bigConstants(w + 32)
+		t := new(big.Float).SetPrec(w).Quo(x, ln2)
+		if t.Signbit() {
+			t.Sub(t, big.NewFloat(0.5))
+		} else {
+			t.Add(t, big.NewFloat(0.5))
+		}
+		k, _ = t.Int64()
+		t.SetPrec(w + 64).SetInt64(k)
+		r.Sub(r, t.Mul(t, ln2))
+	} else if e < -8 {
+		scale = 0
+	}
+	r.SetMantExp(r, -scale)
+	sum := new(big.Float).SetPrec(w).SetInt64(1)
+	term := new(big.Float).SetPrec(w).SetInt64(1)
+	for n := int64(1); ; n++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		if term.Sign() == 0 || bigExp(term) < bigExp(sum)-int(w) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for i := 0; i < scale; i++ {
+		sum.Mul(sum, sum)
+	}
+	return sum.SetMantExp(sum, int(k))
+}
+
+// Returns exp(x) - 1 for |x| < 2^20.
+func expm1Kernel(x *big.Float, prec uint) *big.Float {
+	w := prec + 32
+	if bigExp(x) < -8 {
+		sum := bigPrec(w, x)
+		term := bigPrec(w, x)
+		for n := int64(2); ; n++ {
+			term.Mul(term, x)
+			term.Quo(term, new(big.Float).SetInt64(n))
+			if term.Sign() == 0 || bigExp(term) < bigExp(sum)-int(w) {
+				break
+			}
+			sum.Add(sum, term)
+		}
+		return sum
+	}
+	z := expKernel(x, w)
+	return z.Sub(z, big.NewFloat(1))
+}
+
+// Returns atanh(y) for |y| <= 1/2.
+func atanhSeries(y *big.Float, w uint) *big.Float {
+	sum := bigPrec(w, y)
+	y2 := new(big.Float).SetPrec(w).Mul(y, y)
+	term := bigPrec(w, y)
+	t := new(big.Float).SetPrec(w)
+	for k := int64(3); ; k += 2 {
+		term.Mul(term, y2)
+		t.Quo(term, new(big.Float).SetInt64(k))
+		if t.Sign() == 0 || bigExp(t) < bigExp(sum)-int(w) {
+			break
+		}
+		sum.Add(sum, t)
+	}
+	return sum
+}
+
+// Returns ln(x) for x > 0.
+func logKernel(x *big.Float, prec uint) *big.Float {
+	w := prec + 32
+	m := new(big.Float).SetPrec(w + uint(x.MinPrec()))
+	e := x.MantExp(m)
+	if m.Cmp(big.NewFloat(0.70710678118654752)) < 0 {
+		m.SetMantExp(m, 1)
+		e--
+	}
+	one := big.NewFloat(1)
+	y := new(big.Float).SetPrec(w).Sub(m, one)
+	y.Quo(y, new(big.Float).SetPrec(w).Add(m, one))
+	z := atanhSeries(y, w)
+	z.SetMantExp(z, 1)
+	if e != 0 {
+		_, ln2, _ := bigConstants(w + 32)
+		t := new(big.Float).SetPrec(w).SetInt64(int64(e))
+		z.Add(z, t.Mul(t, ln2))
+	}
+	return z
+}
+
+// Returns ln(1 + x) for x > -1.
+func log1pKernel(x *big.Float, prec uint) *big.Float {
+	w := prec + 32
+	if bigExp(x) < -8 {
+		// ln(1 + x) = 2 atanh(x / (2 + x))
+		y := new(big.Float).SetPrec(w).Add(x, big.NewFloat(2))
+		y.Quo(x, y)
+		z := atanhSeries(y, w)
+		return z.SetMantExp(z, 1)
+	}
+	t := new(big.Float).SetPrec(w+uint(x.MinPrec())).Add(x, big.NewFloat(1))
+	return logKernel(t, prec)
+}
+
+// Returns atan(x).
+func atanKernel(x *big.Float, prec uint) *big.Float {
+	w := prec + 32
+	a := bigPrec(w, x)
+	a.Abs(a)
+	one := big.NewFloat(1)
+	invert := a.Cmp(one) > 0
+	if invert {
+		a.Quo(one, a)
+	}
+	// Halve the argument four times: atan(a) = 2 atan(a / (1 + sqrt(1 + a^2))).
+	t := new(big.Float).SetPrec(w)
+	for i := 0; i < 4; i++ {
+		t.Mul(a, a)
+		t.Add(t, one)
+		t.Sqrt(t)
+		t.Add(t, one)
+		a.Quo(a, t)
+	}
+	sum := bigPrec(w, a)
+	a2 := new(big.Float).SetPrec(w).Mul(a, a)
+	a2.Neg(a2)
+	term := bigPrec(w, a)
+	for k := int64(3); ; k += 2 {
+		term.Mul(term, a2)
+		t.Quo(term, new(big.Float).SetInt64(k))
+		if t.Sign() == 0 || bigExp(t) < bigExp(sum)-int(w) {
+			break
+		}
+		sum.Add(sum, t)
+	}
+	sum.SetMantExp(sum, 4)
+	if invert {
+		pi, _, _ := bigConstants(w)
+		sum.Sub(pi.SetMantExp(pi, -1), sum)
+	}
+	if x.Signbit() {
+		sum.Neg(sum)
+	}
+	return sum
+}
+
+// The 66-bit approximation of pi/2 with which the x87 reduces the arguments
+// of FSIN, FCOS, FPTAN and FSINCOS.
+var x87HalfPi = func() *big.Float {
+	m, _ := new(big.Int).SetString("3243F6A8885A308D3", 16)
+	f := new(big.Float).SetInt(m)
+	return f.SetMantExp(f, -65)
+}()
+
+// Returns r and k mod 4 with x = k*pi/2 + r and |r| <= pi/4.  If `x87' is
+// set, pi/2 is replaced by x87HalfPi and r is exact.
+func reduceKernel(x *big.Float, prec uint, x87 bool) (*big.Float, int) {
+	w := prec + 32
+	if a := new(big.Float).Abs(x); a.Cmp(big.NewFloat(0.78)) <= 0 {
+		return bigPrec(w, x), 0
+	}
+	e := bigExp(x)
+	p := w + uint(e) + 64
+	halfPi := x87HalfPi
+	if !x87 {
+		pi, _, _ := bigConstants(p)
+		halfPi = pi.SetMantExp(pi, -1)
+	}
+	q := new(big.Float).SetPrec(p).Quo(x, halfPi)
+	if q.Signbit() {
+		q.Sub(q, big.NewFloat(0.5))
+	} else {
+		q.Add(q, big.NewFloat(0.5))
+	}
+	k, _ := q.Int(nil)
+	r := new(big.Float).SetPrec(p + uint(k.BitLen())).SetInt(k)
+	r.Mul(r, halfPi)
+	r.Sub(x, r)
+	return bigPrec(w, r), int(new(big.Int).And(k, big.NewInt(3)).Int64())
+}
+
+// Returns sin(r) and cos(r) for |r| <= pi/4.
+func sinCosKernel(r *big.Float, w uint) (sin, cos *big.Float) {
+	sin = bigPrec(w, r)
+	cos = new(big.Float).SetPrec(w).SetInt64(1)
+	r2 := new(big.Float).SetPrec(w).Mul(r, r)
+	r2.Neg(r2)
+	term := bigPrec(w, r)
+	for n := int64(2); ; n += 2 {
+		// term = (-1)^(n/2) r^(n+1)/(n+1)!, cosine terms r^n/n! in between
+		c := new(big.Float).SetPrec(w).Mul(term, r)
+		c.Quo(c, new(big.Float).SetInt64(n))
+		if c.Sign() == 0 || bigExp(c) < -int(w) {
+			break
+		}
+		cos.Add(cos, c.Neg(c))
+		term.Mul(term, r2)
+		term.Quo(term, new(big.Float).SetInt64(n*(n+1)))
+		sin.Add(sin, term)
+	}
+	return sin, cos
+}
+
+// Returns sin(x) and cos(x), with the argument reduction of the x87 if
+// `x87' is set.
+func sinCos(x *big.Float, prec uint, x87 bool) (sin, cos *big.Float) {
+	r, k := reduceKernel(x, prec, x87)
+	s, c := sinCosKernel(r, prec+32)
+	switch k {
+	case 1:
+		s, c = c, s.Neg(s)
+	case 2:
+		s, c = s.Neg(s), c.Neg(c)
+	case 3:
+		s, c = c.Neg(c), s
+	}
+	return s, c
+}
+
+// Ln returns the natural logarithm of the extended double-precision
+// floating-point value `a'.  The result is correctly rounded.
+func (a X80) Ln() X80 {
+	s := newStatus()
+	return commit(&s, OpLn, s.ln(a), a)
+}
+
+func (s *status) ln(a X80) X80 {
+	return s.logarithm(a, func(x *big.Float, prec uint) *big.Float {
+		return logKernel(x, prec)
+	}, func(a X80) (*big.Float, bool) {
+		return nil, false
+	})
+}
+
+// Log2 returns the binary logarithm of the extended double-precision
+// floating-point value `a'.  The result is exact for powers of two.
+func (a X80) Log2() X80 {
+	s := newStatus()
+	return commit(&s, OpLog2, s.log2(a), a)
+}
+
+func (s *status) log2(a X80) X80 {
+	return s.logarithm(a, func(x *big.Float, prec uint) *big.Float {
+		_, ln2, _ := bigConstants(prec + 32)
+		z := logKernel(x, prec+8)
+		return z.Quo(z, ln2)
+	}, func(a X80) (*big.Float, bool) {
+		if a.frac() != 0x8000000000000000 {
+			return nil, false
+		}
+		return new(big.Float).SetInt64(int64(a.exp() - 0x3FFF)), true
+	})
+}
+
+// Log10 returns the decimal logarithm of the extended double-precision
+// floating-point value `a'.  The result is exact for the powers of ten that
+// are representable exactly.
+func (a X80) Log10() X80 {
+	s := newStatus()
+	return commit(&s, OpLog10, s.log10(a), a)
+}
+
+func (s *status) log10(a X80) X80 {
+	return s.logarithm(a, func(x *big.Float, prec uint) *big.Float {
+		_, _, ln10 := bigConstants(prec + 32)
+		z := logKernel(x, prec+8)
+		return z.Quo(z, ln10)
+	}, func(a X80) (*big.Float, bool) {
+		if !isIntegral(a) || a.exp()-0x3FFF > 62 {
+			return nil, false
+		}
+		n, p := integralValue(a), int64(1)
+		for i := int64(0); i <= 18; i++ {
+			if p == n {
+				return new(big.Float).SetInt64(i), true
+			}
+			p *= 10
+		}
+		return nil, false
+	})
+}
+
+// Evaluates a logarithm with the special cases shared by Ln, Log2 and Log10.
+// `exact' returns the result for operands where it is exactly representable.
+func (s *status) logarithm(a X80, f func(x *big.Float, prec uint) *big.Float, exact func(a X80) (*big.Float, bool)) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if isZeroValue(a) {
+		s.raise(ExceptionDivbyzero)
+		return X80InfNeg
+	}
+	if a.sign() {
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	}
+	if a.IsInf() {
+		return a
+	}
+	if a == X80One {
+		return X80Zero
+	}
+	if z, ok := exact(a); ok {
+		return s.packBig(z, true)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(128, func(prec uint) *big.Float {
+		return f(x, prec)
+	})
+}
+
+// Log1p returns ln(1 + a) for the extended double-precision floating-point
+// value `a', accurately also for `a' near zero.
+func (a X80) Log1p() X80 {
+	s := newStatus()
+	return commit(&s, OpLog1p, s.log1p(a), a)
+}
+
+func (s *status) log1p(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if isZeroValue(a) {
+		return a
+	}
+	if a.IsInf() {
+		if a.sign() {
+			s.raise(ExceptionInvalid)
+			return X80NaN
+		}
+		return a
+	}
+	if a.sign() {
+		switch c := s.compareFloatX80(a, X80MinusOne, true); c {
+		case Equal:
+			s.raise(ExceptionDivbyzero)
+			return X80InfNeg
+		case Less:
+			s.raise(ExceptionInvalid)
+			return X80NaN
+		}
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		return log1pKernel(x, prec)
+	})
+}
+
+// Log2p1 returns log2(1 + a) for the extended double-precision
+// floating-point value `a', accurately also for `a' near zero.  The result
+// is exact if 1 + `a' is a power of two.
+func (a X80) Log2p1() X80 {
+	s := newStatus()
+	return commit(&s, OpLog2p1, s.log2p1(a), a)
+}
+
+func (s *status) log2p1(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if isZeroValue(a) {
+		return a
+	}
+	if a.IsInf() {
+		if a.sign() {
+			s.raise(ExceptionInvalid)
+			return X80NaN
+		}
+		return a
+	}
+	if a.sign() {
+		switch c := s.compareFloatX80(a, X80MinusOne, true); c {
+		case Equal:
+			s.raise(ExceptionDivbyzero)
+			return X80InfNeg
+		case Less:
+			s.raise(ExceptionInvalid)
+			return X80NaN
+		}
+	}
+	x := bigFromX80(a, 64)
+	if e := unbiasedExp(a); e >= -64 && e <= 64 {
+		// 1 + a is exact in 256 bits.
+		t, m := new(big.Float).SetPrec(256).Add(x, big.NewFloat(1)), new(big.Float)
+		if exp := t.MantExp(m); m.Cmp(big.NewFloat(0.5)) == 0 {
+			return s.packBig(new(big.Float).SetInt64(int64(exp-1)), true)
+		}
+	}
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		_, ln2, _ := bigConstants(prec + 32)
+		z := log1pKernel(x, prec+8)
+		return z.Quo(z, ln2)
+	})
+}
+
+// Exp returns e raised to the power of the extended double-precision
+// floating-point value `a'.
+func (a X80) Exp() X80 {
+	s := newStatus()
+	return commit(&s, OpExp, s.exp(a), a)
+}
+
+func (s *status) exp(a X80) X80 {
+	return s.exponential(a, nil, func(a X80) (*big.Float, bool) {
+		return nil, false
+	})
+}
+
+// Exp2 returns 2 raised to the power of the extended double-precision
+// floating-point value `a'.  The result is exact for integral `a'.
+func (a X80) Exp2() X80 {
+	s := newStatus()
+	return commit(&s, OpExp2, s.exp2(a), a)
+}
+
+func (s *status) exp2(a X80) X80 {
+	return s.exponential(a, func(prec uint) *big.Float {
+		_, ln2, _ := bigConstants(prec)
+		return ln2
+	}, func(a X80) (*big.Float, bool) {
+		if !isIntegral(a) {
+			return nil, false
+		}
+		return new(big.Float).SetMantExp(big.NewFloat(1), int(integralValue(a))), true
+	})
+}
+
+// Exp10 returns 10 raised to the power of the extended double-precision
+// floating-point value `a'.  The result is exact for integral `a' whose
+// power of ten is representable exactly.
+func (a X80) Exp10() X80 {
+	s := newStatus()
+	return commit(&s, OpExp10, s.exp10(a), a)
+}
+
+func (s *status) exp10(a X80) X80 {
+	return s.exponential(a, func(prec uint) *big.Float {
+		_, _, ln10 := bigConstants(prec)
+		return ln10
+	}, func(a X80) (*big.Float, bool) {
+		if !isIntegral(a) || a.sign() || integralValue(a) > 27 {
+			return nil, false
+		}
+		z := new(big.Int).Exp(big.NewInt(10), big.NewInt(integralValue(a)), nil)
+		return new(big.Float).SetInt(z), true
+	})
+}
+
+// Evaluates exp(a * ln(base)), where `ln' returns ln(base) or is nil for base
+// e, with the special cases shared by Exp, Exp2 and Exp10.
+func (s *status) exponential(a X80, ln func(prec uint) *big.Float, exact func(a X80) (*big.Float, bool)) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		if a.sign() {
+			return X80Zero
+		}
+		return a
+	}
+	if isZeroValue(a) {
+		return X80One
+	}
+	e := unbiasedExp(a)
+	if e >= 20 {
+		// Overflows or underflows in every rounding precision.
+		if a.sign() {
+			return s.packBig(bigHuge(false, -0x10000), false)
+		}
+		return s.packBig(bigHuge(false, 0x10000), false)
+	}
+	if e < 15 {
+		if z, ok := exact(a); ok {
+			return s.packBig(z, true)
+		}
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		y := x
+		if ln != nil {
+			y = new(big.Float).SetPrec(prec+64).Mul(x, ln(prec+64))
+		}
+		return expKernel(y, prec)
+	})
+}
+
+// Expm1 returns e raised to the power of the extended double-precision
+// floating-point value `a', minus one, accurately also for `a' near zero.
+func (a X80) Expm1() X80 {
+	s := newStatus()
+	return commit(&s, OpExpm1, s.expm1(a), a)
+}
+
+func (s *status) expm1(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		if a.sign() {
+			return X80MinusOne
+		}
+		return a
+	}
+	if isZeroValue(a) {
+		return a
+	}
+	e := unbiasedExp(a)
+	if e >= 20 {
+		if a.sign() {
+			// -1 + tiny
+			return s.packBig(new(big.Float).SetPrec(256).Add(big.NewFloat(-1), bigHuge(false, -200)), false)
+		}
+		return s.packBig(bigHuge(false, 0x10000), false)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		return expm1Kernel(x, prec)
+	})
+}
+
+// Exp2m1 returns 2 raised to the power of the extended double-precision
+// floating-point value `a', minus one, accurately also for `a' near zero.
+// The result is exact for integral `a'.
+func (a X80) Exp2m1() X80 {
+	s := newStatus()
+	return commit(&s, OpExp2m1, s.exp2m1(a), a)
+}
+
+func (s *status) exp2m1(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		if a.sign() {
+			return X80MinusOne
+		}
+		return a
+	}
+	if isZeroValue(a) {
+		return a
+	}
+	e := unbiasedExp(a)
+	if e >= 20 {
+		if a.sign() {
+			// -1 + tiny
+			return s.packBig(new(big.Float).SetPrec(256).Add(big.NewFloat(-1), bigHuge(false, -200)), false)
+		}
+		return s.packBig(bigHuge(false, 0x10000), false)
+	}
+	if isIntegral(a) {
+		// |a| < 2^(e+1), so 2^a - 1 has fewer than 2^(e+1) + 1 bits.
+		z := new(big.Float).SetPrec(2<<uint(e) + 64)
+		z.SetMantExp(big.NewFloat(1), int(integralValue(a)))
+		return s.packBig(z.Sub(z, big.NewFloat(1)), true)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		_, ln2, _ := bigConstants(prec + 64)
+		y := new(big.Float).SetPrec(prec+64).Mul(x, ln2)
+		return expm1Kernel(y, prec)
+	})
+}
+
+// Accuracy selects how Sin, Cos, Tan and Sincos reduce their argument.
+type Accuracy int
+
+const (
+	// AccuracyCorrect reduces the argument with an exact value of pi, so
+	// that the results are correctly rounded for all arguments.
+	AccuracyCorrect Accuracy = iota
+
+	// AccuracyX87 reduces the argument with the 66-bit approximation of pi
+	// of the x87 FSIN, FCOS, FPTAN and FSINCOS instructions.  The result is
+	// correctly rounded for the reduced argument, which reproduces the large
+	// relative errors of the x87 near the zeros of the functions, such as
+	// sin(x) for x close to pi.
+	AccuracyX87
+)
+
+// TranscendentalAccuracy is the argument reduction of Sin, Cos, Tan and
+// Sincos.
+var TranscendentalAccuracy = AccuracyCorrect
+
+// Sin returns the sine of the extended double-precision floating-point value
+// `a'.  The argument is reduced as selected by TranscendentalAccuracy.
+func (a X80) Sin() X80 {
+	s := newStatus()
+	return commit(&s, OpSin, s.sin(a), a)
+}
+
+func (s *status) sin(a X80) X80 {
+	return s.trigonometric(a, func(x *big.Float, prec uint) *big.Float {
+		sin, _ := sinCos(x, prec, s.accuracy == AccuracyX87)
+		return sin
+	})
+}
+
+// Cos returns the cosine of the extended double-precision floating-point
+// value `a'.  The argument is reduced as selected by TranscendentalAccuracy.
+func (a X80) Cos() X80 {
+	s := newStatus()
+	return commit(&s, OpCos, s.cos(a), a)
+}
+
+func (s *status) cos(a X80) X80 {
+	return s.trigonometric(a, func(x *big.Float, prec uint) *big.Float {
+		_, cos := sinCos(x, prec, s.accuracy == AccuracyX87)
+		return cos
+	})
+}
+
+// Sincos returns the sine and the cosine of the extended double-precision
+// floating-point value `a', with the argument reduction of Sin and Cos.  The
+// exception flags of both results are raised together; an event or trap
+// reports the sine as the result.
+func (a X80) Sincos() (sin, cos X80) {
+	s := newStatus()
+	sin, cos = s.sincos(a)
+	return commit(&s, OpSincos, sin, a), cos
+}
+
+func (s *status) sincos(a X80) (sin, cos X80) {
+	return s.sin(a), s.cos(a)
+}
+
+// Tan returns the tangent of the extended double-precision floating-point
+// value `a'.  The argument is reduced as selected by TranscendentalAccuracy.
+func (a X80) Tan() X80 {
+	s := newStatus()
+	return commit(&s, OpTan, s.tan(a), a)
+}
+
+func (s *status) tan(a X80) X80 {
+	return s.trigonometric(a, func(x *big.Float, prec uint) *big.Float {
+		sin, cos := sinCos(x, prec+8, s.accuracy == AccuracyX87)
+		return sin.Quo(sin, cos)
+	})
+}
+
+// Evaluates a trigonometric function with the special cases shared by Sin,
+// Cos and Tan.  Zeros are returned unchanged, except by the cosine.
+func (s *status) trigonometric(a X80, f func(x *big.Float, prec uint) *big.Float) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	}
+	x := bigFromX80(a, 64)
+	if isZeroValue(a) {
+		if z := f(x, 64); z.Cmp(big.NewFloat(1)) == 0 {
+			return X80One
+		}
+		return a
+	}
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		return f(x, prec)
+	})
+}
+
+// Atan returns the arctangent of the extended double-precision floating-point
+// value `a'.
+func (a X80) Atan() X80 {
+	s := newStatus()
+	return commit(&s, OpAtan, s.atan(a), a)
+}
+
+func (s *status) atan(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done || isZeroValue(a) {
+		return a
+	}
+	if a.IsInf() {
+		pi, _, _ := bigConstants(256)
+		pi.SetMantExp(pi, -1)
+		if a.sign() {
+			pi.Neg(pi)
+		}
+		return s.packBig(pi, false)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		return atanKernel(x, prec)
+	})
+}
+
+// Atan2 returns the arctangent of `a' divided by `b' for the extended
+// double-precision floating-point values `a' and `b', in the quadrant given
+// by the signs of both operands, in the range -pi to pi.  The special cases
+// are those of the IEEE Standard: zeros and infinities give exact zeros or
+// inexact multiples of pi/4 that depend on the signs of the operands.
+func (a X80) Atan2(b X80) X80 {
+	s := newStatus()
+	return commit(&s, OpAtan2, s.atan2(a, b), a, b)
+}
+
+func (s *status) atan2(a, b X80) X80 {
+	a, b = s.denormalOperands(a, b)
+	if a.IsNaN() || b.IsNaN() {
+		return s.propagateFloatX80NaN(a, b)
+	}
+	// Returns k*pi/4 with the sign of `a'.
+	angle := func(k int64) X80 {
+		pi, _, _ := bigConstants(256)
+		pi.Mul(pi, big.NewFloat(float64(k)))
+		pi.SetMantExp(pi, -2)
+		if a.sign() {
+			pi.Neg(pi)
+		}
+		return s.packBig(pi, false)
+	}
+	switch {
+	case isZeroValue(a):
+		if b.sign() {
+			return angle(4)
+		}
+		return packFloatX80(a.sign(), 0, 0)
+	case isZeroValue(b):
+		return angle(2)
+	case a.IsInf():
+		switch {
+		case !b.IsInf():
+			return angle(2)
+		case b.sign():
+			return angle(3)
+		}
+		return angle(1)
+	case b.IsInf():
+		if b.sign() {
+			return angle(4)
+		}
+		return packFloatX80(a.sign(), 0, 0)
+	}
+	y, x := bigFromX80(a, 64), bigFromX80(b, 64)
+	return s.roundTranscendental(startPrec(unbiasedExp(a)-unbiasedExp(b)), func(prec uint) *big.Float {
+		z := atanKernel(new(big.Float).SetPrec(prec+32).Quo(y, x), prec)
+		if b.sign() {
+			// atan(a/b) + pi or atan(a/b) - pi
+			pi, _, _ := bigConstants(prec + 64)
+			if a.sign() {
+				pi.Neg(pi)
+			}
+			z.Add(z, pi)
+		}
+		return z
+	})
+}
+
+// Asin returns the arcsine of the extended double-precision floating-point
+// value `a'.
+func (a X80) Asin() X80 {
+	s := newStatus()
+	return commit(&s, OpAsin, s.asin(a), a)
+}
+
+func (s *status) asin(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done || isZeroValue(a) {
+		return a
+	}
+	x := bigFromX80(a, 64)
+	switch c := new(big.Float).Abs(x).Cmp(big.NewFloat(1)); {
+	case a.IsInf() || c > 0:
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	case c == 0:
+		pi, _, _ := bigConstants(256)
+		pi.SetMantExp(pi, -1)
+		if a.sign() {
+			pi.Neg(pi)
+		}
+		return s.packBig(pi, false)
+	}
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		// asin(x) = atan(x / sqrt((1 - x)(1 + x)))
+		w := prec + 32
+		one := big.NewFloat(1)
+		t := new(big.Float).SetPrec(w).Sub(one, x)
+		t.Mul(t, new(big.Float).SetPrec(w).Add(one, x))
+		t.Sqrt(t)
+		return atanKernel(t.Quo(x, t), prec)
+	})
+}
+
+// Acos returns the arccosine of the extended double-precision floating-point
+// value `a'.
+func (a X80) Acos() X80 {
+	s := newStatus()
+	return commit(&s, OpAcos, s.acos(a), a)
+}
+
+func (s *status) acos(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	}
+	x := bigFromX80(a, 64)
+	one := big.NewFloat(1)
+	switch c := new(big.Float).Abs(x).Cmp(one); {
+	case c > 0:
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	case c == 0 && !a.sign():
+		return X80Zero
+	case c == 0:
+		pi, _, _ := bigConstants(256)
+		return s.packBig(pi, false)
+	}
+	return s.roundTranscendental(128, func(prec uint) *big.Float {
+		// acos(x) = 2 atan(sqrt((1 - x) / (1 + x)))
+		w := prec + 32
+		t := new(big.Float).SetPrec(w).Sub(one, x)
+		t.Quo(t, new(big.Float).SetPrec(w).Add(one, x))
+		z := atanKernel(t.Sqrt(t), prec)
+		return z.SetMantExp(z, 1)
+	})
+}
+
+// Atanh returns the inverse hyperbolic tangent of the extended
+// double-precision floating-point value `a'.
+func (a X80) Atanh() X80 {
+	s := newStatus()
+	return commit(&s, OpAtanh, s.atanh(a), a)
+}
+
+func (s *status) atanh(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done || isZeroValue(a) {
+		return a
+	}
+	if a.IsInf() {
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	}
+	x := bigFromX80(a, 64)
+	one := big.NewFloat(1)
+	switch c := new(big.Float).Abs(x).Cmp(one); {
+	case c > 0:
+		s.raise(ExceptionInvalid)
+		return X80NaN
+	case c == 0:
+		s.raise(ExceptionDivbyzero)
+		return packFloatX80(a.sign(), 0x7FFF, 0x8000000000000000)
+	}
+	return s.roundTranscendental(startPrec(unbiasedExp(a)), func(prec uint) *big.Float {
+		// atanh(x) = ln(1 + 2x / (1 - x)) / 2
+		w := prec + 32
+		t := new(big.Float).SetPrec(w).Sub(one, x)
+		t.Quo(new(big.Float).SetPrec(w).SetMantExp(x, 1), t)
+		z := log1pKernel(t, prec)
+		return z.SetMantExp(z, -1)
+	})
+}
+
+// Sinh returns the hyperbolic sine of the extended double-precision
+// floating-point value `a'.
+func (a X80) Sinh() X80 {
+	s := newStatus()
+	return commit(&s, OpSinh, s.sinh(a), a)
+}
+
+func (s *status) sinh(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done || isZeroValue(a) || a.IsInf() {
+		return a
+	}
+	e := unbiasedExp(a)
+	if e >= 20 {
+		return s.packBig(bigHuge(a.sign(), 0x10000), false)
+	}
+	x := bigFromX80(a, 64)
+	x.Abs(x)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		w := prec + 32
+		var t *big.Float
+		if e < 0 {
+			// sinh(x) = (t + t / (t + 1)) / 2 with t = exp(x) - 1
+			t = expm1Kernel(x, w)
+			u := new(big.Float).SetPrec(w).Add(t, big.NewFloat(1))
+			t.Add(t, u.Quo(t, u))
+		} else {
+			// sinh(x) = (t - 1 / t) / 2 with t = exp(x)
+			t = expKernel(x, w)
+			t.Sub(t, new(big.Float).SetPrec(w).Quo(big.NewFloat(1), t))
+		}
+		if a.sign() {
+			t.Neg(t)
+		}
+		return t.SetMantExp(t, -1)
+	})
+}
+
+// Cosh returns the hyperbolic cosine of the extended double-precision
+// floating-point value `a'.
+func (a X80) Cosh() X80 {
+	s := newStatus()
+	return commit(&s, OpCosh, s.cosh(a), a)
+}
+
+func (s *status) cosh(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done {
+		return a
+	}
+	if a.IsInf() {
+		return X80InfPos
+	}
+	if isZeroValue(a) {
+		return X80One
+	}
+	e := unbiasedExp(a)
+	if e >= 20 {
+		return s.packBig(bigHuge(false, 0x10000), false)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		// cosh(x) = (t + 1 / t) / 2 with t = exp(x)
+		w := prec + 32
+		t := expKernel(x, w)
+		u := new(big.Float).SetPrec(w).Quo(big.NewFloat(1), t)
+		t.Add(t, u)
+		return t.SetMantExp(t, -1)
+	})
+}
+
+// Tanh returns the hyperbolic tangent of the extended double-precision
+// floating-point value `a'.
+func (a X80) Tanh() X80 {
+	s := newStatus()
+	return commit(&s, OpTanh, s.tanh(a), a)
+}
+
+func (s *status) tanh(a X80) X80 {
+	a, done := s.transcendentalOperand(a)
+	if done || isZeroValue(a) {
+		return a
+	}
+	if a.IsInf() {
+		return packFloatX80(a.sign(), 0x3FFF, 0x8000000000000000)
+	}
+	e := unbiasedExp(a)
+	if e >= 6 {
+		// |tanh(x)| > 1 - 2^-180
+		z := new(big.Float).SetPrec(256).Sub(big.NewFloat(1), bigHuge(false, -200))
+		if a.sign() {
+			z.Neg(z)
+		}
+		return s.packBig(z, false)
+	}
+	x := bigFromX80(a, 64)
+	return s.roundTranscendental(startPrec(e), func(prec uint) *big.Float {
+		// tanh(x) = t / (t + 2) with t = exp(2x) - 1
+		w := prec + 32
+		t := expm1Kernel(new(big.Float).SetMantExp(x, 1), w)
+		u := new(big.Float).SetPrec(w).Add(t, big.NewFloat(2))
+		return t.Quo(t, u)
+	})
+}
@@ -0,0 +1,197 @@
+package float
+
+import (
+	"math"
+	"testing"
+)
+
+// math.Acos loses accuracy near 1, where 1 - x cancels in its asin.
+func acos(x float64) float64 { return math.Atan2(math.Sqrt((1-x)*(1+x)), x) }
+
+func TestX80_TranscendentalAccuracy(t *testing.T) {
+	funcs := []struct {
+		name string
+		f    func(X80) X80
+		ref  func(float64) float64
+		args []float64
+	}{
+		{"Ln", X80.Ln, math.Log, []float64{0.001, 0.5, 0.999, 1.5, 2, 10, 1e300}},
+		{"Log2", X80.Log2, math.Log2, []float64{0.3, 3, 1000, 1e-300}},
+		{"Log10", X80.Log10, math.Log10, []float64{0.3, 3, 12345, 1e-300}},
+		{"Log1p", X80.Log1p, math.Log1p, []float64{-0.5, 1e-10, 0.25, 3, 1e20}},
+		{"Exp", X80.Exp, math.Exp, []float64{-700, -1, -1e-10, 0.5, 1, 10, 700}},
+		{"Exp2", X80.Exp2, math.Exp2, []float64{-10.5, 0.1, 3.3, 1000.25}},
+		{"Exp10", X80.Exp10, func(x float64) float64 { return math.Pow(10, x) }, []float64{-3.5, 0.5, 2.25}},
+		{"Expm1", X80.Expm1, math.Expm1, []float64{-5, -1e-12, 1e-12, 0.3, 5}},
+		{"Exp2m1", X80.Exp2m1, func(x float64) float64 { return math.Expm1(x * math.Ln2) }, []float64{-5.5, -1e-12, 1e-12, 0.3, 1.5}},
+		{"Log2p1", X80.Log2p1, func(x float64) float64 { return math.Log1p(x) / math.Ln2 }, []float64{-0.3, 1e-10, 0.25, 3, 1e20}},
+		{"Sin", X80.Sin, math.Sin, []float64{-3, 1e-8, 0.5, 1, 2, 100, 1e6}},
+		{"Cos", X80.Cos, math.Cos, []float64{-3, 1e-8, 0.5, 1, 2, 100, 1e6}},
+		{"Tan", X80.Tan, math.Tan, []float64{-1.5, 1e-8, 0.5, 1, 100}},
+		{"Asin", X80.Asin, math.Asin, []float64{-1, -0.5, 1e-9, 0.7, 0.999}},
+		{"Acos", X80.Acos, acos, []float64{-1, -0.5, 1e-9, 0.7, 0.999}},
+		{"Atan", X80.Atan, math.Atan, []float64{-1e10, -1, 1e-9, 0.5, 3, 1e300}},
+		{"Atanh", X80.Atanh, math.Atanh, []float64{-0.9, 1e-9, 0.5, 0.999}},
+		{"Sinh", X80.Sinh, math.Sinh, []float64{-20, -1e-9, 0.5, 3, 700}},
+		{"Cosh", X80.Cosh, math.Cosh, []float64{-20, 1e-9, 0.5, 3, 700}},
+		{"Tanh", X80.Tanh, math.Tanh, []float64{-20, -1e-9, 0.5, 3}},
+	}
+	for _, fn := range funcs {
+		for _, x := range fn.args {
+			got := fn.f(NewFromFloat64(x)).ToFloat64()
+			want := fn.ref(x)
+			if ulp := math.Abs(math.Nextafter(want, math.Inf(1)) - want); math.Abs(got-want) > 2*ulp {
+				t.Errorf("%s(%v) = %v, want %v", fn.name, x, got, want)
+			}
+		}
+	}
+	ClearExceptions()
+}
+
+func TestX80_TranscendentalSpecial(t *testing.T) {
+	negZero := X80{0x8000, 0}
+	tests := []struct {
+		name string
+		got  func() X80
+		want X80
+		exc  Flags
+	}{
+		{"ln(0)", X80Zero.Ln, X80InfNeg, ExceptionDivbyzero},
+		{"ln(-1)", X80MinusOne.Ln, X80NaN, ExceptionInvalid},
+		{"ln(1)", X80One.Ln, X80Zero, 0},
+		{"ln(inf)", X80InfPos.Ln, X80InfPos, 0},
+		{"log2(1024)", Int32ToFloatX80(1024).Log2, Int32ToFloatX80(10), 0},
+		{"log10(1000)", Int32ToFloatX80(1000).Log10, Int32ToFloatX80(3), 0},
+		{"log1p(-1)", X80MinusOne.Log1p, X80InfNeg, ExceptionDivbyzero},
+		{"log1p(-0)", negZero.Log1p, negZero, 0},
+		{"log2p1(-0.75)", X80{0xBFFE, 0xC000000000000000}.Log2p1, Int32ToFloatX80(-2), 0},
+		{"log2p1(7)", Int32ToFloatX80(7).Log2p1, Int32ToFloatX80(3), 0},
+		{"log2p1(-2)", Int32ToFloatX80(-2).Log2p1, X80NaN, ExceptionInvalid},
+		{"exp(0)", X80Zero.Exp, X80One, 0},
+		{"exp(-inf)", X80InfNeg.Exp, X80Zero, 0},
+		{"exp(20000)", Int32ToFloatX80(20000).Exp, X80InfPos, ExceptionOverflow | ExceptionInexact},
+		{"exp(-20000)", Int32ToFloatX80(-20000).Exp, X80Zero, ExceptionUnderflow | ExceptionInexact},
+		{"exp2(-3)", Int32ToFloatX80(-3).Exp2, X80{0x3FFC, 0x8000000000000000}, 0},
+		{"exp10(3)", Int32ToFloatX80(3).Exp10, Int32ToFloatX80(1000), 0},
+		{"expm1(-inf)", X80InfNeg.Expm1, X80MinusOne, 0},
+		{"exp2m1(-2)", Int32ToFloatX80(-2).Exp2m1, X80{0xBFFE, 0xC000000000000000}, 0},
+		{"exp2m1(-0)", negZero.Exp2m1, negZero, 0},
+		{"exp2m1(-inf)", X80InfNeg.Exp2m1, X80MinusOne, 0},
+		{"sin(-0)", negZero.Sin, negZero, 0},
+		{"sin(inf)", X80InfPos.Sin, X80NaN, ExceptionInvalid},
+		{"cos(0)", X80Zero.Cos, X80One, 0},
+		{"tan(-inf)", X80InfNeg.Tan, X80NaN, ExceptionInvalid},
+		{"asin(2)", Int32ToFloatX80(2).Asin, X80NaN, ExceptionInvalid},
+		{"acos(1)", X80One.Acos, X80Zero, 0},
+		{"atan(inf)", X80InfPos.Atan, X80{0x3FFF, 0xC90FDAA22168C235}, ExceptionInexact},
+		{"atan2(-0, -1)", func() X80 { return negZero.Atan2(X80MinusOne) }, X80{0xC000, 0xC90FDAA22168C235}, ExceptionInexact},
+		{"atan2(0, 0)", func() X80 { return X80Zero.Atan2(X80Zero) }, X80Zero, 0},
+		{"atan2(-1, inf)", func() X80 { return X80MinusOne.Atan2(X80InfPos) }, negZero, 0},
+		{"atan2(inf, -inf)", func() X80 { return X80InfPos.Atan2(X80InfNeg) }, X80{0x4000, 0x96CBE3F9990E91A8}, ExceptionInexact},
+		{"atan2(1, -0)", func() X80 { return X80One.Atan2(negZero) }, X80{0x3FFF, 0xC90FDAA22168C235}, ExceptionInexact},
+		{"atanh(1)", X80One.Atanh, X80InfPos, ExceptionDivbyzero},
+		{"atanh(-2)", Int32ToFloatX80(-2).Atanh, X80NaN, ExceptionInvalid},
+		{"sinh(-inf)", X80InfNeg.Sinh, X80InfNeg, 0},
+		{"cosh(-inf)", X80InfNeg.Cosh, X80InfPos, 0},
+		{"tanh(inf)", X80InfPos.Tanh, X80One, 0},
+		{"exp(nan)", X80NaN.Exp, X80NaN, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearExceptions()
+			got := tt.got()
+			if tt.want.IsNaN() {
+				if !got.IsNaN() {
+					t.Errorf("got %v, want NaN", got.Internal())
+				}
+			} else if got != tt.want {
+				t.Errorf("got %v, want %v", got.Internal(), tt.want.Internal())
+			}
+			if Exception != tt.exc {
+				t.Errorf("Exception = %v, want %v", Exception, tt.exc)
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_TranscendentalRounding(t *testing.T) {
+	defer func(m Rounding) { RoundingMode = m }(RoundingMode)
+	tiny := X80{0x3F00, 0x8000000000000000}
+	tests := []struct {
+		name string
+		mode Rounding
+		got  func() X80
+		want X80
+	}{
+		{"exp(tiny) RN", RoundNearestEven, tiny.Exp, X80One},
+		{"exp(tiny) RU", RoundUp, tiny.Exp, X80{0x3FFF, 0x8000000000000001}},
+		{"exp(-tiny) RZ", RoundToZero, X80{0xBF00, 0x8000000000000000}.Exp, X80{0x3FFE, 0xFFFFFFFFFFFFFFFF}},
+		{"cos(tiny) RD", RoundDown, tiny.Cos, X80{0x3FFE, 0xFFFFFFFFFFFFFFFF}},
+		{"sin(tiny) RZ", RoundToZero, tiny.Sin, X80{0x3EFF, 0xFFFFFFFFFFFFFFFF}},
+		{"sin(tiny) RN", RoundNearestEven, tiny.Sin, tiny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundingMode = tt.mode
+			if got := tt.got(); got != tt.want {
+				t.Errorf("got %v, want %v", got.Internal(), tt.want.Internal())
+			}
+		})
+	}
+	ClearExceptions()
+}
+
+func TestX80_Atan2(t *testing.T) {
+	for _, y := range []float64{-3, -1e-20, 0.5, 7} {
+		for _, x := range []float64{-1e10, -2, -1e-5, 0.25, 3, 1e30} {
+			got := NewFromFloat64(y).Atan2(NewFromFloat64(x)).ToFloat64()
+			want := math.Atan2(y, x)
+			if ulp := math.Abs(math.Nextafter(want, math.Inf(1)) - want); math.Abs(got-want) > 2*ulp {
+				t.Errorf("Atan2(%v, %v) = %v, want %v", y, x, got, want)
+			}
+		}
+	}
+	ClearExceptions()
+}
+
+func TestX80_TrigReduction(t *testing.T) {
+	halfPi := X80{0x3FFF, 0xC90FDAA22168C235}
+	tests := []struct {
+		name         string
+		op           func(e *Env) X80
+		correct, x87 X80
+	}{
+		{"Sin(pi)", func(e *Env) X80 { return e.Sin(X80Pi) },
+			X80{0xBFBE, 0xECE675D1FC8F8CBB}, X80{0xBFBF, 0x8000000000000000}},
+		{"Sin(double pi)", func(e *Env) X80 { return e.Sin(Float64ToFloatX80(math.Pi)) },
+			X80{0x3FCA, 0x8D313198A2E03707}, X80{0x3FCA, 0x8D30000000000000}},
+		{"Cos(pi/2)", func(e *Env) X80 { return e.Cos(halfPi) },
+			X80{0xBFBD, 0xECE675D1FC8F8CBB}, X80{0xBFBE, 0x8000000000000000}},
+		{"Tan(pi)", func(e *Env) X80 { return e.Tan(X80Pi) },
+			X80{0x3FBE, 0xECE675D1FC8F8CBB}, X80{0x3FBF, 0x8000000000000000}},
+		{"Sincos(2pi)", func(e *Env) X80 { sin, _ := e.Sincos(X80{0x4001, 0xC90FDAA22168C235}); return sin },
+			X80{0x3FBF, 0xECE675D1FC8F8CBB}, X80{0x3FC0, 0x8000000000000000}},
+		{"Sin(3)", func(e *Env) X80 { return e.Sin(Int32ToFloatX80(3)) },
+			X80{0x3FFC, 0x9081C36DB6AADA79}, X80{0x3FFC, 0x9081C36DB6AADA79}},
+		{"Cos(0.5) unreduced", func(e *Env) X80 { _, cos := e.Sincos(Float64ToFloatX80(0.5)); return cos },
+			X80One.Div(Int32ToFloatX80(2)).Cos(), X80One.Div(Int32ToFloatX80(2)).Cos()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Env{RoundingPrecision: 80}
+			if got := tt.op(&e); got != tt.correct || e.Exception != ExceptionInexact {
+				t.Errorf("AccuracyCorrect: got %v, %v, want %v", got.Internal(), e.Exception, tt.correct.Internal())
+			}
+			e = Env{RoundingPrecision: 80, TranscendentalAccuracy: AccuracyX87}
+			if got := tt.op(&e); got != tt.x87 || e.Exception != ExceptionInexact {
+				t.Errorf("AccuracyX87: got %v, %v, want %v", got.Internal(), e.Exception, tt.x87.Internal())
+			}
+		})
+	}
+	sin, cos := halfPi.Sincos()
+	if sin != X80One || cos != halfPi.Cos() {
+		t.Errorf("Sincos(pi/2) = %v, %v", sin.Internal(), cos.Internal())
+	}
+	ClearExceptions()
+}
@@ -0,0 +1,78 @@
+package x87
+
+import "github.com/jenska/float"
+
+// BCD80 is a packed BCD integer in memory order.  Bytes 0 to 8 hold the
+// eighteen decimal digits, two per byte with the less significant digit in
+// the low nibble and the least significant byte first; bit 7 of byte 9 is
+// the sign, its other bits are ignored on loads and zero on stores:
+//
+//	S 0000000 | D17 D16 | ... | D1 D0
+type BCD80 [10]byte
+
+// BCDIndefinite is the packed BCD indefinite, which FBSTP stores for NaNs,
+// infinities and values of more than eighteen digits if the invalid
+// operation exception is masked.
+var BCDIndefinite = BCD80{0, 0, 0, 0, 0, 0, 0, 0xC0, 0xFF, 0xFF}
+
+// The largest magnitude of a packed BCD integer.
+var maxBCD = float.Int64ToFloatX80(999999999999999999)
+
+// X80 converts `b' to extended double precision, which is always exact.  A
+// negative zero converts to -0.0.  The Intel and AMD manuals leave the result
+// of FBLD undefined for the illegal digits A to F, and no processor documents
+// it.  The conversion follows the FBLD of QEMU (helper_fbld_ST0), which
+// weights every nibble by its decimal position, so that nine bytes of FF load
+// as 1666666666666666665; this has not been checked against silicon.
+func (b BCD80) X80() float.X80 {
+	var n uint64
+	for i := 8; i >= 0; i-- {
+		n = n*10 + uint64(b[i]>>4)
+		n = n*10 + uint64(b[i]&0xF)
+	}
+	high, low := float.Int64ToFloatX80(int64(n)).Bits()
+	return float.NewFromBits(high|uint16(b[9]&0x80)<<8, low)
+}
+
+// NewBCD80 converts `a' to a packed BCD integer, rounding in the rounding
+// mode `mode', and returns the exception flags raised: inexact if `a' was
+// not an integer, and invalid, with the packed BCD indefinite as the result,
+// for NaNs, infinities and results of more than eighteen digits.  Values
+// that round to zero keep their sign.
+func NewBCD80(a float.X80, mode float.Rounding) (b BCD80, exc float.Flags) {
+	if a.IsNaN() || a.IsInf() {
+		return BCDIndefinite, float.ExceptionInvalid
+	}
+	e := float.Env{RoundingMode: mode, RoundingPrecision: 80}
+	r := e.RoundToInt(a)
+	if greater(r, maxBCD) {
+		return BCDIndefinite, float.ExceptionInvalid
+	}
+	n := e.ToInt64(abs(r))
+	for i := 0; i < 9; i++ {
+		b[i] = byte(n%10) | byte(n/10%10)<<4
+		n /= 100
+	}
+	if negative(r) {
+		b[9] = 0x80
+	}
+	return b, e.Exception & float.ExceptionInexact
+}
+
+// FBLD pushes the packed BCD integer `b'.
+func (f *FPU) FBLD(b BCD80) { f.FLD(b.X80()) }
+
+// FBSTP converts ST(0) to a packed BCD integer in the rounding mode of the
+// control word for a store to memory and pops the stack.  NaNs, infinities
+// and values out of range raise IE and deliver the packed BCD indefinite;
+// denormal operands raise no DE.  The result is to be written unless `ok' is
+// false, which an unmasked IE causes.
+func (f *FPU) FBSTP() (b BCD80, ok bool) {
+	ok = f.store(true, func(e *float.Env, a float.X80) bool {
+		var exc float.Flags
+		b, exc = NewBCD80(a, e.RoundingMode)
+		e.Exception |= exc
+		return exc == float.ExceptionInexact && greater(b.X80(), a)
+	})
+	return b, ok
+}
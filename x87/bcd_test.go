@@ -0,0 +1,79 @@
+package x87
+
+import (
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func TestBCD80(t *testing.T) {
+	tests := []struct {
+		name string
+		a    float.X80
+		mode float.Rounding
+		want BCD80
+		exc  float.Flags
+	}{
+		{"integer", float.Int32ToFloatX80(-1234), float.RoundNearestEven, BCD80{0x34, 0x12, 9: 0x80}, 0},
+		{"largest", maxBCD, float.RoundNearestEven,
+			BCD80{0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99}, 0},
+		{"half even", float.NewFromFloat64(2.5), float.RoundNearestEven, BCD80{0x02}, float.ExceptionInexact},
+		{"up", float.NewFromFloat64(2.25), float.RoundUp, BCD80{0x03}, float.ExceptionInexact},
+		{"down", float.NewFromFloat64(-2.25), float.RoundDown, BCD80{0x03, 9: 0x80}, float.ExceptionInexact},
+		{"negative zero", float.NewFromFloat64(-0.25), float.RoundToZero, BCD80{9: 0x80}, float.ExceptionInexact},
+		{"denormal", float.NewFromBits(0, 1), float.RoundUp, BCD80{0x01}, float.ExceptionInexact},
+		{"overflow", float.Int64ToFloatX80(1000000000000000000), float.RoundNearestEven, BCDIndefinite, float.ExceptionInvalid},
+		{"overflow by rounding", float.NewFromFloat64(999999999999999999.5), float.RoundUp, BCDIndefinite, float.ExceptionInvalid},
+		{"infinity", float.X80InfNeg, float.RoundNearestEven, BCDIndefinite, float.ExceptionInvalid},
+		{"NaN", qnanA, float.RoundNearestEven, BCDIndefinite, float.ExceptionInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, exc := NewBCD80(tt.a, tt.mode)
+			if b != tt.want || exc != tt.exc {
+				t.Errorf("NewBCD80() = % X, %v, want % X, %v", b, exc, tt.want, tt.exc)
+			}
+		})
+	}
+	loads := []struct {
+		b    BCD80
+		want float.X80
+	}{
+		{BCD80{0x34, 0x12, 9: 0xFF}, float.Int32ToFloatX80(-1234)},
+		{BCD80{9: 0x80}, negZero},
+		{BCD80{0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99, 0x99}, maxBCD},
+		{BCD80{0x0A, 0xF0}, float.Int32ToFloatX80(15010)},
+		// Illegal digits as loaded by the FBLD of QEMU (helper_fbld_ST0).
+		{BCD80{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, float.Int64ToFloatX80(1666666666666666665)},
+		{BCD80{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, float.Int64ToFloatX80(-1666666666666666665)},
+		{BCD80{0xAB, 0xCD, 0xEF}, float.Int32ToFloatX80(1563411)},
+		{BCDIndefinite, float.NewFromBits(0xC03B, 0xB884E18E05980000)},
+	}
+	for _, tt := range loads {
+		if got := tt.b.X80(); got != tt.want {
+			t.Errorf("% X.X80() = %s, want %s", tt.b, got.Internal(), tt.want.Internal())
+		}
+	}
+}
+
+func TestFPU_FBSTP(t *testing.T) {
+	var f FPU
+	f.FINIT()
+	f.FBLD(BCD80{0x21, 0x43, 0x65, 9: 0x80})
+	f.FLD(float.NewFromFloat64(0.75))
+	if b, ok := f.FBSTP(); !ok || b != (BCD80{0x01}) || f.SW&^TopMask != PE|C1 {
+		t.Errorf("FBSTP() = % X, %v, SW = %#04x", b, ok, f.SW)
+	}
+	if b, ok := f.FBSTP(); !ok || b != (BCD80{0x21, 0x43, 0x65, 9: 0x80}) || f.Top() != 0 {
+		t.Errorf("FBSTP() = % X, %v, Top() = %d", b, ok, f.Top())
+	}
+	if b, ok := f.FBSTP(); !ok || b != BCDIndefinite || f.SW&(SF|IE) != SF|IE {
+		t.Errorf("FBSTP() of empty ST(0) = % X, %v, SW = %#04x", b, ok, f.SW)
+	}
+	f.FINIT()
+	f.FLDCW(masked &^ IM)
+	f.FLD(float.X80InfPos)
+	if _, ok := f.FBSTP(); ok || f.Top() != 7 || f.SW&^TopMask != IE|ES|B {
+		t.Errorf("FBSTP() of infinity unmasked: ok = %v, Top() = %d, SW = %#04x", ok, f.Top(), f.SW)
+	}
+}
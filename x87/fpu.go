@@ -0,0 +1,1076 @@
+// Package x87 models the x87 floating-point unit of the x86 processors on top
+// of the extended double-precision arithmetic of package float.
+//
+// An FPU holds the eight data registers with the control, status and tag
+// words and executes the load, store, arithmetic, compare and transcendental
+// instructions with the register stack, stack fault, condition code and
+// exception semantics of the x87.  Decoding of instructions and effective addresses is
+// left to the emulator, which passes memory operands as values and stack
+// registers ST(i) by their index i:
+//
+//	var fpu x87.FPU
+//	fpu.FINIT()
+//	fpu.FLD(float.X80Pi) // FLD TBYTE PTR [pi]
+//	fpu.FLD1()           // FLD1
+//	fpu.FADDP(1)         // FADDP ST(1),ST(0)
+//	if fpu.Pending() {
+//	    // raise #MF at the next waiting instruction
+//	}
+package x87
+
+import (
+	"math"
+
+	"github.com/jenska/float"
+)
+
+// Control word exception masks and status word exception flags.  They have
+// the values of the corresponding float exception flags.
+const (
+	IM = 0x0001 // invalid operation mask
+	DM = 0x0002 // denormal operand mask
+	ZM = 0x0004 // zero divide mask
+	OM = 0x0008 // overflow mask
+	UM = 0x0010 // underflow mask
+	PM = 0x0020 // precision mask
+
+	IE = 0x0001 // invalid operation
+	DE = 0x0002 // denormal operand
+	ZE = 0x0004 // zero divide
+	OE = 0x0008 // overflow
+	UE = 0x0010 // underflow
+	PE = 0x0020 // precision
+
+	ExceptionMask = 0x003F
+)
+
+// Control word precision and rounding control.
+const (
+	PrecisionMask     = 0x0300
+	PrecisionSingle   = 0x0000
+	PrecisionDouble   = 0x0200
+	PrecisionExtended = 0x0300
+
+	RoundingMask = 0x0C00
+	RoundNearest = 0x0000
+	RoundDown    = 0x0400
+	RoundUp      = 0x0800
+	RoundZero    = 0x0C00
+)
+
+// Status word bits.
+const (
+	SF      = 0x0040 // stack fault
+	ES      = 0x0080 // exception summary
+	C0      = 0x0100
+	C1      = 0x0200
+	C2      = 0x0400
+	TopMask = 0x3800
+	C3      = 0x4000
+	B       = 0x8000 // busy, mirrors ES
+)
+
+// Tag word values of a register.
+const (
+	TagValid   = 0
+	TagZero    = 1
+	TagSpecial = 2 // NaN, infinity, denormal or unsupported format
+	TagEmpty   = 3
+)
+
+// Indefinite is the real indefinite, the default NaN of the x87.
+var Indefinite = float.NewFromBits(0xFFFF, 0xC000000000000000)
+
+// FPU is the programmer's model of an x87.  The zero value has all exceptions
+// unmasked and all registers tagged valid; FINIT puts it into the state after
+// initialization.
+type FPU struct {
+	R  [8]float.X80 // physical data registers R0-R7
+	CW uint16       // control word
+	SW uint16       // status word
+	TW uint16       // tag word, two bits per physical register
+
+	// Accuracy selects the argument reduction of FSIN, FCOS, FPTAN and
+	// FSINCOS.  It is not part of the FPU state and FINIT leaves it alone;
+	// float.AccuracyX87 reproduces the results of the hardware near the
+	// zeros of the functions.
+	Accuracy float.Accuracy
+}
+
+// FINIT sets the control word to 0x037F, which masks all exceptions and
+// selects extended precision and rounding to nearest, clears the status
+// word and tags all registers empty.
+func (f *FPU) FINIT() {
+	f.CW, f.SW, f.TW = 0x037F, 0, 0xFFFF
+}
+
+// FCLEX clears the exception flags, the stack fault flag, the exception
+// summary and the busy bit.
+func (f *FPU) FCLEX() {
+	f.SW &^= ExceptionMask | SF | ES | B
+}
+
+// FLDCW loads the control word `cw'.  Unmasking a pending exception sets the
+// exception summary.
+func (f *FPU) FLDCW(cw uint16) {
+	f.CW = cw
+	f.summarize()
+}
+
+// Pending reports whether an unmasked exception is pending, which the next
+// waiting floating-point instruction takes as #MF.
+func (f *FPU) Pending() bool {
+	return f.SW&ES != 0
+}
+
+var roundingModes = [4]float.Rounding{
+	float.RoundNearestEven, float.RoundDown, float.RoundUp, float.RoundToZero,
+}
+
+// RoundingMode returns the rounding mode selected by the control word.
+func (f *FPU) RoundingMode() float.Rounding {
+	return roundingModes[f.CW&RoundingMask>>10]
+}
+
+// RoundingPrecision returns the rounding precision selected by the control
+// word: 32, 64 or 80 bits.  The reserved encoding selects extended precision.
+func (f *FPU) RoundingPrecision() int {
+	switch f.CW & PrecisionMask {
+	case PrecisionSingle:
+		return 32
+	case PrecisionDouble:
+		return 64
+	}
+	return 80
+}
+
+// Top returns the index of the physical register that is ST(0).
+func (f *FPU) Top() int {
+	return int(f.SW&TopMask) >> 11
+}
+
+// Sets the top of stack pointer to `top'.
+func (f *FPU) setTop(top int) {
+	f.SW = f.SW&^TopMask | uint16(top&7)<<11
+}
+
+// ST returns the contents of ST(i), whether or not it is empty.
+func (f *FPU) ST(i int) float.X80 {
+	return f.R[(f.Top()+i)&7]
+}
+
+// Tag returns the tag of the physical register Rn.
+func (f *FPU) Tag(n int) int {
+	return int(f.TW>>(2*n)) & 3
+}
+
+// Sets the tag of the physical register Rn to `tag'.
+func (f *FPU) setTag(n, tag int) {
+	f.TW = f.TW&^(3<<(2*n)) | uint16(tag)<<(2*n)
+}
+
+// Returns the tag of a register holding `a'.
+func tag(a float.X80) int {
+	high, low := a.Bits()
+	exp := high & 0x7FFF
+	switch {
+	case exp == 0 && low == 0:
+		return TagZero
+	case exp == 0x7FFF || exp == 0 || low>>63 == 0:
+		return TagSpecial
+	}
+	return TagValid
+}
+
+// Writes `a' to ST(i) and tags it.
+func (f *FPU) write(i int, a float.X80) {
+	n := (f.Top() + i) & 7
+	f.R[n] = a
+	f.setTag(n, tag(a))
+}
+
+// Pushes `a' onto the stack.  On stack overflow the masked response pushes
+// the real indefinite, while an unmasked one leaves the stack unchanged.
+func (f *FPU) push(a float.X80) {
+	top := (f.Top() - 1) & 7
+	if f.Tag(top) != TagEmpty {
+		f.SW |= SF | C1
+		if !f.complete(float.ExceptionInvalid) {
+			return
+		}
+		a = Indefinite
+	}
+	f.setTop(top)
+	f.write(0, a)
+}
+
+// Tags ST(0) empty and increments the top of stack pointer.
+func (f *FPU) pop() {
+	f.setTag(f.Top(), TagEmpty)
+	f.setTop(f.Top() + 1)
+}
+
+// Index of the memory operand in the operand lists of instructions.
+const memory = -1
+
+// Returns ST(i), or `m' for the index `memory', and whether it is not empty.
+func (f *FPU) operand(i int, m float.X80) (float.X80, bool) {
+	if i == memory {
+		return m, true
+	}
+	n := (f.Top() + i) & 7
+	return f.R[n], f.Tag(n) != TagEmpty
+}
+
+// Signals a stack underflow.  The masked response writes the real
+// indefinite to ST(dst) and pops the stack if `pop'.
+func (f *FPU) underflow(dst int, pop bool) {
+	f.SW = f.SW&^C1 | SF
+	if !f.complete(float.ExceptionInvalid) {
+		return
+	}
+	f.write(dst, Indefinite)
+	if pop {
+		f.pop()
+	}
+}
+
+// Executes FPTAN, FSINCOS or FXTRACT: ST(0) is replaced by op1(ST(0)) and
+// op2(ST(0)) is pushed.  An empty ST(0) or an occupied ST(7) is a stack
+// fault, to which the masked response is the real indefinite in ST(0) and
+// ST(1).  If `reduce', operands out of the range of FSIN set C2 instead.
+func (f *FPU) push2(reduce bool, op1, op2 func(e *float.Env, a float.X80) float.X80) {
+	a, ok := f.operand(0, float.X80Zero)
+	f.SW &^= C1 | C2
+	if overflow := f.Tag((f.Top()-1)&7) != TagEmpty; !ok || overflow {
+		f.SW |= SF
+		if ok {
+			f.SW |= C1
+		}
+		if f.complete(float.ExceptionInvalid) {
+			f.write(0, Indefinite)
+			f.setTop(f.Top() - 1)
+			f.write(0, Indefinite)
+		}
+		return
+	}
+	if reduce && outOfRange(a) {
+		f.SW |= C2
+		return
+	}
+	run := func(op func(e *float.Env, a float.X80) float.X80) func(e *float.Env) float.X80 {
+		return func(e *float.Env) float.X80 {
+			e.RoundingPrecision = 80
+			return op(e, a)
+		}
+	}
+	y, yExc, _ := f.execute(run(op1), a)
+	z, zExc, up := f.execute(run(op2), a)
+	if y.IsNaN() {
+		z = y
+	}
+	if !f.complete(yExc | zExc) {
+		return
+	}
+	if up {
+		f.SW |= C1
+	}
+	f.write(0, y)
+	f.setTop(f.Top() - 1)
+	f.write(0, z)
+}
+
+// Returns the arithmetic environment selected by the control word.
+// Tininess is detected before rounding, and unmasked overflow and underflow
+// are trapped so that the result is delivered with a wrapped exponent.
+func (f *FPU) env() float.Env {
+	return float.Env{
+		RoundingMode:           f.RoundingMode(),
+		RoundingPrecision:      f.RoundingPrecision(),
+		DetectTininess:         float.TininessBeforeRounding,
+		TrapEnable:             float.Flags(^f.CW & (OM | UM)),
+		SignalDenormal:         true,
+		TranscendentalAccuracy: f.Accuracy,
+	}
+}
+
+// Sets the exception flags `exc' in the status word and updates the
+// exception summary.  Reports whether the destination is written, which
+// unmasked invalid operation, denormal and zero divide exceptions prevent.
+func (f *FPU) complete(exc float.Flags) bool {
+	f.SW |= uint16(exc)
+	f.summarize()
+	return uint16(exc)&^f.CW&(IE|DE|ZE) == 0
+}
+
+// Sets ES and B if an exception flag is set whose exception is unmasked, and
+// clears them otherwise.
+func (f *FPU) summarize() {
+	if f.SW&^f.CW&ExceptionMask != 0 {
+		f.SW |= ES | B
+	} else {
+		f.SW &^= ES | B
+	}
+}
+
+// Reports whether the sign bit of `a' is set.
+func negative(a float.X80) bool {
+	high, _ := a.Bits()
+	return high&0x8000 != 0
+}
+
+// Returns the absolute value of `a'.
+func abs(a float.X80) float.X80 {
+	high, low := a.Bits()
+	return float.NewFromBits(high&0x7FFF, low)
+}
+
+// Reports whether |a| > |b|.
+func greater(a, b float.X80) bool {
+	var e float.Env
+	return e.CompareQuiet(abs(a), abs(b)) == float.Greater
+}
+
+// Returns the NaN `a' with the quiet bit set.
+func quiet(a float.X80) float.X80 {
+	high, low := a.Bits()
+	return float.NewFromBits(high, low|0x4000000000000000)
+}
+
+// Returns the NaN result of an operation on `operands' and whether one of
+// them is a NaN.  A quiet NaN takes precedence over a signaling one;
+// between NaNs of the same kind the larger significand wins.
+func propagate(operands ...float.X80) (z float.X80, ok bool) {
+	for _, a := range operands {
+		if !a.IsNaN() {
+			continue
+		}
+		_, aLow := a.Bits()
+		_, zLow := z.Bits()
+		switch {
+		case !ok:
+			z, ok = a, true
+		case a.IsSignalingNaN() != z.IsSignalingNaN():
+			if z.IsSignalingNaN() {
+				z = a
+			}
+		case aLow > zLow:
+			z = a
+		}
+	}
+	return quiet(z), ok
+}
+
+// Executes `op' in the control word environment and returns the result, the
+// exception flags and whether an inexact result was rounded away from zero.
+// If one of `operands' is a NaN, `op' is not called and the propagated NaN
+// is the result.  Invalid operations deliver the real indefinite, and an
+// unmasked denormal operand exception suppresses all other exceptions.
+func (f *FPU) execute(op func(e *float.Env) float.X80, operands ...float.X80) (z float.X80, exc float.Flags, up bool) {
+	if z, ok := propagate(operands...); ok {
+		for _, a := range operands {
+			if a.IsSignalingNaN() {
+				exc = float.ExceptionInvalid
+			}
+		}
+		return z, exc, false
+	}
+	e := f.env()
+	z = op(&e)
+	exc = e.Exception
+	if exc&float.ExceptionDenormal != 0 && f.CW&DM == 0 {
+		return z, float.ExceptionDenormal, false
+	}
+	if z.IsNaN() {
+		return Indefinite, exc, false
+	}
+	if exc&float.ExceptionInexact != 0 {
+		switch e.RoundingMode {
+		case float.RoundUp:
+			up = !negative(z)
+		case float.RoundDown:
+			up = negative(z)
+		case float.RoundNearestEven:
+			e = f.env()
+			e.RoundingMode = float.RoundToZero
+			up = op(&e) != z
+		}
+	}
+	return z, exc, up
+}
+
+// Sets the exception flags `exc' and, unless an unmasked exception prevents
+// it, writes `z' to ST(dst), sets C1 if `z' was rounded up and pops the
+// stack if `pop'.
+func (f *FPU) deliver(dst int, pop bool, z float.X80, exc float.Flags, up bool) {
+	f.SW &^= C1
+	if !f.complete(exc) {
+		return
+	}
+	if up {
+		f.SW |= C1
+	}
+	f.write(dst, z)
+	if pop {
+		f.pop()
+	}
+}
+
+// Executes ST(0) = op(ST(0)).
+func (f *FPU) monadic(op func(e *float.Env, a float.X80) float.X80) {
+	a, ok := f.operand(0, float.X80Zero)
+	if !ok {
+		f.underflow(0, false)
+		return
+	}
+	z, exc, up := f.execute(func(e *float.Env) float.X80 { return op(e, a) }, a)
+	f.deliver(0, false, z, exc, up)
+}
+
+// Executes ST(dst) = op(x, y) for the operands ST(i) and ST(j), where the
+// index `memory' selects the memory operand `m', and pops the stack if
+// `pop'.
+func (f *FPU) dyadic(dst, i, j int, m float.X80, pop bool, op func(e *float.Env, a, b float.X80) float.X80) {
+	a, aok := f.operand(i, m)
+	b, bok := f.operand(j, m)
+	if !aok || !bok {
+		f.underflow(dst, pop)
+		return
+	}
+	z, exc, up := f.execute(func(e *float.Env) float.X80 { return op(e, a, b) }, a, b)
+	f.deliver(dst, pop, z, exc, up)
+}
+
+// Converts the single-precision value with the bits `u' to extended
+// precision.  Signaling NaNs are quieted and raise the invalid exception.
+func single(e *float.Env, u uint32) float.X80 {
+	if u&0x7FFFFFFF > 0x7F800000 {
+		if u&0x00400000 == 0 {
+			e.Exception |= float.ExceptionInvalid
+		}
+		return float.NewFromBits(uint16(u>>16)&0x8000|0x7FFF, 0xC000000000000000|uint64(u)<<40)
+	}
+	return e.Float32ToFloatX80(math.Float32frombits(u))
+}
+
+// Converts the double-precision value with the bits `u' to extended
+// precision.  Signaling NaNs are quieted and raise the invalid exception.
+func double(e *float.Env, u uint64) float.X80 {
+	if u&0x7FFFFFFFFFFFFFFF > 0x7FF0000000000000 {
+		if u&0x0008000000000000 == 0 {
+			e.Exception |= float.ExceptionInvalid
+		}
+		return float.NewFromBits(uint16(u>>48)&0x8000|0x7FFF, 0xC000000000000000|u<<11)
+	}
+	return e.Float64ToFloatX80(math.Float64frombits(u))
+}
+
+// FLD pushes the extended-precision operand `a'.
+func (f *FPU) FLD(a float.X80) {
+	f.SW &^= C1
+	f.push(a)
+}
+
+// FLD32 converts the single-precision operand `a' to extended precision and
+// pushes it.  Signaling NaNs raise IE and denormals DE.
+func (f *FPU) FLD32(a float32) {
+	e := f.env()
+	z := single(&e, math.Float32bits(a))
+	f.SW &^= C1
+	if f.complete(e.Exception) {
+		f.push(z)
+	}
+}
+
+// FLD64 converts the double-precision operand `a' to extended precision and
+// pushes it.  Signaling NaNs raise IE and denormals DE.
+func (f *FPU) FLD64(a float64) {
+	e := f.env()
+	z := double(&e, math.Float64bits(a))
+	f.SW &^= C1
+	if f.complete(e.Exception) {
+		f.push(z)
+	}
+}
+
+// FLDST pushes a copy of ST(i).
+func (f *FPU) FLDST(i int) {
+	a, ok := f.operand(i, float.X80Zero)
+	f.SW &^= C1
+	if !ok {
+		f.SW |= SF
+		if !f.complete(float.ExceptionInvalid) {
+			return
+		}
+		a = Indefinite
+	}
+	f.push(a)
+}
+
+// FILD pushes the integer `n'.
+func (f *FPU) FILD(n int64) {
+	f.FLD(float.Int64ToFloatX80(n))
+}
+
+// A constant of the x87 constant ROM: the constant truncated to 64 bits and
+// whether it is rounded up when rounding to nearest.
+type constant struct {
+	high uint16
+	mant uint64
+	up   bool
+}
+
+var (
+	constPi  = constant{0x4000, 0xC90FDAA22168C234, true}
+	constL2T = constant{0x4000, 0xD49A784BCD1B8AFE, false}
+	constL2E = constant{0x3FFF, 0xB8AA3B295C17F0BB, true}
+	constLG2 = constant{0x3FFD, 0x9A209A84FBCFF798, true}
+	constLN2 = constant{0x3FFE, 0xB17217F7D1CF79AB, true}
+)
+
+// Pushes the constant `c' rounded in the rounding mode of the control word.
+// The constants are irrational, but no precision exception is raised.
+func (f *FPU) loadConstant(c constant) {
+	mant := c.mant
+	switch f.CW & RoundingMask {
+	case RoundNearest:
+		if c.up {
+			mant++
+		}
+	case RoundUp:
+		mant++
+	}
+	f.FLD(float.NewFromBits(c.high, mant))
+}
+
+// FLD1 pushes +1.0.
+func (f *FPU) FLD1() { f.FLD(float.X80One) }
+
+// FLDZ pushes +0.0.
+func (f *FPU) FLDZ() { f.FLD(float.X80Zero) }
+
+// FLDPI pushes pi.
+func (f *FPU) FLDPI() { f.loadConstant(constPi) }
+
+// FLDL2T pushes log2(10).
+func (f *FPU) FLDL2T() { f.loadConstant(constL2T) }
+
+// FLDL2E pushes log2(e).
+func (f *FPU) FLDL2E() { f.loadConstant(constL2E) }
+
+// FLDLG2 pushes log10(2).
+func (f *FPU) FLDLG2() { f.loadConstant(constLG2) }
+
+// FLDLN2 pushes ln(2).
+func (f *FPU) FLDLN2() { f.loadConstant(constLN2) }
+
+// FST copies ST(0) to ST(i).
+func (f *FPU) FST(i int) { f.copyST(i, false) }
+
+// FSTP copies ST(0) to ST(i) and pops the stack.
+func (f *FPU) FSTP(i int) { f.copyST(i, true) }
+
+// Executes FST or FSTP to a register.
+func (f *FPU) copyST(i int, pop bool) {
+	a, ok := f.operand(0, float.X80Zero)
+	if !ok {
+		f.underflow(i, pop)
+		return
+	}
+	f.SW &^= C1
+	f.write(i, a)
+	if pop {
+		f.pop()
+	}
+}
+
+// Executes a store of ST(0) to memory: `conv' converts ST(0) and reports
+// whether it was rounded away from zero, and the stack is popped if `pop'.
+// An empty ST(0) is a stack underflow, for which the real indefinite is
+// converted.  Reports whether the memory operand is written, which unmasked
+// exceptions other than the precision exception prevent; the stack is then
+// not popped.
+func (f *FPU) store(pop bool, conv func(e *float.Env, a float.X80) bool) bool {
+	a, ok := f.operand(0, float.X80Zero)
+	e := f.env()
+	f.SW &^= C1
+	if !ok {
+		f.SW |= SF
+		e.Exception = float.ExceptionInvalid
+		a = Indefinite
+	}
+	up := conv(&e, a)
+	if e.Exception&float.ExceptionDenormal != 0 && f.CW&DM == 0 {
+		e.Exception = float.ExceptionDenormal
+	}
+	f.complete(e.Exception)
+	if uint16(e.Exception)&^f.CW&(IE|DE|ZE|OE|UE) != 0 {
+		return false
+	}
+	if up {
+		f.SW |= C1
+	}
+	if pop {
+		f.pop()
+	}
+	return true
+}
+
+// FST32 converts ST(0) to single precision for a store to memory and pops
+// the stack if `pop'.  NaNs keep the most significant bits of their
+// significand; signaling NaNs raise IE and are quieted.  The result is to be
+// written unless `ok' is false, which unmasked exceptions other than PE
+// cause.
+func (f *FPU) FST32(pop bool) (z float32, ok bool) {
+	ok = f.store(pop, func(e *float.Env, a float.X80) bool {
+		if a.IsNaN() {
+			high, low := a.Bits()
+			if a.IsSignalingNaN() {
+				e.Exception |= float.ExceptionInvalid
+			}
+			z = math.Float32frombits(uint32(high&0x8000)<<16 | 0x7FC00000 | uint32(low>>40)&0x7FFFFF)
+			return false
+		}
+		z = e.ToFloat32(a)
+		var x float.Env
+		return e.Exception&float.ExceptionInexact != 0 && greater(x.Float32ToFloatX80(z), a)
+	})
+	return z, ok
+}
+
+// FST64 converts ST(0) to double precision for a store to memory and pops
+// the stack if `pop', like FST32.
+func (f *FPU) FST64(pop bool) (z float64, ok bool) {
+	ok = f.store(pop, func(e *float.Env, a float.X80) bool {
+		if a.IsNaN() {
+			high, low := a.Bits()
+			if a.IsSignalingNaN() {
+				e.Exception |= float.ExceptionInvalid
+			}
+			z = math.Float64frombits(uint64(high&0x8000)<<48 | 0x7FF8000000000000 | low>>11&0xFFFFFFFFFFFFF)
+			return false
+		}
+		z = e.ToFloat64(a)
+		var x float.Env
+		return e.Exception&float.ExceptionInexact != 0 && greater(x.Float64ToFloatX80(z), a)
+	})
+	return z, ok
+}
+
+// FST80 returns ST(0) for a store to memory in extended precision and pops
+// the stack if `pop'.  No exceptions but stack underflow are raised.
+func (f *FPU) FST80(pop bool) (z float.X80, ok bool) {
+	ok = f.store(pop, func(e *float.Env, a float.X80) bool {
+		z = a
+		return false
+	})
+	return z, ok
+}
+
+// FIST converts ST(0) to a signed integer of `size' bits, 16, 32 or 64, in
+// the rounding mode of the control word for a store to memory and pops the
+// stack if `pop'.  NaNs, infinities and values out of range raise IE and
+// deliver the integer indefinite, the most negative integer of the size.
+func (f *FPU) FIST(size int, pop bool) (n int64, ok bool) {
+	ok = f.store(pop, func(e *float.Env, a float.X80) bool {
+		limit := int64(1) << (size - 1)
+		n = e.ToInt64(a)
+		if e.Exception&float.ExceptionInvalid != 0 || size < 64 && (n >= limit || n < -limit) {
+			e.Exception = e.Exception&float.ExceptionDenormal | float.ExceptionInvalid
+			n = -limit
+			return false
+		}
+		return e.Exception&float.ExceptionInexact != 0 && greater(float.Int64ToFloatX80(n), a)
+	})
+	return n, ok
+}
+
+// FADD adds the memory operand `m' to ST(0).
+func (f *FPU) FADD(m float.X80) { f.dyadic(0, 0, memory, m, false, (*float.Env).Add) }
+
+// FADDST adds ST(src) to ST(dst), one of which is ST(0).
+func (f *FPU) FADDST(dst, src int) { f.dyadic(dst, dst, src, float.X80Zero, false, (*float.Env).Add) }
+
+// FADDP adds ST(0) to ST(i) and pops the stack.
+func (f *FPU) FADDP(i int) { f.dyadic(i, i, 0, float.X80Zero, true, (*float.Env).Add) }
+
+// FSUB subtracts the memory operand `m' from ST(0).
+func (f *FPU) FSUB(m float.X80) { f.dyadic(0, 0, memory, m, false, (*float.Env).Sub) }
+
+// FSUBST subtracts ST(src) from ST(dst), one of which is ST(0).
+func (f *FPU) FSUBST(dst, src int) { f.dyadic(dst, dst, src, float.X80Zero, false, (*float.Env).Sub) }
+
+// FSUBP subtracts ST(0) from ST(i) and pops the stack.
+func (f *FPU) FSUBP(i int) { f.dyadic(i, i, 0, float.X80Zero, true, (*float.Env).Sub) }
+
+// FSUBR subtracts ST(0) from the memory operand `m' into ST(0).
+func (f *FPU) FSUBR(m float.X80) { f.dyadic(0, memory, 0, m, false, (*float.Env).Sub) }
+
+// FSUBRST subtracts ST(dst) from ST(src) into ST(dst), one of which is
+// ST(0).
+func (f *FPU) FSUBRST(dst, src int) { f.dyadic(dst, src, dst, float.X80Zero, false, (*float.Env).Sub) }
+
+// FSUBRP subtracts ST(i) from ST(0) into ST(i) and pops the stack.
+func (f *FPU) FSUBRP(i int) { f.dyadic(i, 0, i, float.X80Zero, true, (*float.Env).Sub) }
+
+// FMUL multiplies ST(0) by the memory operand `m'.
+func (f *FPU) FMUL(m float.X80) { f.dyadic(0, 0, memory, m, false, (*float.Env).Mul) }
+
+// FMULST multiplies ST(dst) by ST(src), one of which is ST(0).
+func (f *FPU) FMULST(dst, src int) { f.dyadic(dst, dst, src, float.X80Zero, false, (*float.Env).Mul) }
+
+// FMULP multiplies ST(i) by ST(0) and pops the stack.
+func (f *FPU) FMULP(i int) { f.dyadic(i, i, 0, float.X80Zero, true, (*float.Env).Mul) }
+
+// FDIV divides ST(0) by the memory operand `m'.
+func (f *FPU) FDIV(m float.X80) { f.dyadic(0, 0, memory, m, false, (*float.Env).Div) }
+
+// FDIVST divides ST(dst) by ST(src), one of which is ST(0).
+func (f *FPU) FDIVST(dst, src int) { f.dyadic(dst, dst, src, float.X80Zero, false, (*float.Env).Div) }
+
+// FDIVP divides ST(i) by ST(0) and pops the stack.
+func (f *FPU) FDIVP(i int) { f.dyadic(i, i, 0, float.X80Zero, true, (*float.Env).Div) }
+
+// FDIVR divides the memory operand `m' by ST(0) into ST(0).
+func (f *FPU) FDIVR(m float.X80) { f.dyadic(0, memory, 0, m, false, (*float.Env).Div) }
+
+// FDIVRST divides ST(src) by ST(dst) into ST(dst), one of which is ST(0).
+func (f *FPU) FDIVRST(dst, src int) { f.dyadic(dst, src, dst, float.X80Zero, false, (*float.Env).Div) }
+
+// FDIVRP divides ST(0) by ST(i) into ST(i) and pops the stack.
+func (f *FPU) FDIVRP(i int) { f.dyadic(i, 0, i, float.X80Zero, true, (*float.Env).Div) }
+
+// FSQRT computes the square root of ST(0), rounded to the selected precision.
+func (f *FPU) FSQRT() { f.monadic((*float.Env).Sqrt) }
+
+// FRNDINT rounds ST(0) to an integer in the rounding mode of the control
+// word.
+func (f *FPU) FRNDINT() {
+	f.monadic(func(e *float.Env, a float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		return e.RoundToInt(a)
+	})
+}
+
+// FABS clears the sign of ST(0).  No exceptions but stack underflow are
+// raised, even for signaling NaNs.
+func (f *FPU) FABS() { f.sign(func(high uint16) uint16 { return high &^ 0x8000 }) }
+
+// FCHS inverts the sign of ST(0), like FABS.
+func (f *FPU) FCHS() { f.sign(func(high uint16) uint16 { return high ^ 0x8000 }) }
+
+// Executes FABS or FCHS.
+func (f *FPU) sign(op func(high uint16) uint16) {
+	a, ok := f.operand(0, float.X80Zero)
+	if !ok {
+		f.underflow(0, false)
+		return
+	}
+	f.SW &^= C1
+	high, low := a.Bits()
+	f.write(0, float.NewFromBits(op(high), low))
+}
+
+// FXCH exchanges ST(0) and ST(i).  The masked response to a stack underflow
+// exchanges empty registers as real indefinites.
+func (f *FPU) FXCH(i int) {
+	a, aok := f.operand(0, float.X80Zero)
+	b, bok := f.operand(i, float.X80Zero)
+	f.SW &^= C1
+	if !aok || !bok {
+		f.SW |= SF
+		if !f.complete(float.ExceptionInvalid) {
+			return
+		}
+		if !aok {
+			a = Indefinite
+		}
+		if !bok {
+			b = Indefinite
+		}
+	}
+	f.write(0, b)
+	f.write(i, a)
+}
+
+// FPREM computes the partial remainder of ST(0) divided by ST(1) with the
+// quotient truncated towards zero, as the 8087 and 80287 did.
+func (f *FPU) FPREM() { f.remainder((*float.Env).ModQuo) }
+
+// FPREM1 computes the partial IEEE remainder of ST(0) divided by ST(1), with
+// the quotient rounded to nearest.
+func (f *FPU) FPREM1() { f.remainder((*float.Env).RemQuo) }
+
+// Returns the unbiased exponent of the finite nonzero value `a', and false
+// for zeros, infinities and NaNs.
+func exponent(a float.X80) (int, bool) {
+	high, low := a.Bits()
+	if high&0x7FFF == 0x7FFF || low == 0 {
+		return 0, false
+	}
+	exp := int(high&0x7FFF) - 0x3FFF
+	if high&0x7FFF == 0 {
+		exp++
+	}
+	for ; low&0x8000000000000000 == 0; low <<= 1 {
+		exp--
+	}
+	return exp, true
+}
+
+// Executes FPREM or FPREM1.  If the exponents of the operands differ by 64
+// or more, ST(0) is only reduced by a multiple of ST(1) scaled to leave an
+// exponent difference of 32 to 63 and C2 is set; the instruction has to be
+// repeated until C2 is clear.  Otherwise the remainder is complete and the
+// three least significant bits of the quotient are stored in C0, C3 and C1.
+func (f *FPU) remainder(op func(e *float.Env, a, b float.X80) (float.X80, uint64)) {
+	a, aok := f.operand(0, float.X80Zero)
+	b, bok := f.operand(1, float.X80Zero)
+	if !aok || !bok {
+		f.underflow(0, false)
+		return
+	}
+	var q uint64
+	partial := false
+	z, exc, _ := f.execute(func(e *float.Env) float.X80 {
+		e.RoundingPrecision = 80
+		aExp, aFinite := exponent(a)
+		bExp, bFinite := exponent(b)
+		if d := aExp - bExp; aFinite && bFinite && d >= 64 {
+			partial = true
+			z, _ := e.ModQuo(a, e.Scalbn(b, d-32-d%32))
+			return z
+		}
+		var z float.X80
+		z, q = op(e, a, b)
+		return z
+	}, a, b)
+	f.SW &^= C0 | C1 | C2 | C3
+	if !f.complete(exc) {
+		return
+	}
+	switch {
+	case partial:
+		f.SW |= C2
+	case exc&float.ExceptionInvalid == 0:
+		if q&4 != 0 {
+			f.SW |= C0
+		}
+		if q&2 != 0 {
+			f.SW |= C3
+		}
+		if q&1 != 0 {
+			f.SW |= C1
+		}
+	}
+	f.write(0, z)
+}
+
+// Reports whether `a' has an encoding that the x87 does not support since
+// the 80387: an unnormal, pseudo-infinity or pseudo-NaN, whose explicit
+// integer bit is clear although its exponent is not zero.
+func unsupported(a float.X80) bool {
+	high, low := a.Bits()
+	return high&0x7FFF != 0 && low>>63 == 0
+}
+
+// FXTRACT splits ST(0) into its unbiased exponent, which replaces ST(0), and
+// its significand with the sign of ST(0) and an exponent of zero, which is
+// pushed.  Denormals are normalized.  A zero raises ZE and yields -inf as
+// its exponent and itself as its significand; an infinity yields +inf and
+// itself.
+func (f *FPU) FXTRACT() {
+	f.push2(false, func(e *float.Env, a float.X80) float.X80 {
+		exp, _ := extract(e, a)
+		if tag(a) == TagZero {
+			e.Exception |= float.ExceptionDivbyzero
+		}
+		return exp
+	}, func(e *float.Env, a float.X80) float.X80 {
+		_, sig := extract(e, a)
+		return sig
+	})
+}
+
+// Returns the exponent and the significand of `a' for FXTRACT.
+func extract(e *float.Env, a float.X80) (exp, sig float.X80) {
+	high, low := a.Bits()
+	switch {
+	case unsupported(a):
+		e.Exception |= float.ExceptionInvalid
+		return Indefinite, Indefinite
+	case a.IsInf():
+		return float.X80InfPos, a
+	case low == 0:
+		return float.X80InfNeg, a
+	case a.IsDenormal():
+		e.Exception |= float.ExceptionDenormal
+	}
+	n, _ := exponent(a)
+	for ; low>>63 == 0; low <<= 1 {
+	}
+	return float.Int32ToFloatX80(int32(n)), float.NewFromBits(high&0x8000|0x3FFF, low)
+}
+
+// FSCALE multiplies ST(0) by two to the power of ST(1) truncated to an
+// integer.  The result is exact unless it overflows or underflows, in which
+// case it is rounded to extended precision regardless of the precision
+// control.  With OE or UE unmasked, a result too large or too small for the
+// bias adjustment of 24576 to bring into range gets the masked response.  An
+// infinite ST(1) yields an infinity or a zero with the sign of ST(0), except
+// for 0 * 2^+inf and inf * 2^-inf, which are invalid operations.
+func (f *FPU) FSCALE() {
+	f.dyadic(0, 0, 1, float.X80Zero, false, func(e *float.Env, a, b float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		if b.IsDenormal() {
+			e.Exception |= float.ExceptionDenormal
+		}
+		high, low := b.Bits()
+		exp := int(high&0x7FFF) - 0x3FFF
+		var n int
+		switch {
+		case b.IsInf():
+			if a.IsDenormal() {
+				e.Exception |= float.ExceptionDenormal
+			}
+			if tag(a) == TagZero && !negative(b) || a.IsInf() && negative(b) {
+				e.Exception |= float.ExceptionInvalid
+				return Indefinite
+			}
+			aHigh, _ := a.Bits()
+			if negative(b) {
+				return float.NewFromBits(aHigh&0x8000, 0)
+			}
+			return float.NewFromBits(aHigh|0x7FFF, 0x8000000000000000)
+		case exp >= 16:
+			n = 0x10000
+		case exp >= 0:
+			n = int(low >> (63 - exp))
+		}
+		if negative(b) {
+			n = -n
+		}
+		return e.Scalbn(a, n)
+	})
+}
+
+var conditions = [4]uint16{
+	float.Less:      C0,
+	float.Equal:     C3,
+	float.Greater:   0,
+	float.Unordered: C3 | C2 | C0,
+}
+
+// Compares ST(0) with ST(i), where the index `memory' selects the memory
+// operand `m', sets C3, C2 and C0 and pops the stack `pops' times.  Ordered
+// comparisons raise IE for all NaNs, unordered ones only for signaling
+// NaNs.  Unmasked exceptions leave the condition codes and the stack
+// unchanged.
+func (f *FPU) compare(i int, m float.X80, ordered bool, pops int) {
+	a, aok := f.operand(0, m)
+	b, bok := f.operand(i, m)
+	f.SW &^= C1
+	cc := conditions[float.Unordered]
+	if !aok || !bok {
+		f.SW |= SF
+		if !f.complete(float.ExceptionInvalid) {
+			return
+		}
+	} else {
+		e := f.env()
+		var o float.Ordering
+		if ordered {
+			o = e.Compare(a, b)
+		} else {
+			o = e.CompareQuiet(a, b)
+		}
+		if !f.complete(e.Exception) {
+			return
+		}
+		cc = conditions[o]
+	}
+	f.SW = f.SW&^(C3|C2|C0) | cc
+	for ; pops > 0; pops-- {
+		f.pop()
+	}
+}
+
+// FCOM compares ST(0) with the memory operand `m'.
+func (f *FPU) FCOM(m float.X80) { f.compare(memory, m, true, 0) }
+
+// FCOMP compares ST(0) with the memory operand `m' and pops the stack.
+func (f *FPU) FCOMP(m float.X80) { f.compare(memory, m, true, 1) }
+
+// FCOMST compares ST(0) with ST(i).
+func (f *FPU) FCOMST(i int) { f.compare(i, float.X80Zero, true, 0) }
+
+// FCOMPST compares ST(0) with ST(i) and pops the stack.
+func (f *FPU) FCOMPST(i int) { f.compare(i, float.X80Zero, true, 1) }
+
+// FCOMPP compares ST(0) with ST(1) and pops the stack twice.
+func (f *FPU) FCOMPP() { f.compare(1, float.X80Zero, true, 2) }
+
+// FUCOM compares ST(0) with ST(i), raising IE only for signaling NaNs.
+func (f *FPU) FUCOM(i int) { f.compare(i, float.X80Zero, false, 0) }
+
+// FUCOMP compares ST(0) with ST(i) like FUCOM and pops the stack.
+func (f *FPU) FUCOMP(i int) { f.compare(i, float.X80Zero, false, 1) }
+
+// FUCOMPP compares ST(0) with ST(1) like FUCOM and pops the stack twice.
+func (f *FPU) FUCOMPP() { f.compare(1, float.X80Zero, false, 2) }
+
+// FTST compares ST(0) with +0.0.
+func (f *FPU) FTST() { f.compare(memory, float.X80Zero, true, 0) }
+
+// FXAM classifies ST(0) in C3, C2 and C0 and sets C1 to its sign:
+//
+//	C3 C2 C0
+//	 0  0  0  unsupported format
+//	 0  0  1  NaN
+//	 0  1  0  normal finite number
+//	 0  1  1  infinity
+//	 1  0  0  zero
+//	 1  0  1  empty register
+//	 1  1  0  denormal or pseudo-denormal
+//
+// No exceptions are raised, not even for an empty ST(0).
+func (f *FPU) FXAM() {
+	a, ok := f.operand(0, float.X80Zero)
+	f.SW &^= C0 | C1 | C2 | C3
+	if negative(a) {
+		f.SW |= C1
+	}
+	_, low := a.Bits()
+	switch {
+	case !ok:
+		f.SW |= C3 | C0
+	case unsupported(a):
+	case a.IsNaN():
+		f.SW |= C0
+	case a.IsInf():
+		f.SW |= C2 | C0
+	case a.IsDenormal():
+		f.SW |= C3 | C2
+	case low == 0:
+		f.SW |= C3
+	default:
+		f.SW |= C2
+	}
+}
+
+// FFREE tags ST(i) empty.
+func (f *FPU) FFREE(i int) {
+	f.setTag((f.Top()+i)&7, TagEmpty)
+}
+
+// FINCSTP increments the top of stack pointer without changing tags.
+func (f *FPU) FINCSTP() {
+	f.SW &^= C1
+	f.setTop(f.Top() + 1)
+}
+
+// FDECSTP decrements the top of stack pointer without changing tags.
+func (f *FPU) FDECSTP() {
+	f.SW &^= C1
+	f.setTop(f.Top() - 1)
+}
@@ -0,0 +1,364 @@
+package x87
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+var (
+	one      = float.X80One
+	two      = float.Int32ToFloatX80(2)
+	three    = float.Int32ToFloatX80(3)
+	seven    = float.Int32ToFloatX80(7)
+	negZero  = float.NewFromBits(0x8000, 0)
+	maxX80   = float.NewFromBits(0x7FFE, 0xFFFFFFFFFFFFFFFF)
+	snan     = float.NewFromBits(0x7FFF, 0xA000000000000000)
+	qnanA    = float.NewFromBits(0x7FFF, 0xC000000000000001)
+	qnanB    = float.NewFromBits(0xFFFF, 0xC000000000000002)
+	oneThird = float.X80One.Div(float.Int32ToFloatX80(3))
+)
+
+const masked = 0x037F
+
+func TestFPU_Instructions(t *testing.T) {
+	tests := []struct {
+		name  string
+		cw    uint16
+		stack []float.X80 // pushed in order, the last one is ST(0)
+		exec  func(f *FPU)
+		want  float.X80 // ST(0) afterwards
+		sw    uint16    // status word without TOP
+	}{
+		{"FADD", masked, []float.X80{one}, func(f *FPU) { f.FADD(two) }, three, 0},
+		{"FADDP", masked, []float.X80{one, two}, func(f *FPU) { f.FADDP(1) }, three, 0},
+		{"FSUBST", masked, []float.X80{one, seven}, func(f *FPU) { f.FSUBST(0, 1) }, float.Int32ToFloatX80(6), 0},
+		{"FSUBRST", masked, []float.X80{one, seven}, func(f *FPU) { f.FSUBRST(0, 1) }, float.Int32ToFloatX80(-6), 0},
+		{"FSUBP", masked, []float.X80{one, seven}, func(f *FPU) { f.FSUBP(1) }, float.Int32ToFloatX80(-6), 0},
+		{"FSUBRP", masked, []float.X80{one, seven}, func(f *FPU) { f.FSUBRP(1) }, float.Int32ToFloatX80(6), 0},
+		{"FSUBR", masked, []float.X80{one}, func(f *FPU) { f.FSUBR(seven) }, float.Int32ToFloatX80(6), 0},
+		{"FMULST", masked, []float.X80{three, two}, func(f *FPU) { f.FMULST(0, 1) }, float.Int32ToFloatX80(6), 0},
+		{"FDIV rounded up", masked, []float.X80{two}, func(f *FPU) { f.FDIV(three) },
+			float.NewFromBits(0x3FFE, 0xAAAAAAAAAAAAAAAB), PE | C1},
+		{"FDIV single", masked &^ PrecisionMask, []float.X80{one}, func(f *FPU) { f.FDIV(three) },
+			float.NewFromBits(0x3FFD, 0xAAAAAB0000000000), PE | C1},
+		{"FDIV RZ", masked | RoundZero, []float.X80{two}, func(f *FPU) { f.FDIV(three) },
+			float.NewFromBits(0x3FFE, 0xAAAAAAAAAAAAAAAA), PE},
+		{"FDIVR", masked, []float.X80{two}, func(f *FPU) { f.FDIVR(seven) }, float.NewFromFloat64(3.5), 0},
+		{"FDIVRP", masked, []float.X80{one, two}, func(f *FPU) { f.FDIVRP(1) }, two, 0},
+		{"FDIV by zero", masked, []float.X80{one}, func(f *FPU) { f.FDIV(negZero) }, float.X80InfNeg, ZE},
+		{"FDIV by zero unmasked", masked &^ ZM, []float.X80{one}, func(f *FPU) { f.FDIV(negZero) }, one, ZE | ES | B},
+		{"FMUL overflow", masked, []float.X80{maxX80}, func(f *FPU) { f.FMUL(two) }, float.X80InfPos, OE | PE | C1},
+		{"FMUL overflow unmasked", masked &^ OM, []float.X80{maxX80}, func(f *FPU) { f.FMUL(two) },
+			float.NewFromBits(0x1FFF, 0xFFFFFFFFFFFFFFFF), OE | ES | B},
+		{"FMUL exact underflow", masked, []float.X80{float.NewFromBits(0x0001, 0x8000000000000000)},
+			func(f *FPU) { f.FMUL(float.NewFromFloat64(0.5)) }, float.NewFromBits(0, 0x4000000000000000), 0},
+		{"FMUL exact underflow unmasked", masked &^ UM, []float.X80{float.NewFromBits(0x0001, 0x8000000000000000)},
+			func(f *FPU) { f.FMUL(float.NewFromFloat64(0.5)) }, float.NewFromBits(0x6000, 0x8000000000000000), UE | ES | B},
+		{"FADD denormal", masked, []float.X80{one}, func(f *FPU) { f.FADD(float.NewFromBits(0, 1)) }, one, DE | PE},
+		{"FADD denormal unmasked", masked &^ DM, []float.X80{two}, func(f *FPU) { f.FADD(float.NewFromBits(0, 1)) },
+			two, DE | ES | B},
+		{"FSQRT", masked, []float.X80{float.Int32ToFloatX80(9)}, func(f *FPU) { f.FSQRT() }, three, 0},
+		{"FSQRT negative", masked, []float.X80{float.X80MinusOne}, func(f *FPU) { f.FSQRT() }, Indefinite, IE},
+		{"FSQRT negative unmasked", masked &^ IM, []float.X80{float.X80MinusOne}, func(f *FPU) { f.FSQRT() },
+			float.X80MinusOne, IE | ES | B},
+		{"FADD SNaN", masked, []float.X80{one}, func(f *FPU) { f.FADD(snan) },
+			float.NewFromBits(0x7FFF, 0xE000000000000000), IE},
+		{"FADD QNaN before SNaN", masked, []float.X80{snan}, func(f *FPU) { f.FADD(qnanA) }, qnanA, IE},
+		{"FADD larger significand", masked, []float.X80{qnanA}, func(f *FPU) { f.FADD(qnanB) }, qnanB, 0},
+		{"FRNDINT", masked, []float.X80{float.NewFromFloat64(2.5)}, func(f *FPU) { f.FRNDINT() }, two, PE},
+		{"FRNDINT RU", masked | RoundUp, []float.X80{float.NewFromFloat64(2.25)}, func(f *FPU) { f.FRNDINT() },
+			three, PE | C1},
+		{"FRNDINT RD", masked | RoundDown, []float.X80{float.NewFromFloat64(-2.25)}, func(f *FPU) { f.FRNDINT() },
+			float.Int32ToFloatX80(-3), PE | C1},
+		{"FABS SNaN", masked, []float.X80{float.NewFromBits(0xFFFF, 0xA000000000000000)}, func(f *FPU) { f.FABS() }, snan, 0},
+		{"FCHS", masked, []float.X80{float.X80Zero}, func(f *FPU) { f.FCHS() }, negZero, 0},
+		{"FPREM", masked, []float.X80{three, seven}, func(f *FPU) { f.FPREM() }, one, C3},
+		{"FPREM1", masked, []float.X80{three, float.Int32ToFloatX80(8)}, func(f *FPU) { f.FPREM1() },
+			float.X80MinusOne, C3 | C1},
+		{"FPREM quotient bits", masked, []float.X80{three, float.Int32ToFloatX80(20)}, func(f *FPU) { f.FPREM() },
+			two, C0 | C3},
+		{"FPREM by zero", masked, []float.X80{float.X80Zero, seven}, func(f *FPU) { f.FPREM() }, Indefinite, IE},
+		{"FPREM partial", masked, []float.X80{three, float.NewFromBits(0x3FFF+100, 0x8000000000000000)},
+			func(f *FPU) { f.FPREM() }, float.NewFromBits(0x3FFF+64, 0x8000000000000000), C2},
+		{"FSCALE", masked, []float.X80{float.NewFromFloat64(-2.75), three}, func(f *FPU) { f.FSCALE() },
+			float.NewFromFloat64(0.75), 0},
+		{"FSCALE overflow", masked, []float.X80{float.Int32ToFloatX80(20000), one}, func(f *FPU) { f.FSCALE() },
+			float.X80InfPos, OE | PE | C1},
+		{"FSCALE overflow unmasked", masked &^ OM, []float.X80{float.Int32ToFloatX80(20000), one},
+			func(f *FPU) { f.FSCALE() }, float.NewFromBits(0x3FFF+20000-0x6000, 0x8000000000000000), OE | ES | B},
+		{"FSCALE overflow beyond bias adjust", masked &^ OM, []float.X80{float.Int32ToFloatX80(1 << 16), float.NewFromBits(0x7FFE, 0xFFFFFFFFFFFFFFFF)},
+			func(f *FPU) { f.FSCALE() }, float.X80InfPos, OE | PE | C1 | ES | B},
+		{"FSCALE underflow beyond bias adjust", masked &^ UM, []float.X80{float.Int32ToFloatX80(-1 << 16), float.NewFromBits(0x8001, 0x8000000000000000)},
+			func(f *FPU) { f.FSCALE() }, negZero, UE | PE | ES | B},
+		{"FSCALE underflow", masked, []float.X80{float.Int32ToFloatX80(-20000), float.X80MinusOne},
+			func(f *FPU) { f.FSCALE() }, negZero, UE | PE},
+		{"FSCALE by -inf", masked, []float.X80{float.X80InfNeg, seven}, func(f *FPU) { f.FSCALE() }, float.X80Zero, 0},
+		{"FSCALE by +inf", masked, []float.X80{float.X80InfPos, float.X80MinusOne}, func(f *FPU) { f.FSCALE() },
+			float.X80InfNeg, 0},
+		{"FSCALE zero by +inf", masked, []float.X80{float.X80InfPos, float.X80Zero}, func(f *FPU) { f.FSCALE() },
+			Indefinite, IE},
+		{"FSCALE by denormal", masked, []float.X80{float.NewFromBits(0, 1), seven}, func(f *FPU) { f.FSCALE() }, seven, DE},
+		{"FLDPI", masked, nil, func(f *FPU) { f.FLDPI() }, float.X80Pi, 0},
+		{"FLDPI RZ", masked | RoundZero, nil, func(f *FPU) { f.FLDPI() }, float.NewFromBits(0x4000, 0xC90FDAA22168C234), 0},
+		{"FLDL2T", masked, nil, func(f *FPU) { f.FLDL2T() }, float.NewFromBits(0x4000, 0xD49A784BCD1B8AFE), 0},
+		{"FLDL2T RU", masked | RoundUp, nil, func(f *FPU) { f.FLDL2T() }, float.NewFromBits(0x4000, 0xD49A784BCD1B8AFF), 0},
+		{"FLDLG2", masked, nil, func(f *FPU) { f.FLDLG2() }, float.NewFromBits(0x3FFD, 0x9A209A84FBCFF799), 0},
+		{"FLDL2E", masked, nil, func(f *FPU) { f.FLDL2E() }, float.X80Log2E, 0},
+		{"FLDLN2 RD", masked | RoundDown, nil, func(f *FPU) { f.FLDLN2() }, float.NewFromBits(0x3FFE, 0xB17217F7D1CF79AB), 0},
+		{"FLD32 SNaN", masked, nil, func(f *FPU) { f.FLD32(math.Float32frombits(0xFFA00001)) },
+			float.NewFromBits(0xFFFF, 0xE000010000000000), IE},
+		{"FLD32 denormal", masked, nil, func(f *FPU) { f.FLD32(math.Float32frombits(1)) },
+			float.NewFromBits(0x3F6A, 0x8000000000000000), DE},
+		{"FLD64 SNaN unmasked", masked &^ IM, []float.X80{one}, func(f *FPU) { f.FLD64(math.Float64frombits(0x7FF0000000000001)) },
+			one, IE | ES | B},
+		{"FILD", masked, nil, func(f *FPU) { f.FILD(math.MinInt64) }, float.NewFromBits(0xC03E, 0x8000000000000000), 0},
+		{"FLDST", masked, []float.X80{seven, one}, func(f *FPU) { f.FLDST(1) }, seven, 0},
+		{"FXCH", masked, []float.X80{seven, one}, func(f *FPU) { f.FXCH(1) }, seven, 0},
+		{"FSTP", masked, []float.X80{seven, one, two}, func(f *FPU) { f.FSTP(2) }, one, 0},
+		{"stack overflow", masked, []float.X80{one, one, one, one, one, one, one, one}, func(f *FPU) { f.FLD1() },
+			Indefinite, IE | SF | C1},
+		{"stack overflow unmasked", masked &^ IM, []float.X80{one, one, one, one, one, one, one, two},
+			func(f *FPU) { f.FLDZ() }, two, IE | SF | C1 | ES | B},
+		{"stack underflow", masked, []float.X80{one}, func(f *FPU) { f.FADDST(0, 1) }, Indefinite, IE | SF},
+		{"stack underflow unmasked", masked &^ IM, []float.X80{one}, func(f *FPU) { f.FADDST(0, 1) },
+			one, IE | SF | ES | B},
+		{"stack underflow FXCH", masked, []float.X80{one}, func(f *FPU) { f.FXCH(1) }, Indefinite, IE | SF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FINIT()
+			for _, a := range tt.stack {
+				f.FLD(a)
+			}
+			f.FLDCW(tt.cw)
+			tt.exec(&f)
+			if got := f.ST(0); got != tt.want {
+				t.Errorf("ST(0) = %s, want %s", got.Internal(), tt.want.Internal())
+			}
+			if got := f.SW &^ TopMask; got != tt.sw {
+				t.Errorf("SW = %#04x, want %#04x", got, tt.sw)
+			}
+		})
+	}
+}
+
+func TestFPU_Stack(t *testing.T) {
+	var f FPU
+	f.FINIT()
+	f.FLDZ()
+	f.FLD1()
+	f.FLDPI()
+	if f.Top() != 5 || f.TW != 0x43FF {
+		t.Errorf("Top() = %d, TW = %#04x", f.Top(), f.TW)
+	}
+	f.FADDP(2)
+	f.FXCH(1)
+	if f.Top() != 6 || f.ST(0) != float.NewFromBits(0x4000, 0xC90FDAA22168C235) || f.ST(1) != one {
+		t.Errorf("Top() = %d, ST(0) = %s, ST(1) = %s", f.Top(), f.ST(0).Internal(), f.ST(1).Internal())
+	}
+	f.FFREE(1)
+	f.FINCSTP()
+	if f.Top() != 7 || f.Tag(7) != TagEmpty || f.Tag(6) != TagValid {
+		t.Errorf("Top() = %d, TW = %#04x", f.Top(), f.TW)
+	}
+	f.FDECSTP()
+	f.FDECSTP()
+	if f.Top() != 5 || f.Tag(5) != TagEmpty {
+		t.Errorf("Top() = %d, TW = %#04x", f.Top(), f.TW)
+	}
+
+	f.FINIT()
+	f.FLD(float.NewFromBits(0x3FFF+200, 0x8000000000000000))
+	f.FLD(three)
+	f.FXCH(1)
+	for i := 0; i < 10; i++ {
+		f.FPREM()
+		if f.SW&C2 == 0 {
+			break
+		}
+	}
+	if f.SW&C2 != 0 || f.ST(0) != one {
+		t.Errorf("FPREM loop: ST(0) = %s, SW = %#04x", f.ST(0).Internal(), f.SW)
+	}
+}
+
+func TestFPU_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		cw   uint16
+		a, b float.X80 // ST(0), operand
+		exec func(f *FPU, b float.X80)
+		sw   uint16
+	}{
+		{"less", masked, one, two, (*FPU).FCOM, C0},
+		{"equal", masked, negZero, float.X80Zero, (*FPU).FCOM, C3},
+		{"greater", masked, two, one, (*FPU).FCOM, 0},
+		{"unordered", masked, qnanA, one, (*FPU).FCOM, IE | C3 | C2 | C0},
+		{"unordered unmasked", masked &^ IM, qnanA, one, (*FPU).FCOM, IE | ES | B},
+		{"FTST", masked, float.X80MinusOne, float.X80Zero, func(f *FPU, b float.X80) { f.FTST() }, C0},
+		{"FUCOM QNaN", masked, one, qnanA, func(f *FPU, b float.X80) { f.FLD(b); f.FXCH(1); f.FUCOM(1) }, C3 | C2 | C0},
+		{"FUCOM SNaN", masked, one, snan, func(f *FPU, b float.X80) { f.FLD(b); f.FXCH(1); f.FUCOM(1) }, IE | C3 | C2 | C0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FINIT()
+			f.FLD(tt.a)
+			f.FLDCW(tt.cw)
+			tt.exec(&f, tt.b)
+			if got := f.SW &^ TopMask; got != tt.sw {
+				t.Errorf("SW = %#04x, want %#04x", got, tt.sw)
+			}
+		})
+	}
+
+	var f FPU
+	f.FINIT()
+	f.FLD(one)
+	f.FLD(two)
+	f.FLD(three)
+	f.FCOMPP()
+	if f.Top() != 7 || f.ST(0) != one || f.SW&(C3|C2|C0) != 0 {
+		t.Errorf("FCOMPP: Top() = %d, SW = %#04x", f.Top(), f.SW)
+	}
+}
+
+func TestFPU_Store(t *testing.T) {
+	var f FPU
+	f.FINIT()
+	f.FLD(oneThird)
+	if z, ok := f.FST32(false); !ok || z != float32(1.0/3) || f.SW&^TopMask != PE|C1 {
+		t.Errorf("FST32() = %v, %v, SW = %#04x", z, ok, f.SW)
+	}
+	if z, ok := f.FST64(true); !ok || z != 1.0/3 || f.SW&^TopMask != PE || f.Top() != 0 {
+		t.Errorf("FST64() = %v, %v, SW = %#04x", z, ok, f.SW)
+	}
+
+	f.FINIT()
+	f.FLD(float.NewFromFloat64(1e300))
+	if z, ok := f.FST32(false); !ok || !math.IsInf(float64(z), 1) || f.SW&^TopMask != OE|PE|C1 {
+		t.Errorf("FST32() = %v, %v, SW = %#04x", z, ok, f.SW)
+	}
+	f.FLDCW(masked &^ OM)
+	if _, ok := f.FST32(true); ok || f.Top() != 7 || f.SW&^TopMask != OE|PE|ES|B {
+		t.Errorf("FST32() ok = %v, Top() = %d, SW = %#04x", ok, f.Top(), f.SW)
+	}
+
+	f.FINIT()
+	f.FLD(snan)
+	if z, ok := f.FST64(false); !ok || math.Float64bits(z) != 0x7FFC000000000000 || f.SW&^TopMask != IE {
+		t.Errorf("FST64(SNaN) = %#x, %v, SW = %#04x", math.Float64bits(z), ok, f.SW)
+	}
+	if z, ok := f.FST80(true); !ok || z != snan || f.Top() != 0 {
+		t.Errorf("FST80() = %s, %v", z.Internal(), ok)
+	}
+	if z, ok := f.FST80(false); !ok || z != Indefinite || f.SW&^TopMask != IE|SF {
+		t.Errorf("FST80() of empty = %s, %v, SW = %#04x", z.Internal(), ok, f.SW)
+	}
+
+	tests := []struct {
+		name string
+		cw   uint16
+		a    float.X80
+		size int
+		want int64
+		sw   uint16
+	}{
+		{"word", masked, float.NewFromFloat64(-2.5), 16, -2, PE},
+		{"word RD", masked | RoundDown, float.NewFromFloat64(-2.5), 16, -3, PE | C1},
+		{"word overflow", masked, float.NewFromFloat64(40000), 16, -32768, IE},
+		{"long", masked, float.NewFromFloat64(1e9), 32, 1000000000, 0},
+		{"long NaN", masked, qnanA, 32, math.MinInt32, IE},
+		{"quad", masked, float.NewFromFloat64(-1e18), 64, -1e18, 0},
+		{"quad overflow", masked, float.NewFromFloat64(1e19), 64, math.MinInt64, IE},
+		{"quad inf", masked, float.X80InfNeg, 64, math.MinInt64, IE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FINIT()
+			f.FLD(tt.a)
+			f.FLDCW(tt.cw)
+			if n, ok := f.FIST(tt.size, true); !ok || n != tt.want {
+				t.Errorf("FIST() = %d, %v, want %d", n, ok, tt.want)
+			}
+			if got := f.SW &^ TopMask; got != tt.sw {
+				t.Errorf("SW = %#04x, want %#04x", got, tt.sw)
+			}
+		})
+	}
+}
+
+func TestFPU_FXTRACT(t *testing.T) {
+	tests := []struct {
+		name     string
+		cw       uint16
+		stack    []float.X80
+		st0, st1 float.X80
+		sw       uint16
+	}{
+		{"normal", masked, []float.X80{float.Int32ToFloatX80(-10)}, float.NewFromFloat64(-1.25), three, 0},
+		{"denormal", masked, []float.X80{float.NewFromBits(0, 3)}, float.NewFromFloat64(1.5),
+			float.Int32ToFloatX80(-16444), DE},
+		{"zero", masked, []float.X80{negZero}, negZero, float.X80InfNeg, ZE},
+		{"zero unmasked", masked &^ ZM, []float.X80{seven, negZero}, negZero, seven, ZE | ES | B},
+		{"infinity", masked, []float.X80{float.X80InfNeg}, float.X80InfNeg, float.X80InfPos, 0},
+		{"SNaN", masked, []float.X80{snan}, quiet(snan), quiet(snan), IE},
+		{"unnormal", masked, []float.X80{float.NewFromBits(0x4000, 0x4000000000000000)}, Indefinite, Indefinite, IE},
+		{"stack overflow", masked, []float.X80{one, one, one, one, one, one, one, two}, Indefinite, Indefinite,
+			IE | SF | C1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FINIT()
+			for _, a := range tt.stack {
+				f.FLD(a)
+			}
+			f.FLDCW(tt.cw)
+			f.FXTRACT()
+			if st0, st1 := f.ST(0), f.ST(1); st0 != tt.st0 || st1 != tt.st1 {
+				t.Errorf("ST(0), ST(1) = %s, %s, want %s, %s", st0.Internal(), st1.Internal(), tt.st0.Internal(), tt.st1.Internal())
+			}
+			if got := f.SW &^ TopMask; got != tt.sw {
+				t.Errorf("SW = %#04x, want %#04x", got, tt.sw)
+			}
+		})
+	}
+}
+
+func TestFPU_FXAM(t *testing.T) {
+	tests := []struct {
+		a    float.X80
+		want uint16
+	}{
+		{float.NewFromBits(0x4000, 0x4000000000000000), 0},
+		{float.NewFromBits(0x7FFF, 0x4000000000000000), 0},
+		{qnanB, C1 | C0},
+		{snan, C0},
+		{float.X80MinusOne, C1 | C2},
+		{float.X80InfPos, C2 | C0},
+		{negZero, C1 | C3},
+		{float.NewFromBits(0, 1), C3 | C2},
+		{float.NewFromBits(0x8000, 0x8000000000000000), C1 | C3 | C2},
+	}
+	for _, tt := range tests {
+		var f FPU
+		f.FINIT()
+		f.FLD(tt.a)
+		f.SW |= C0 | C1 | C2 | C3
+		f.FXAM()
+		if got := f.SW &^ TopMask; got != tt.want {
+			t.Errorf("FXAM(%s): SW = %#04x, want %#04x", tt.a.Internal(), got, tt.want)
+		}
+	}
+	var f FPU
+	f.FINIT()
+	f.FXAM()
+	if f.SW != C3|C0 {
+		t.Errorf("FXAM(empty): SW = %#04x", f.SW)
+	}
+}
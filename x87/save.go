@@ -0,0 +1,265 @@
+package x87
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/jenska/float"
+)
+
+// Sizes of the save images.  XSAVESize covers the legacy region and the
+// XSAVE header, which hold the x87 and SSE state.
+const (
+	FSAVESize  = 108
+	FXSAVESize = 512
+	XSAVESize  = 576
+)
+
+// XSAVE state-component bitmap bits of the x87 and SSE state.
+const (
+	XStateX87 = 0x1
+	XStateSSE = 0x2
+)
+
+// ErrImageSize is returned for save images shorter or longer than their
+// format.
+var ErrImageSize = errors.New("x87: invalid save image size")
+
+// FSAVEImage is the 108-byte image that FSAVE stores and FRSTOR loads in
+// 32-bit protected mode: the environment followed by ST(0) to ST(7) in
+// stack order, ten bytes each.
+type FSAVEImage struct {
+	FCW uint16 // control word
+	FSW uint16 // status word
+	FTW uint16 // full tag word
+	FIP uint32 // instruction pointer offset
+	FCS uint16 // instruction pointer selector
+	FOP uint16 // last opcode, 11 bits
+	FDP uint32 // data pointer offset
+	FDS uint16 // data pointer selector
+	ST  [8]float.X80
+}
+
+// FXSAVEImage is the 512-byte image that FXSAVE stores and FXRSTOR loads, as
+// found in core dumps and ptrace snapshots, and the legacy region of an
+// XSAVE area.  FIP and FDP are decoded as in the 64-bit format; in the
+// 32-bit format their bits 32 to 47 hold the selectors FCS and FDS.  The
+// registers ST(0) to ST(7) are stored in stack order, padded to sixteen
+// bytes each.
+type FXSAVEImage struct {
+	FCW       uint16 // control word
+	FSW       uint16 // status word
+	FTW       uint8  // abridged tag word
+	FOP       uint16 // last opcode, 11 bits
+	FIP       uint64 // instruction pointer
+	FDP       uint64 // data pointer
+	MXCSR     uint32
+	MXCSRMask uint32
+	ST        [8]float.X80
+	XMM       [16][16]byte
+}
+
+// Stores `a' in the x87 memory format: the significand followed by the sign
+// and the exponent, little endian.
+func putX80(b []byte, a float.X80) {
+	high, low := a.Bits()
+	binary.LittleEndian.PutUint64(b, low)
+	binary.LittleEndian.PutUint16(b[8:], high)
+}
+
+// Loads a value in the x87 memory format.
+func getX80(b []byte) float.X80 {
+	return float.NewFromBits(binary.LittleEndian.Uint16(b[8:]), binary.LittleEndian.Uint64(b))
+}
+
+// AbridgedTag converts the full tag word `tw' to the abridged tag word of
+// FXSAVE, in which bit n is set if the physical register Rn is not empty.
+func AbridgedTag(tw uint16) uint8 {
+	var ftw uint8
+	for n := 0; n < 8; n++ {
+		if tw>>(2*n)&3 != TagEmpty {
+			ftw |= 1 << n
+		}
+	}
+	return ftw
+}
+
+// FullTag converts the abridged tag word `ftw' to a full tag word.  The tags
+// of the registers that are not empty are computed from their contents,
+// given in stack order in `st', whose physical register numbers follow from
+// the top of stack pointer in the status word `sw'.
+func FullTag(ftw uint8, sw uint16, st [8]float.X80) uint16 {
+	top := int(sw&TopMask) >> 11
+	var tw uint16
+	for i, a := range st {
+		n := (top + i) & 7
+		t := TagEmpty
+		if ftw&(1<<n) != 0 {
+			t = tag(a)
+		}
+		tw |= uint16(t) << (2 * n)
+	}
+	return tw
+}
+
+// MarshalBinary returns the image as FSAVE stores it in memory.
+func (img FSAVEImage) MarshalBinary() ([]byte, error) {
+	b := make([]byte, FSAVESize)
+	binary.LittleEndian.PutUint16(b[0:], img.FCW)
+	binary.LittleEndian.PutUint16(b[4:], img.FSW)
+	binary.LittleEndian.PutUint16(b[8:], img.FTW)
+	binary.LittleEndian.PutUint32(b[12:], img.FIP)
+	binary.LittleEndian.PutUint16(b[16:], img.FCS)
+	binary.LittleEndian.PutUint16(b[18:], img.FOP&0x7FF)
+	binary.LittleEndian.PutUint32(b[20:], img.FDP)
+	binary.LittleEndian.PutUint16(b[24:], img.FDS)
+	for i, a := range img.ST {
+		putX80(b[28+10*i:], a)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes an image stored by FSAVE.  The reserved bits are
+// ignored.
+func (img *FSAVEImage) UnmarshalBinary(b []byte) error {
+	if len(b) != FSAVESize {
+		return ErrImageSize
+	}
+	*img = FSAVEImage{
+		FCW: binary.LittleEndian.Uint16(b[0:]),
+		FSW: binary.LittleEndian.Uint16(b[4:]),
+		FTW: binary.LittleEndian.Uint16(b[8:]),
+		FIP: binary.LittleEndian.Uint32(b[12:]),
+		FCS: binary.LittleEndian.Uint16(b[16:]),
+		FOP: binary.LittleEndian.Uint16(b[18:]) & 0x7FF,
+		FDP: binary.LittleEndian.Uint32(b[20:]),
+		FDS: binary.LittleEndian.Uint16(b[24:]),
+	}
+	for i := range img.ST {
+		img.ST[i] = getX80(b[28+10*i:])
+	}
+	return nil
+}
+
+// MarshalBinary returns the image as FXSAVE stores it in memory, with the
+// reserved bytes zero.
+func (img FXSAVEImage) MarshalBinary() ([]byte, error) {
+	b := make([]byte, FXSAVESize)
+	binary.LittleEndian.PutUint16(b[0:], img.FCW)
+	binary.LittleEndian.PutUint16(b[2:], img.FSW)
+	b[4] = img.FTW
+	binary.LittleEndian.PutUint16(b[6:], img.FOP&0x7FF)
+	binary.LittleEndian.PutUint64(b[8:], img.FIP)
+	binary.LittleEndian.PutUint64(b[16:], img.FDP)
+	binary.LittleEndian.PutUint32(b[24:], img.MXCSR)
+	binary.LittleEndian.PutUint32(b[28:], img.MXCSRMask)
+	for i, a := range img.ST {
+		putX80(b[32+16*i:], a)
+	}
+	for i, x := range img.XMM {
+		copy(b[160+16*i:], x[:])
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes an image stored by FXSAVE.  The reserved bytes,
+// including the padding of the registers, are ignored.
+func (img *FXSAVEImage) UnmarshalBinary(b []byte) error {
+	if len(b) != FXSAVESize {
+		return ErrImageSize
+	}
+	*img = FXSAVEImage{
+		FCW:       binary.LittleEndian.Uint16(b[0:]),
+		FSW:       binary.LittleEndian.Uint16(b[2:]),
+		FTW:       b[4],
+		FOP:       binary.LittleEndian.Uint16(b[6:]) & 0x7FF,
+		FIP:       binary.LittleEndian.Uint64(b[8:]),
+		FDP:       binary.LittleEndian.Uint64(b[16:]),
+		MXCSR:     binary.LittleEndian.Uint32(b[24:]),
+		MXCSRMask: binary.LittleEndian.Uint32(b[28:]),
+	}
+	for i := range img.ST {
+		img.ST[i] = getX80(b[32+16*i:])
+	}
+	for i := range img.XMM {
+		copy(img.XMM[i][:], b[160+16*i:])
+	}
+	return nil
+}
+
+// MarshalXSAVE returns the image as the legacy region of an XSAVE area in
+// the standard format, followed by the XSAVE header, which marks the x87
+// and SSE state as saved.
+func (img FXSAVEImage) MarshalXSAVE() []byte {
+	b, _ := img.MarshalBinary()
+	b = append(b, make([]byte, XSAVESize-FXSAVESize)...)
+	binary.LittleEndian.PutUint64(b[FXSAVESize:], XStateX87|XStateSSE)
+	return b
+}
+
+// UnmarshalXSAVE decodes the x87 and SSE state of an XSAVE area of at least
+// XSAVESize bytes.  Components whose bit in the XSTATE_BV field of the
+// header is clear are in their initial configuration, which XSAVE does not
+// store: FCW 0x037F with all other x87 state zero, and zero XMM registers.
+func (img *FXSAVEImage) UnmarshalXSAVE(b []byte) error {
+	if len(b) < XSAVESize {
+		return ErrImageSize
+	}
+	if err := img.UnmarshalBinary(b[:FXSAVESize]); err != nil {
+		return err
+	}
+	bv := binary.LittleEndian.Uint64(b[FXSAVESize:])
+	if bv&XStateX87 == 0 {
+		img.FCW, img.FSW, img.FTW, img.FOP, img.FIP, img.FDP = 0x037F, 0, 0, 0, 0, 0
+		img.ST = [8]float.X80{}
+	}
+	if bv&XStateSSE == 0 {
+		img.XMM = [16][16]byte{}
+	}
+	return nil
+}
+
+// FSAVE stores the control, status and tag words and the registers in
+// `img' and initializes the FPU as FINIT does.  The instruction and data
+// pointers and the opcode are left to the emulator.
+func (f *FPU) FSAVE(img *FSAVEImage) {
+	img.FCW, img.FSW, img.FTW = f.CW, f.SW, f.TW
+	for i := range img.ST {
+		img.ST[i] = f.ST(i)
+	}
+	f.FINIT()
+}
+
+// FRSTOR loads the control, status and tag words and the registers from
+// `img'.  Only empty tags are taken from the tag word; the tags of the other
+// registers are computed from their contents.
+func (f *FPU) FRSTOR(img *FSAVEImage) {
+	f.CW, f.SW = img.FCW, img.FSW
+	f.restore(AbridgedTag(img.FTW), img.ST)
+}
+
+// FXSAVE stores the x87 state in `img' with the abridged tag word.  The
+// instruction and data pointers, the opcode and the SSE state are left to
+// the emulator.  Unlike FSAVE, FXSAVE does not initialize the FPU.
+func (f *FPU) FXSAVE(img *FXSAVEImage) {
+	img.FCW, img.FSW, img.FTW = f.CW, f.SW, AbridgedTag(f.TW)
+	for i := range img.ST {
+		img.ST[i] = f.ST(i)
+	}
+}
+
+// FXRSTOR loads the x87 state from `img', computing the tags of the
+// registers that the abridged tag word marks as not empty.
+func (f *FPU) FXRSTOR(img *FXSAVEImage) {
+	f.CW, f.SW = img.FCW, img.FSW
+	f.restore(img.FTW, img.ST)
+}
+
+// Loads the registers in stack order `st' and the tag word for the abridged
+// tag word `ftw', with the top of stack pointer already in the status word.
+func (f *FPU) restore(ftw uint8, st [8]float.X80) {
+	for i, a := range st {
+		f.R[(f.Top()+i)&7] = a
+	}
+	f.TW = FullTag(ftw, f.SW, st)
+}
@@ -0,0 +1,108 @@
+package x87
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+var piBytes = []byte{0x35, 0xC2, 0x68, 0x21, 0xA2, 0xDA, 0x0F, 0xC9, 0x00, 0x40}
+
+func TestTagWord(t *testing.T) {
+	st := [8]float.X80{float.X80Pi, float.X80Zero, float.X80InfNeg, one}
+	// TOP = 6: ST(0) to ST(3) are R6, R7, R0 and R1.
+	if got := FullTag(0xC3, 6<<11, st); got != 0x4FF2 {
+		t.Errorf("FullTag() = %#04x, want 0x4FF2", got)
+	}
+	if got := AbridgedTag(0x4FF2); got != 0xC3 {
+		t.Errorf("AbridgedTag() = %#02x, want 0xC3", got)
+	}
+	if got := AbridgedTag(0xFFFF); got != 0 {
+		t.Errorf("AbridgedTag(0xFFFF) = %#02x", got)
+	}
+}
+
+func TestFSAVEImage(t *testing.T) {
+	img := FSAVEImage{FCW: 0x027F, FSW: 0x3800, FTW: 0x3FFF, FIP: 0x08048000, FCS: 0x23, FOP: 0x1E9,
+		FDP: 0xBFFF0000, FDS: 0x2B, ST: [8]float.X80{float.X80Pi}}
+	b, err := img.MarshalBinary()
+	if err != nil || len(b) != FSAVESize {
+		t.Fatalf("MarshalBinary() = %d bytes, %v", len(b), err)
+	}
+	if !bytes.Equal(b[28:38], piBytes) || binary.LittleEndian.Uint32(b[16:]) != 0x01E90023 {
+		t.Errorf("MarshalBinary() = % X", b)
+	}
+	var got FSAVEImage
+	if err := got.UnmarshalBinary(b); err != nil || got != img {
+		t.Errorf("UnmarshalBinary() = %+v, %v", got, err)
+	}
+	if err := got.UnmarshalBinary(b[:94]); err != ErrImageSize {
+		t.Errorf("UnmarshalBinary() of 94 bytes = %v", err)
+	}
+}
+
+func TestFXSAVEImage(t *testing.T) {
+	img := FXSAVEImage{FCW: 0x037F, FSW: 0x3800, FTW: 0x80, FOP: 0x7FF, FIP: 0x401000, FDP: 0x7FFC0000,
+		MXCSR: 0x1F80, MXCSRMask: 0xFFFF, ST: [8]float.X80{float.X80Pi}}
+	img.XMM[15][0] = 0xAA
+	b, err := img.MarshalBinary()
+	if err != nil || len(b) != FXSAVESize {
+		t.Fatalf("MarshalBinary() = %d bytes, %v", len(b), err)
+	}
+	if !bytes.Equal(b[32:42], piBytes) || b[4] != 0x80 || b[400] != 0xAA {
+		t.Errorf("MarshalBinary() = % X", b[:48])
+	}
+	b[42] = 0xFF // padding
+	var got FXSAVEImage
+	if err := got.UnmarshalBinary(b); err != nil || got != img {
+		t.Errorf("UnmarshalBinary() = %+v, %v", got, err)
+	}
+	x := img.MarshalXSAVE()
+	if len(x) != XSAVESize || binary.LittleEndian.Uint64(x[512:]) != XStateX87|XStateSSE {
+		t.Fatalf("MarshalXSAVE() = % X", x[512:])
+	}
+	if err := got.UnmarshalXSAVE(x); err != nil || got != img {
+		t.Errorf("UnmarshalXSAVE() = %+v, %v", got, err)
+	}
+	x[512] = XStateSSE
+	if err := got.UnmarshalXSAVE(x); err != nil || got.FCW != 0x037F || got.FTW != 0 || got.ST[0] != float.X80Zero ||
+		got.MXCSR != 0x1F80 || got.XMM[15][0] != 0xAA {
+		t.Errorf("UnmarshalXSAVE() in x87 init state = %+v, %v", got, err)
+	}
+	if err := got.UnmarshalXSAVE(x[:FXSAVESize]); err != ErrImageSize {
+		t.Errorf("UnmarshalXSAVE() of legacy region = %v", err)
+	}
+}
+
+func TestFPU_SaveRestore(t *testing.T) {
+	var f FPU
+	f.FINIT()
+	f.FLD1()
+	f.FLDPI()
+	f.FLDZ()
+	want := f
+
+	var img FSAVEImage
+	f.FSAVE(&img)
+	if img.ST[1] != float.X80Pi || img.FTW != want.TW || f.TW != 0xFFFF || f.SW != 0 {
+		t.Errorf("FSAVE() = %+v, TW = %#04x", img, f.TW)
+	}
+	img.FTW = 0x03FF // tags of R5 to R7 are recomputed
+	f.FRSTOR(&img)
+	if f != want {
+		t.Errorf("FRSTOR() = %+v, want %+v", f, want)
+	}
+
+	var fx FXSAVEImage
+	f.FXSAVE(&fx)
+	if fx.FTW != 0xE0 || fx.ST[2] != one || f != want {
+		t.Errorf("FXSAVE() = %+v", fx)
+	}
+	f.FINIT()
+	f.FXRSTOR(&fx)
+	if f != want {
+		t.Errorf("FXRSTOR() = %+v, want %+v", f, want)
+	}
+}
@@ -0,0 +1,95 @@
+package x87
+
+import "github.com/jenska/float"
+
+// The transcendental instructions always round to extended precision,
+// regardless of the precision control.  Their results are correctly rounded,
+// which is within the error bound of one unit in the last place specified
+// for the x87.  The trigonometric instructions reduce their operand with an
+// exact value of pi unless FPU.Accuracy selects the 66-bit pi of the
+// hardware.
+
+// Reports whether the finite operand `a' is outside the range of FSIN,
+// FCOS, FSINCOS and FPTAN, that is |a| >= 2^63.
+func outOfRange(a float.X80) bool {
+	exp, ok := exponent(a)
+	return ok && exp >= 63
+}
+
+// F2XM1 computes 2^ST(0) - 1.  The operand must lie between -1 and +1; the
+// result for other operands is undefined and here the value of the function.
+func (f *FPU) F2XM1() {
+	f.monadic(func(e *float.Env, a float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		return e.Exp2m1(a)
+	})
+}
+
+// FYL2X computes ST(1) * log2(ST(0)), stores it in ST(1) and pops the stack.
+// Negative ST(0) is an invalid operation; a zero ST(0) raises the zero
+// divide exception for finite nonzero ST(1), gives an infinity for infinite
+// ST(1) and is an invalid operation for zero ST(1).
+func (f *FPU) FYL2X() {
+	f.dyadic(1, 1, 0, float.X80Zero, true, func(e *float.Env, y, x float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		if tag(x) == TagZero && (tag(y) == TagZero || y.IsInf()) {
+			return e.Mul(y, float.X80InfNeg)
+		}
+		return e.Mul(y, e.Log2(x))
+	})
+}
+
+// FYL2XP1 computes ST(1) * log2(ST(0) + 1), stores it in ST(1) and pops the
+// stack.  ST(0) must lie between -(1 - sqrt(2)/2) and 1 - sqrt(2)/2, where
+// log2(ST(0) + 1) is more accurate than with FYL2X; the result for other
+// operands is undefined and here the value of the function.
+func (f *FPU) FYL2XP1() {
+	f.dyadic(1, 1, 0, float.X80Zero, true, func(e *float.Env, y, x float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		return e.Mul(y, e.Log2p1(x))
+	})
+}
+
+// FPATAN computes the arctangent of ST(1) / ST(0) in the quadrant of the
+// point (ST(0), ST(1)), stores it in ST(1) and pops the stack.  There are no
+// domain restrictions.
+func (f *FPU) FPATAN() {
+	f.dyadic(1, 1, 0, float.X80Zero, true, func(e *float.Env, y, x float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		return e.Atan2(y, x)
+	})
+}
+
+// FSIN computes the sine of ST(0).  If |ST(0)| >= 2^63, C2 is set and ST(0)
+// is left unchanged; the emulator has to reduce the operand, as with FPREM,
+// and repeat the instruction.  Infinities are invalid operations.
+func (f *FPU) FSIN() { f.trigonometric((*float.Env).Sin) }
+
+// FCOS computes the cosine of ST(0), with the range reduction of FSIN.
+func (f *FPU) FCOS() { f.trigonometric((*float.Env).Cos) }
+
+// Executes FSIN or FCOS.
+func (f *FPU) trigonometric(op func(e *float.Env, a float.X80) float.X80) {
+	a, ok := f.operand(0, float.X80Zero)
+	f.SW &^= C2
+	if ok && outOfRange(a) {
+		f.SW |= C2
+		return
+	}
+	f.monadic(func(e *float.Env, a float.X80) float.X80 {
+		e.RoundingPrecision = 80
+		return op(e, a)
+	})
+}
+
+// FPTAN computes the tangent of ST(0), stores it in ST(0) and pushes 1.0, so
+// that ST(1) / ST(0) is the tangent, with the range reduction of FSIN.  A
+// NaN result is pushed instead of 1.0.
+func (f *FPU) FPTAN() {
+	f.push2(true, (*float.Env).Tan, func(e *float.Env, a float.X80) float.X80 { return float.X80One })
+}
+
+// FSINCOS computes the sine and the cosine of ST(0), stores the sine in
+// ST(0) and pushes the cosine, with the range reduction of FSIN.  C1
+// indicates the rounding of the cosine.
+func (f *FPU) FSINCOS() { f.push2(true, (*float.Env).Sin, (*float.Env).Cos) }
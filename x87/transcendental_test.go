@@ -0,0 +1,118 @@
+package x87
+
+import (
+	"testing"
+
+	"github.com/jenska/float"
+)
+
+func TestFPU_Transcendental(t *testing.T) {
+	half := float.NewFromFloat64(0.5)
+	big := float.NewFromBits(0x3FFF+63, 0x8000000000000000)
+	negBig := float.NewFromBits(0xC000+62, 0x8000000000000000)
+	negPi := float.NewFromBits(0xC000, 0xC90FDAA22168C235)
+	full := []float.X80{one, one, one, one, one, one, one, one}
+	tests := []struct {
+		name  string
+		cw    uint16
+		stack []float.X80 // pushed in order, the last one is ST(0)
+		exec  func(f *FPU)
+		want  []float.X80 // ST(0), ST(1), ... afterwards
+		sw    uint16      // status word without TOP
+	}{
+		{"F2XM1", masked, []float.X80{half}, func(f *FPU) { f.F2XM1() }, []float.X80{half.Exp2m1()}, PE},
+		{"F2XM1 exact", masked, []float.X80{float.X80MinusOne}, func(f *FPU) { f.F2XM1() },
+			[]float.X80{float.NewFromFloat64(-0.5)}, 0},
+		{"F2XM1 single", masked &^ PrecisionMask, []float.X80{half}, func(f *FPU) { f.F2XM1() },
+			[]float.X80{half.Exp2m1()}, PE},
+		{"FYL2X", masked, []float.X80{three, float.Int32ToFloatX80(8)}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{float.Int32ToFloatX80(9)}, 0},
+		{"FYL2X zero", masked, []float.X80{one, float.X80Zero}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{float.X80InfNeg}, ZE},
+		{"FYL2X zero unmasked", masked &^ ZM, []float.X80{one, float.X80Zero}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{float.X80Zero, one}, ZE | ES | B},
+		{"FYL2X zero by infinity", masked, []float.X80{float.X80InfPos, negZero}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{float.X80InfNeg}, 0},
+		{"FYL2X zero by zero", masked, []float.X80{negZero, float.X80Zero}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{Indefinite}, IE},
+		{"FYL2X negative", masked, []float.X80{one, float.X80MinusOne}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{Indefinite}, IE},
+		{"FYL2X infinity by one", masked, []float.X80{float.X80InfNeg, one}, func(f *FPU) { f.FYL2X() },
+			[]float.X80{Indefinite}, IE},
+		{"FYL2XP1", masked, []float.X80{two, half}, func(f *FPU) { f.FYL2XP1() },
+			[]float.X80{two.Mul(half.Log2p1())}, PE | C1},
+		{"FYL2XP1 negative zero", masked, []float.X80{one, negZero}, func(f *FPU) { f.FYL2XP1() },
+			[]float.X80{negZero}, 0},
+		{"FYL2XP1 infinity by zero", masked, []float.X80{float.X80InfPos, float.X80Zero}, func(f *FPU) { f.FYL2XP1() },
+			[]float.X80{Indefinite}, IE},
+		{"FPATAN", masked, []float.X80{one, one}, func(f *FPU) { f.FPATAN() },
+			[]float.X80{float.NewFromBits(0x3FFE, 0xC90FDAA22168C235)}, PE | C1},
+		{"FPATAN negative zero", masked, []float.X80{negZero, float.X80MinusOne}, func(f *FPU) { f.FPATAN() },
+			[]float.X80{negPi}, PE | C1},
+		{"FPATAN zero", masked, []float.X80{float.X80Zero, float.X80Zero}, func(f *FPU) { f.FPATAN() },
+			[]float.X80{float.X80Zero}, 0},
+		{"FPATAN SNaN", masked, []float.X80{snan, one}, func(f *FPU) { f.FPATAN() },
+			[]float.X80{float.NewFromBits(0x7FFF, 0xE000000000000000)}, IE},
+		{"FSIN", masked, []float.X80{half}, func(f *FPU) { f.FSIN() }, []float.X80{half.Sin()}, PE | C1},
+		{"FSIN negative zero", masked, []float.X80{negZero}, func(f *FPU) { f.FSIN() }, []float.X80{negZero}, 0},
+		{"FSIN out of range", masked, []float.X80{big}, func(f *FPU) { f.FSIN() }, []float.X80{big}, C2},
+		{"FSIN infinity", masked, []float.X80{float.X80InfPos}, func(f *FPU) { f.FSIN() }, []float.X80{Indefinite}, IE},
+		{"FCOS", masked, []float.X80{half}, func(f *FPU) { f.FCOS() }, []float.X80{half.Cos()}, PE | C1},
+		{"FCOS zero", masked, []float.X80{float.X80Zero}, func(f *FPU) { f.FCOS() }, []float.X80{one}, 0},
+		{"FCOS out of range", masked, []float.X80{negBig}, func(f *FPU) { f.FCOS() }, []float.X80{negBig}, C2},
+		{"FCOS underflow", masked, nil, func(f *FPU) { f.FCOS() }, []float.X80{Indefinite}, IE | SF},
+		{"FPTAN", masked, []float.X80{half}, func(f *FPU) { f.FPTAN() }, []float.X80{one, half.Tan()}, PE},
+		{"FPTAN zero", masked, []float.X80{negZero}, func(f *FPU) { f.FPTAN() }, []float.X80{one, negZero}, 0},
+		{"FPTAN out of range", masked, []float.X80{big}, func(f *FPU) { f.FPTAN() }, []float.X80{big}, C2},
+		{"FPTAN infinity", masked, []float.X80{float.X80InfNeg}, func(f *FPU) { f.FPTAN() },
+			[]float.X80{Indefinite, Indefinite}, IE},
+		{"FPTAN NaN", masked, []float.X80{qnanA}, func(f *FPU) { f.FPTAN() }, []float.X80{qnanA, qnanA}, 0},
+		{"FPTAN overflow", masked, full, func(f *FPU) { f.FPTAN() }, []float.X80{Indefinite, Indefinite, one}, IE | SF | C1},
+		{"FPTAN overflow unmasked", masked &^ IM, full, func(f *FPU) { f.FPTAN() },
+			[]float.X80{one, one}, IE | SF | C1 | ES | B},
+		{"FPTAN underflow", masked, nil, func(f *FPU) { f.FPTAN() }, []float.X80{Indefinite, Indefinite}, IE | SF},
+		{"FSINCOS", masked, []float.X80{half}, func(f *FPU) { f.FSINCOS() }, []float.X80{half.Cos(), half.Sin()}, PE | C1},
+		{"FSINCOS out of range", masked, []float.X80{big}, func(f *FPU) { f.FSINCOS() }, []float.X80{big}, C2},
+		{"FSINCOS denormal unmasked", masked &^ DM, []float.X80{float.NewFromBits(0, 1)}, func(f *FPU) { f.FSINCOS() },
+			[]float.X80{float.NewFromBits(0, 1)}, DE | ES | B},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f FPU
+			f.FINIT()
+			for _, a := range tt.stack {
+				f.FLD(a)
+			}
+			f.FLDCW(tt.cw)
+			tt.exec(&f)
+			for i, want := range tt.want {
+				if got := f.ST(i); got != want {
+					t.Errorf("ST(%d) = %s, want %s", i, got.Internal(), want.Internal())
+				}
+			}
+			if got := f.SW &^ TopMask; got != tt.sw {
+				t.Errorf("SW = %#04x, want %#04x", got, tt.sw)
+			}
+		})
+	}
+}
+
+func TestFPU_Accuracy(t *testing.T) {
+	var f FPU
+	f.FINIT()
+	f.FLDPI()
+	f.FSIN()
+	if got := f.ST(0); got != float.X80Pi.Sin() {
+		t.Errorf("FSIN(pi) = %s", got.Internal())
+	}
+	f.Accuracy = float.AccuracyX87
+	f.FINIT()
+	f.FLDPI()
+	f.FSINCOS()
+	if sin, cos := f.ST(1), f.ST(0); sin != float.NewFromBits(0xBFBF, 0x8000000000000000) || cos != float.X80MinusOne {
+		t.Errorf("FSINCOS(pi) = %s, %s", sin.Internal(), cos.Internal())
+	}
+	if f.Accuracy != float.AccuracyX87 {
+		t.Errorf("FINIT() reset Accuracy")
+	}
+}